@@ -137,7 +137,7 @@ func benchmarkQueueProcessing() BenchmarkResults {
 			Duration:    time.Second,
 			Priority:    i % 3, // Mix priorities
 			SubmittedAt: time.Now(),
-			OnComplete: func(text string) {
+			OnComplete: func(result *transcriber.TranscriptResult) {
 				wg.Done()
 			},
 			OnError: func(err error) {
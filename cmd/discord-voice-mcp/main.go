@@ -2,6 +2,7 @@ package main
 
 import (
 	"context"
+	"errors"
 	"flag"
 	"os"
 	"os/signal"
@@ -10,9 +11,14 @@ import (
 
 	"github.com/fankserver/discord-voice-mcp/internal/audio"
 	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
 	"github.com/fankserver/discord-voice-mcp/internal/mcp"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/internal/vocabulary"
 	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/translator"
+	"github.com/fankserver/discord-voice-mcp/pkg/tts"
 	"github.com/joho/godotenv"
 	"github.com/sirupsen/logrus"
 )
@@ -22,12 +28,29 @@ var (
 	UserID          string
 	TranscriberType string
 	WhisperModel    string
+	SessionStore    string
+	ExportDir       string
+	TransportName   string
+	HTTPAddr        string
+	AuthToken       string
+	TTSEngine       string
+	TranslatorType  string
+	VocabularyPath  string
+	RecordingDir    string
 )
 
 func init() {
 	flag.StringVar(&Token, "token", "", "Discord Bot Token")
-	flag.StringVar(&TranscriberType, "transcriber", "mock", "Transcriber type: mock, whisper, or google")
+	flag.StringVar(&TranscriberType, "transcriber", "mock", "Transcriber type: mock, whisper, whisper-server, google, or openai")
 	flag.StringVar(&WhisperModel, "whisper-model", "", "Path to Whisper model file (required for whisper transcriber)")
+	flag.StringVar(&SessionStore, "session-store", "", "Path to session journal file (sessions are kept in memory only if empty)")
+	flag.StringVar(&ExportDir, "export-dir", "", "Directory session exports are written to (default: exports)")
+	flag.StringVar(&TransportName, "transport", "stdio", "MCP transport: stdio, http (streamable HTTP), or sse")
+	flag.StringVar(&HTTPAddr, "http-addr", mcp.DefaultHTTPAddr, "Listen address for the http and sse transports")
+	flag.StringVar(&TTSEngine, "tts", "none", "Text-to-speech engine for the speak tool: none, piper, espeak, or tone")
+	flag.StringVar(&TranslatorType, "translator", "none", "Text translator for set_translation: none, openai (chat completions), or mock")
+	flag.StringVar(&VocabularyPath, "vocabulary", "", "Path to the per-guild vocabulary file (kept in memory only if empty)")
+	flag.StringVar(&RecordingDir, "recording-dir", "", "Directory session audio is recorded to (recording is off if empty)")
 	flag.Parse()
 
 	// Load from environment
@@ -46,6 +69,31 @@ func init() {
 	if envWhisperModel := os.Getenv("WHISPER_MODEL_PATH"); envWhisperModel != "" {
 		WhisperModel = envWhisperModel
 	}
+	if envSessionStore := os.Getenv("SESSION_STORE_PATH"); envSessionStore != "" {
+		SessionStore = envSessionStore
+	}
+	if envExportDir := os.Getenv("EXPORT_DIR"); envExportDir != "" {
+		ExportDir = envExportDir
+	}
+	if envTransport := os.Getenv("MCP_TRANSPORT"); envTransport != "" {
+		TransportName = envTransport
+	}
+	if envHTTPAddr := os.Getenv("MCP_HTTP_ADDR"); envHTTPAddr != "" {
+		HTTPAddr = envHTTPAddr
+	}
+	AuthToken = os.Getenv("MCP_AUTH_TOKEN")
+	if envTTS := os.Getenv("TTS_ENGINE"); envTTS != "" {
+		TTSEngine = envTTS
+	}
+	if envTranslator := os.Getenv("TRANSLATOR_TYPE"); envTranslator != "" {
+		TranslatorType = envTranslator
+	}
+	if envVocabulary := os.Getenv("VOCABULARY_PATH"); envVocabulary != "" {
+		VocabularyPath = envVocabulary
+	}
+	if envRecordingDir := os.Getenv("RECORDING_DIR"); envRecordingDir != "" {
+		RecordingDir = envRecordingDir
+	}
 }
 
 func main() {
@@ -71,56 +119,51 @@ func main() {
 		logrus.Fatal("Discord token is required. Use -token flag or DISCORD_TOKEN env var")
 	}
 
+	transport, err := mcp.ParseTransport(TransportName)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid MCP transport. Use stdio, http, or sse")
+	}
+
 	// Set up signal handling with context for graceful shutdown
 	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
 	defer cancel()
 
-	// Create session manager
-	sessionManager := session.NewManager()
-	logrus.Debug("Session manager created")
-
-	// Create transcriber based on configuration
-	var trans transcriber.Transcriber
-	var err error
-	switch strings.ToLower(TranscriberType) {
-	case "whisper":
-		if WhisperModel == "" {
-			logrus.Fatal("Whisper model path is required when using whisper transcriber")
-		}
-		// Check if GPU support is enabled (default for whisper Docker images)
-		if os.Getenv("WHISPER_USE_GPU") == "true" || os.Getenv("WHISPER_USE_GPU") == "" {
-			// Try GPU transcriber first
-			trans, err = transcriber.NewGPUWhisperTranscriber(WhisperModel)
-			if err != nil {
-				logrus.WithError(err).Warn("Failed to initialize GPU Whisper transcriber, falling back to CPU")
-				trans, err = transcriber.NewWhisperTranscriber(WhisperModel)
-				if err != nil {
-					logrus.WithError(err).Fatal("Failed to initialize CPU Whisper transcriber")
-				}
-				logrus.WithField("model", WhisperModel).Info("Using CPU Whisper transcriber (fallback)")
-			} else {
-				logrus.WithField("model", WhisperModel).Info("Using GPU-accelerated Whisper transcriber")
-			}
-		} else {
-			// Explicitly disabled GPU
-			trans, err = transcriber.NewWhisperTranscriber(WhisperModel)
-			if err != nil {
-				logrus.WithError(err).Fatal("Failed to initialize Whisper transcriber")
-			}
-			logrus.WithField("model", WhisperModel).Info("Using CPU Whisper transcriber (GPU disabled)")
+	// Create session manager, backed by a journal when configured
+	var sessionManager *session.Manager
+	if SessionStore != "" {
+		store, err := session.OpenJournal(SessionStore)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to open session store")
 		}
-	case "google":
-		trans, err = transcriber.NewGoogleTranscriber()
+		sessionManager, err = session.NewManagerWithStore(store)
 		if err != nil {
-			logrus.WithError(err).Fatal("Failed to initialize Google transcriber")
+			logrus.WithError(err).Fatal("Failed to restore sessions")
 		}
-		logrus.Info("Using Google Speech-to-Text transcriber")
-	case "mock":
-		fallthrough
-	default:
-		trans = &transcriber.MockTranscriber{}
-		logrus.Info("Using mock transcriber")
+		logrus.WithField("path", SessionStore).Info("Using persistent session store")
+	} else {
+		sessionManager = session.NewManager()
+	}
+	defer func() {
+		if err := sessionManager.Close(); err != nil {
+			logrus.WithError(err).Warn("Failed to close session store")
+		}
+	}()
+	if ExportDir != "" {
+		sessionManager.SetExportDir(ExportDir)
+	}
+	logrus.Debug("Session manager created")
+
+	// Create transcriber based on configuration
+	trans, err := transcriber.New(TranscriberType, WhisperModel)
+	if errors.Is(err, transcriber.ErrUnknownTranscriber) {
+		// Unknown types have always meant the mock transcriber
+		logrus.WithField("transcriber", TranscriberType).Warn("Unknown transcriber type, falling back to mock. Use whisper, whisper-server, openai, google, or mock")
+		trans, err = &transcriber.MockTranscriber{}, nil
 	}
+	if err != nil {
+		logrus.WithError(err).WithField("transcriber", TranscriberType).Fatal("Failed to initialize transcriber")
+	}
+	logTranscriber(trans)
 	defer func() {
 		if err := trans.Close(); err != nil {
 			logrus.WithError(err).Warn("Failed to close transcriber")
@@ -132,6 +175,64 @@ func main() {
 	audioProcessor := audio.NewAsyncProcessor(trans, processorConfig)
 	logrus.Debug("Async audio processor created with non-blocking pipeline")
 
+	// Clean up transcriber output (hallucinations, noise tags, loops) before it is stored
+	filters, err := filter.NewChainFromEnv()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid transcript filter configuration")
+	}
+	audioProcessor.SetFilterChain(filters)
+	logrus.WithField("filters", filters.Names()).Debug("Transcript filters configured")
+
+	// Record session audio alongside the transcripts when a directory is configured
+	var recorder *recording.Recorder
+	if RecordingDir != "" {
+		recordingConfig, err := recording.ConfigFromEnv(RecordingDir)
+		if err != nil {
+			logrus.WithError(err).Fatal("Invalid recording configuration")
+		}
+		recorder, err = recording.NewRecorder(recordingConfig)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to set up recording")
+		}
+		// Sessions must not point at recordings the retention limits deleted
+		recorder.OnPruned(func(sessionID string) {
+			if err := sessionManager.ClearRecording(sessionID); err != nil {
+				logrus.WithError(err).WithField("session_id", sessionID).Debug("Pruned recording has no session")
+			}
+		})
+		audioProcessor.SetRecorder(recorder)
+		logrus.WithFields(logrus.Fields{
+			"dir":       RecordingDir,
+			"retention": recordingConfig.MaxAge,
+			"max_bytes": recordingConfig.MaxBytes,
+		}).Info("Session audio recording enabled")
+	}
+
+	// Configure the text translator; whisper translates into English on its own
+	var textTranslator translator.Translator
+	switch strings.ToLower(TranslatorType) {
+	case "openai":
+		textTranslator, err = translator.NewChatTranslator()
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to initialize translator")
+		}
+	case "mock":
+		textTranslator = &translator.MockTranslator{}
+	case "", "none":
+		logrus.Debug("Text translation disabled")
+	default:
+		logrus.WithField("translator", TranslatorType).Fatal("Unknown translator. Use none, openai, or mock")
+	}
+	if textTranslator != nil {
+		defer func() {
+			if err := textTranslator.Close(); err != nil {
+				logrus.WithError(err).Warn("Failed to close translator")
+			}
+		}()
+		audioProcessor.SetTranslator(textTranslator)
+		logrus.WithField("translator", TranslatorType).Info("Text translation enabled")
+	}
+
 	// Create bot
 	voiceBot, err := bot.New(Token, sessionManager, audioProcessor)
 	if err != nil {
@@ -139,11 +240,65 @@ func main() {
 	}
 	logrus.Info("Discord bot created successfully")
 
+	// Prompt the transcriber with each guild's vocabulary and channel member names
+	if VocabularyPath != "" {
+		store, err := vocabulary.Open(VocabularyPath)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to open vocabulary")
+		}
+		voiceBot.SetVocabularyStore(store)
+		logrus.WithField("path", VocabularyPath).Info("Using persistent vocabulary")
+	}
+	audioProcessor.SetVocabularyFunc(voiceBot.PromptVocabulary)
+
+	// Configure text-to-speech for the speak tool
+	var synthesizer tts.Synthesizer
+	switch strings.ToLower(TTSEngine) {
+	case "piper":
+		synthesizer, err = tts.NewPiperSynthesizer(os.Getenv("PIPER_MODEL_PATH"))
+	case "espeak", "espeak-ng":
+		synthesizer, err = tts.NewEspeakSynthesizer(os.Getenv("ESPEAK_VOICE"))
+	case "tone":
+		synthesizer = &tts.ToneSynthesizer{}
+	case "", "none":
+		logrus.Debug("Text-to-speech disabled")
+	default:
+		logrus.WithField("engine", TTSEngine).Fatal("Unknown TTS engine. Use none, piper, espeak, or tone")
+	}
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to initialize text-to-speech")
+	}
+	if synthesizer != nil {
+		defer func() {
+			if err := synthesizer.Close(); err != nil {
+				logrus.WithError(err).Warn("Failed to close synthesizer")
+			}
+		}()
+		voiceBot.SetSynthesizer(synthesizer)
+		logrus.WithField("engine", TTSEngine).Info("Text-to-speech enabled")
+	}
+
 	// Always start MCP server - this is an MCP-first application
 	mcpServer := mcp.NewServer(voiceBot, sessionManager, UserID)
+	mcpServer.AttachEventBus(audioProcessor.GetEventBus())
+	mcpServer.SetRetranscriber(audio.NewRetranscriber(audioProcessor, sessionManager, newRetranscriptionTranscriber))
+	mcpServer.SetFileTranscriber(audio.NewFileTranscriber(audioProcessor, sessionManager, newRetranscriptionTranscriber))
+	if recorder != nil {
+		mcpServer.SetRecorder(recorder)
+	}
+	mcpDone := make(chan struct{})
 	go func() {
-		if err := mcpServer.Start(ctx); err != nil {
+		defer close(mcpDone)
+		if transport == mcp.TransportStdio {
+			if err := mcpServer.Start(ctx); err != nil {
+				logrus.WithError(err).Error("MCP server error")
+			}
+			return
+		}
+		// A long-lived HTTP server is useless without its listener, so stop the bot too
+		if err := mcpServer.StartHTTP(ctx, transport, mcp.HTTPConfig{Addr: HTTPAddr, AuthToken: AuthToken}); err != nil {
 			logrus.WithError(err).Error("MCP server error")
+			cancel()
 		}
 	}()
 	logrus.Info("MCP server started")
@@ -170,5 +325,36 @@ func main() {
 	<-ctx.Done()
 
 	logrus.Info("Shutting down gracefully...")
+	if transport != mcp.TransportStdio {
+		// Let connected clients drain before the bot disconnects
+		<-mcpDone
+	}
 	// Deferred functions will handle cleanup
 }
+
+// logTranscriber reports which transcription backend is in use
+func logTranscriber(trans transcriber.Transcriber) {
+	switch trans.(type) {
+	case *transcriber.GPUWhisperTranscriber:
+		logrus.WithField("model", WhisperModel).Info("Using GPU-accelerated Whisper transcriber")
+	case *transcriber.WhisperTranscriber:
+		logrus.WithField("model", WhisperModel).Info("Using CPU Whisper transcriber")
+	case *transcriber.WhisperServerTranscriber:
+		logrus.WithField("model", WhisperModel).Info("Using persistent whisper-server transcriber")
+	case *transcriber.GoogleTranscriber:
+		logrus.Info("Using Google Speech-to-Text transcriber")
+	case *transcriber.OpenAITranscriber:
+		logrus.Info("Using OpenAI-compatible Whisper transcriber")
+	default:
+		logrus.Info("Using mock transcriber")
+	}
+}
+
+// newRetranscriptionTranscriber creates a transcriber for re-transcribing stored
+// sessions. Whisper falls back to the model configured for live transcription.
+func newRetranscriptionTranscriber(kind, model string) (transcriber.Transcriber, error) {
+	if model == "" && strings.HasPrefix(strings.ToLower(kind), "whisper") {
+		model = WhisperModel
+	}
+	return transcriber.New(kind, model)
+}
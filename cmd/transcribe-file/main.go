@@ -0,0 +1,139 @@
+// Command transcribe-file transcribes a recorded audio file with the same
+// segmentation and transcribers as live Discord audio and writes a session export.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/joho/godotenv"
+	"github.com/sirupsen/logrus"
+)
+
+func main() {
+	transcriberType := flag.String("transcriber", "", "Transcriber type: mock, whisper, whisper-server, google, or openai (default: TRANSCRIBER_TYPE or mock)")
+	model := flag.String("model", "", "Whisper model path or OpenAI model name (default: WHISPER_MODEL_PATH or OPENAI_TRANSCRIBE_MODEL)")
+	language := flag.String("language", "", "Language code to transcribe in (default: the transcriber's default)")
+	speaker := flag.String("speaker", "", "Name to attribute the transcripts to (default: Speaker)")
+	vocabulary := flag.String("vocabulary", "", "Comma-separated names and terms to prompt the transcriber with")
+	format := flag.String("format", "json", "Export format: json, markdown, srt, vtt, csv, or txt")
+	output := flag.String("output", ".", "Directory the export is written to, or - for standard output")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "Usage: %s [flags] <file.wav|file.ogg|file.opus>\n", os.Args[0])
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	if err := godotenv.Load(); err != nil {
+		logrus.WithError(err).Debug("Error loading .env file, using environment variables")
+	}
+
+	logrus.SetFormatter(&logrus.TextFormatter{FullTimestamp: true})
+	switch strings.ToLower(os.Getenv("LOG_LEVEL")) {
+	case "debug":
+		logrus.SetLevel(logrus.DebugLevel)
+	case "info":
+		logrus.SetLevel(logrus.InfoLevel)
+	case "error":
+		logrus.SetLevel(logrus.ErrorLevel)
+	default:
+		// Progress is printed to the terminal; keep the pipeline's logs out of the way
+		logrus.SetLevel(logrus.WarnLevel)
+	}
+
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+	path := flag.Arg(0)
+
+	exportFormat, err := session.ParseExportFormat(*format)
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid export format")
+	}
+
+	kind := *transcriberType
+	if kind == "" {
+		kind = os.Getenv("TRANSCRIBER_TYPE")
+	}
+	if kind == "" {
+		kind = "mock"
+	}
+	if *model == "" && strings.HasPrefix(strings.ToLower(kind), "whisper") {
+		*model = os.Getenv("WHISPER_MODEL_PATH")
+	}
+	trans, err := transcriber.New(kind, *model)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to initialize transcriber")
+	}
+	defer func() {
+		if err := trans.Close(); err != nil {
+			logrus.WithError(err).Warn("Failed to close transcriber")
+		}
+	}()
+
+	filters, err := filter.NewChainFromEnv()
+	if err != nil {
+		logrus.WithError(err).Fatal("Invalid transcript filter configuration")
+	}
+
+	var terms []string
+	for _, term := range strings.Split(*vocabulary, ",") {
+		if term = strings.TrimSpace(term); term != "" {
+			terms = append(terms, term)
+		}
+	}
+
+	ctx, cancel := signal.NotifyContext(context.Background(), syscall.SIGINT, syscall.SIGTERM, os.Interrupt)
+	defer cancel()
+
+	sessions := session.NewManager()
+	sessionID := sessions.CreateSession("", filepath.Base(path))
+
+	fmt.Fprintf(os.Stderr, "Transcribing %s with %s...\n", path, kind)
+	result, err := audio.TranscribeFile(ctx, trans, sessions, sessionID, path, audio.FileOptions{
+		Speaker:    *speaker,
+		Language:   *language,
+		Vocabulary: terms,
+		Filters:    filters,
+	})
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to transcribe file")
+	}
+	if err := sessions.EndSession(sessionID); err != nil {
+		logrus.WithError(err).Fatal("Failed to end session")
+	}
+
+	transcripts, _ := sessions.LatestSequence(sessionID)
+	fmt.Fprintf(os.Stderr, "Transcribed %s of audio in %d segments (%d failed), %d transcript entries\n",
+		result.Duration.Round(100*time.Millisecond), result.Segments, result.Failed, transcripts)
+
+	if *output == "-" {
+		data, err := sessions.Render(sessionID, exportFormat)
+		if err != nil {
+			logrus.WithError(err).Fatal("Failed to render session")
+		}
+		if _, err := os.Stdout.Write(data); err != nil {
+			logrus.WithError(err).Fatal("Failed to write export")
+		}
+		return
+	}
+
+	sessions.SetExportDir(*output)
+	exportPath, err := sessions.ExportSessionAs(sessionID, exportFormat)
+	if err != nil {
+		logrus.WithError(err).Fatal("Failed to export session")
+	}
+	fmt.Fprintf(os.Stderr, "Exported to %s\n", exportPath)
+}
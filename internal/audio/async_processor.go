@@ -1,16 +1,21 @@
 package audio
 
 import (
+	"context"
 	"encoding/binary"
+	"fmt"
 	"math"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
 	"github.com/fankserver/discord-voice-mcp/internal/pipeline"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/translator"
 	"github.com/sirupsen/logrus"
 	"layeh.com/gopus"
 )
@@ -25,6 +30,7 @@ const (
 	defaultQueueSize       = 100
 	defaultEventBufferSize = 1000
 	perSpeakerQueueRatio   = 4 // Divisor for per-speaker queue size
+	translationQueueSize   = 32
 
 	// Event publishing intervals
 	bufferingEventPacketInterval = 50 // Publish buffering status every N packets
@@ -47,15 +53,31 @@ type AsyncProcessor struct {
 	// Core components
 	dispatcher  *pipeline.SpeakerAwareDispatcher
 	transcriber transcriber.Transcriber
+	translation *TranslationStage
 	eventBus    *feedback.EventBus
 
-	// User buffers - one per SSRC
-	buffers map[uint32]*SmartUserBuffer
-	mu      sync.RWMutex
+	// Looks up custom vocabulary for a session; nil means none
+	vocabulary func(sessionID string) []string
+
+	// Text filters applied to transcriptions before they are stored
+	filters *filter.Chain
+
+	// Writes received audio to disk per session; nil disables recording
+	recorder *recording.Recorder
+
+	// Audio state of each running receive loop, by session ID
+	receivers map[string]*receiveContext
+	mu        sync.RWMutex
 
 	// Audio segment channel
 	segmentChan chan *AudioSegment
 
+	// Segments waiting to be written to their session's recording
+	retainChan chan *AudioSegment
+
+	// Stored transcripts waiting to be translated
+	translateChan chan translationJob
+
 	// Configuration
 	config ProcessorConfig
 
@@ -96,6 +118,7 @@ type ProcessorMetrics struct {
 	SegmentsCreated  int64
 	ActiveBuffers    int
 	TotalTranscripts int64
+	Filters          map[string]filter.Metrics // Per-filter counters, if filtering is enabled
 }
 
 // processorMetricsInternal tracks processor performance with thread safety
@@ -112,12 +135,16 @@ type processorMetricsInternal struct {
 func NewAsyncProcessor(trans transcriber.Transcriber, config ProcessorConfig) *AsyncProcessor {
 	p := &AsyncProcessor{
 		transcriber: trans,
-		buffers:     make(map[uint32]*SmartUserBuffer),
+		translation: NewTranslationStage(trans),
+		receivers:   make(map[string]*receiveContext),
 		segmentChan: make(chan *AudioSegment, config.QueueSize),
+		retainChan:  make(chan *AudioSegment, config.QueueSize),
 		config:      config,
 		metrics:     &processorMetricsInternal{},
 		stopCh:      make(chan struct{}),
 		eventBus:    feedback.NewEventBus(config.EventBufferSize),
+
+		translateChan: make(chan translationJob, translationQueueSize),
 	}
 
 	// Create speaker-aware dispatcher for optimal multi-speaker Discord processing
@@ -130,6 +157,14 @@ func NewAsyncProcessor(trans transcriber.Transcriber, config ProcessorConfig) *A
 	p.wg.Add(1)
 	go p.routeSegments()
 
+	// Start recording writer
+	p.wg.Add(1)
+	go p.retainSegments()
+
+	// Start translation worker
+	p.wg.Add(1)
+	go p.translateTranscripts()
+
 	logrus.WithFields(logrus.Fields{
 		"workers":        config.WorkerCount,
 		"max_speakers":   dispatcherConfig.MaxActiveSpeakers,
@@ -139,111 +174,363 @@ func NewAsyncProcessor(trans transcriber.Transcriber, config ProcessorConfig) *A
 	return p
 }
 
-// ProcessVoiceReceive handles incoming voice packets asynchronously
-func (p *AsyncProcessor) ProcessVoiceReceive(vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver) {
-	// Create opus decoder
-	decoder, err := gopus.NewDecoder(p.config.SampleRate, p.config.Channels)
+// SetTranslator configures the text translator for sessions that translate into
+// languages the transcriber can't produce itself
+func (p *AsyncProcessor) SetTranslator(t translator.Translator) {
+	p.translation.SetTranslator(t)
+}
+
+// SetVocabularyFunc sets how custom vocabulary is looked up for a session.
+// Terms are folded into the transcriber prompt for every segment.
+func (p *AsyncProcessor) SetVocabularyFunc(vocabulary func(sessionID string) []string) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.vocabulary = vocabulary
+}
+
+// SetFilterChain sets the text filters applied to transcriptions before they
+// are added to the session, such as dropping whisper hallucinations. Speech
+// translations go through the same filters.
+func (p *AsyncProcessor) SetFilterChain(filters *filter.Chain) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.filters = filters
+	p.translation.SetFilterChain(filters)
+}
+
+// SetRecorder enables recording the audio of every session alongside its transcript
+func (p *AsyncProcessor) SetRecorder(recorder *recording.Recorder) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.recorder = recorder
+}
+
+// retainSegments writes routed segments to their session's recording until routing stops.
+// Encoding and disk writes happen here so they never hold up dispatch.
+func (p *AsyncProcessor) retainSegments() {
+	defer p.wg.Done()
+
+	for segment := range p.retainChan {
+		p.retainSegment(segment)
+	}
+}
+
+// queueRetain hands a segment to the recording writer, dropping it if the writer is backed up
+func (p *AsyncProcessor) queueRetain(segment *AudioSegment) {
+	p.mu.RLock()
+	recording := p.recorder != nil
+	p.mu.RUnlock()
+	if !recording || segment.SessionID == "" {
+		return
+	}
+
+	select {
+	case p.retainChan <- segment:
+	default:
+		logrus.WithFields(logrus.Fields{
+			"segment_id": segment.ID,
+			"user":       segment.Username,
+		}).Warn("Recording queue full, segment audio not retained")
+	}
+}
+
+// retainSegment stores a segment's audio with the session's recording, if recording is enabled
+func (p *AsyncProcessor) retainSegment(segment *AudioSegment) {
+	p.mu.RLock()
+	recorder := p.recorder
+	p.mu.RUnlock()
+	if recorder == nil || segment.SessionID == "" {
+		return
+	}
+
+	err := recorder.SaveSegment(segment.SessionID, recording.Segment{
+		ID:         segment.ID,
+		UserID:     segment.UserID,
+		Username:   segment.Username,
+		Language:   segment.Language,
+		AudioStart: segment.AudioStart,
+		AudioEnd:   segment.AudioEnd,
+	}, segment.Audio)
 	if err != nil {
-		logrus.WithError(err).Error("Error creating opus decoder")
+		logrus.WithError(err).WithField("segment_id", segment.ID).Warn("Failed to retain segment audio")
+	}
+}
+
+// translationJob is a stored transcript waiting to be translated
+type translationJob struct {
+	sessions  *session.Manager
+	sessionID string
+	sequence  int64
+	audio     []byte
+	language  string // Language the audio was transcribed with
+	result    *transcriber.TranscriptResult
+	target    string
+}
+
+// translateTranscripts translates stored transcripts until the processor stops.
+// Translation runs here so a slow backend never holds up transcription.
+func (p *AsyncProcessor) translateTranscripts() {
+	defer p.wg.Done()
+
+	// Stopping abandons the translation in progress
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	go func() {
+		<-p.stopCh
+		cancel()
+	}()
+
+	for {
+		select {
+		case job := <-p.translateChan:
+			p.translateTranscript(ctx, job)
+		case <-p.stopCh:
+			return
+		}
+	}
+}
+
+// queueTranslation hands a stored transcript to the translation worker if its
+// session translates, dropping it if the worker is backed up
+func (p *AsyncProcessor) queueTranslation(job translationJob) {
+	job.target = job.sessions.TranslationTarget(job.sessionID)
+	if job.target == "" {
 		return
 	}
 
-	logrus.Info("Started async voice processing")
+	select {
+	case p.translateChan <- job:
+	default:
+		logrus.WithFields(logrus.Fields{
+			"session_id": job.sessionID,
+			"sequence":   job.sequence,
+		}).Warn("Translation queue full, transcript not translated")
+	}
+}
+
+// translateTranscript translates one transcript and attaches the translation to it
+func (p *AsyncProcessor) translateTranscript(ctx context.Context, job translationJob) {
+	logger := logrus.WithFields(logrus.Fields{
+		"session_id": job.sessionID,
+		"sequence":   job.sequence,
+		"target":     job.target,
+	})
 
-	// Publish session created event
+	translation, err := p.translation.Translate(ctx, job.audio, job.language, job.result, job.target)
+	if err != nil {
+		logger.WithError(err).Warn("Failed to translate transcript")
+		return
+	}
+	if translation == "" {
+		return
+	}
+
+	if err := job.sessions.SetTranslation(job.sessionID, job.sequence, translation, job.target); err != nil {
+		logger.WithError(err).Warn("Failed to store translation")
+		return
+	}
 	p.eventBus.Publish(feedback.Event{
-		Type:      feedback.EventSessionCreated,
-		SessionID: activeSessionID,
+		Type:      feedback.EventTranscriptTranslated,
+		SessionID: job.sessionID,
+		Data: feedback.TranscriptTranslatedData{
+			Sequence:    job.sequence,
+			Translation: translation,
+			Language:    job.target,
+		},
 	})
+}
+
+// startRecording starts recording a session if a recorder is configured, or returns nil
+func (p *AsyncProcessor) startRecording(sessionID string, sessionManager *session.Manager) *recording.Recording {
+	p.mu.RLock()
+	recorder := p.recorder
+	p.mu.RUnlock()
+	if recorder == nil {
+		return nil
+	}
+
+	rec, err := recorder.Start(sessionID, func(info session.Recording) {
+		if err := sessionManager.SetRecording(sessionID, info); err != nil {
+			logrus.WithError(err).WithField("session_id", sessionID).Warn("Failed to reference recording from session")
+		}
+	})
+	if err != nil {
+		logrus.WithError(err).WithField("session_id", sessionID).Error("Failed to start recording")
+		return nil
+	}
+	return rec
+}
+
+// openReceive creates the receive context of a session and registers it
+func (p *AsyncProcessor) openReceive(sessionID string, sessionManager *session.Manager, userResolver UserResolver) (*receiveContext, error) {
+	decoder, err := gopus.NewDecoder(p.config.SampleRate, p.config.Channels)
+	if err != nil {
+		return nil, fmt.Errorf("error creating opus decoder: %w", err)
+	}
+
+	rc := &receiveContext{
+		sessionID: sessionID,
+		sessions:  sessionManager,
+		resolver:  userResolver,
+		decoder:   decoder,
+		eventBus:  p.eventBus,
+		buffers:   make(map[uint32]*SmartUserBuffer),
+	}
+
+	p.mu.Lock()
+	if _, exists := p.receivers[sessionID]; exists {
+		p.mu.Unlock()
+		return nil, fmt.Errorf("session %s is already receiving audio", sessionID)
+	}
+	p.receivers[sessionID] = rc
+	p.mu.Unlock()
+
+	rc.recording = p.startRecording(sessionID, sessionManager)
+	return rc, nil
+}
+
+// closeReceive hands off the buffered speech of a receive context, finishes
+// its recording and drops it, so nothing of it outlives the receive loop
+func (p *AsyncProcessor) closeReceive(rc *receiveContext) {
+	rc.flush()
+	if rc.recording != nil {
+		if err := rc.recording.Close(); err != nil {
+			logrus.WithError(err).WithField("session_id", rc.sessionID).Warn("Failed to finish recording")
+		}
+	}
+
+	p.mu.Lock()
+	delete(p.receivers, rc.sessionID)
+	p.mu.Unlock()
+
+	p.metrics.mu.Lock()
+	p.metrics.ActiveBuffers -= rc.bufferCount()
+	p.metrics.mu.Unlock()
+
+	rc.publish(feedback.EventSessionEnded, nil)
+}
+
+// ProcessVoiceReceive handles incoming voice packets asynchronously. Each call
+// gets its own buffers and decoder for activeSessionID, released when it returns.
+func (p *AsyncProcessor) ProcessVoiceReceive(ctx context.Context, vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver) {
+	rc, err := p.openReceive(activeSessionID, sessionManager, userResolver)
+	if err != nil {
+		logrus.WithError(err).WithField("session_id", activeSessionID).Error("Error starting voice processing")
+		return
+	}
+
+	logrus.WithField("session_id", activeSessionID).Info("Started async voice processing")
+
+	// Publish session created event
+	rc.publish(feedback.EventSessionCreated, nil)
+
+	// Hand off buffered speech and close out the session however the loop ends
+	defer p.closeReceive(rc)
 
 	packetCount := 0
 
 	// Process incoming audio
-	for packet := range vc.OpusRecv {
+	for {
+		var packet *discordgo.Packet
+		select {
+		case <-ctx.Done():
+			logrus.WithField("session_id", activeSessionID).Info("Voice receive stopped")
+			return
+		case pkt, ok := <-vc.OpusRecv:
+			if !ok {
+				logrus.WithField("session_id", activeSessionID).Info("Voice receive channel closed")
+				return
+			}
+			packet = pkt
+		}
+
 		packetCount++
 		p.metrics.mu.Lock()
 		p.metrics.PacketsReceived++
 		p.metrics.mu.Unlock()
 
-		// Register packet with resolver for intelligent mapping
-		userResolver.RegisterAudioPacket(packet.SSRC, len(packet.Opus))
+		p.processPacket(rc, packet, packetCount)
+	}
+}
 
-		// Get user info
-		userID, username, nickname := userResolver.GetUserBySSRC(packet.SSRC)
+// processPacket buffers one voice packet of a receive context
+func (p *AsyncProcessor) processPacket(rc *receiveContext, packet *discordgo.Packet, packetCount int) {
+	// Register packet with resolver for intelligent mapping
+	rc.resolver.RegisterAudioPacket(packet.SSRC, len(packet.Opus))
 
-		// Get or create buffer for this user
-		buffer := p.getOrCreateBuffer(packet.SSRC, userID, username, nickname, activeSessionID, sessionManager, userResolver)
+	// Get user info
+	userID, username, nickname := rc.resolver.GetUserBySSRC(packet.SSRC)
 
-		// Check if this is a comfort noise packet
-		isSilence := len(packet.Opus) <= comfortNoisePacketMaxSize
+	// Get or create buffer for this user
+	buffer := p.getOrCreateBuffer(rc, packet.SSRC, userID, username, nickname)
 
-		if isSilence {
-			// Process as silence
-			buffer.ProcessAudio(nil, false)
-			continue
+	if rc.recording != nil {
+		displayName := nickname
+		if displayName == "" {
+			displayName = username
 		}
-
-		// Decode opus to PCM
-		pcm, err := decoder.Decode(packet.Opus, frameSize, false)
-		if err != nil {
-			logrus.WithError(err).Debug("Error decoding opus")
-			continue
+		if err := rc.recording.WritePacket(packet.SSRC, userID, displayName, packet.Opus, time.Now()); err != nil {
+			logrus.WithError(err).WithField("ssrc", packet.SSRC).Debug("Error recording opus packet")
 		}
+	}
 
-		// Convert PCM to bytes
-		pcmBytes := make([]byte, len(pcm)*bytesPerSample)
-		for i := 0; i < len(pcm); i++ {
-			// #nosec G115 -- int16 to uint16 conversion is safe for audio samples
-			binary.LittleEndian.PutUint16(pcmBytes[i*2:], uint16(pcm[i]))
-		}
+	// Check if this is a comfort noise packet
+	isSilence := len(packet.Opus) <= comfortNoisePacketMaxSize
 
-		// Process audio through smart buffer
-		// The buffer will handle VAD and segmentation internally
-		buffer.ProcessAudio(pcmBytes, true)
-
-		// Publish buffering event periodically
-		if packetCount%bufferingEventPacketInterval == 0 {
-			status := buffer.GetStatus()
-			p.eventBus.PublishAudioBuffering(activeSessionID, feedback.AudioBufferingData{
-				UserID:         status.UserID,
-				Username:       status.Username,
-				BufferDuration: status.BufferDuration,
-				BufferSize:     int(status.BufferDuration.Seconds() * float64(p.config.SampleRate*p.config.Channels*bytesPerSample)),
-				IsSpeaking:     status.BufferDuration > 0,
-			})
-		}
+	if isSilence {
+		// Process as silence
+		buffer.ProcessAudio(nil, false)
+		return
+	}
 
-		// Update metrics
-		p.metrics.mu.Lock()
-		p.metrics.BytesProcessed += int64(len(pcmBytes))
-		p.metrics.mu.Unlock()
+	// Decode opus to PCM
+	pcm, err := rc.decoder.Decode(packet.Opus, frameSize, false)
+	if err != nil {
+		logrus.WithError(err).Debug("Error decoding opus")
+		return
 	}
 
-	logrus.Info("Voice receive channel closed")
+	// Convert PCM to bytes
+	pcmBytes := make([]byte, len(pcm)*bytesPerSample)
+	for i := 0; i < len(pcm); i++ {
+		// #nosec G115 -- int16 to uint16 conversion is safe for audio samples
+		binary.LittleEndian.PutUint16(pcmBytes[i*2:], uint16(pcm[i]))
+	}
 
-	// Publish session ended event
-	p.eventBus.Publish(feedback.Event{
-		Type:      feedback.EventSessionEnded,
-		SessionID: activeSessionID,
-	})
-}
+	// Process audio through smart buffer
+	// The buffer will handle VAD and segmentation internally
+	buffer.ProcessAudio(pcmBytes, true)
+
+	// Publish buffering event periodically
+	if packetCount%bufferingEventPacketInterval == 0 {
+		status := buffer.GetStatus()
+		p.eventBus.PublishAudioBuffering(rc.sessionID, feedback.AudioBufferingData{
+			UserID:         status.UserID,
+			Username:       status.Username,
+			BufferDuration: status.BufferDuration,
+			BufferSize:     int(status.BufferDuration.Seconds() * float64(p.config.SampleRate*p.config.Channels*bytesPerSample)),
+			IsSpeaking:     status.BufferDuration > 0,
+		})
+	}
 
-// getOrCreateBuffer gets or creates a buffer for a user
-func (p *AsyncProcessor) getOrCreateBuffer(ssrc uint32, userID, username, nickname string, sessionID string, sessionManager *session.Manager, userResolver UserResolver) *SmartUserBuffer {
-	p.mu.RLock()
-	buffer, exists := p.buffers[ssrc]
-	p.mu.RUnlock()
+	// Update metrics
+	p.metrics.mu.Lock()
+	p.metrics.BytesProcessed += int64(len(pcmBytes))
+	p.metrics.mu.Unlock()
+}
 
-	if exists {
+// getOrCreateBuffer gets or creates the buffer of a speaker in a receive context
+func (p *AsyncProcessor) getOrCreateBuffer(rc *receiveContext, ssrc uint32, userID, username, nickname string) *SmartUserBuffer {
+	if buffer, exists := rc.buffer(ssrc); exists {
 		return buffer
 	}
 
 	// Create new buffer
-	p.mu.Lock()
-	defer p.mu.Unlock()
+	rc.mu.Lock()
+	defer rc.mu.Unlock()
 
 	// Double-check after acquiring write lock
-	if buffer, exists = p.buffers[ssrc]; exists {
+	if buffer, exists := rc.buffers[ssrc]; exists {
 		return buffer
 	}
 
@@ -253,39 +540,56 @@ func (p *AsyncProcessor) getOrCreateBuffer(ssrc uint32, userID, username, nickna
 		displayName = username
 	}
 
+	sessionManager := rc.sessions
+
 	// Create transcription completion callback
-	onTranscriptionComplete := func(sessionID, userID, username, text string) error {
-		return sessionManager.AddTranscript(sessionID, userID, username, text)
+	onTranscriptionComplete := func(sessionID string, transcript session.Transcript) (int64, error) {
+		return sessionManager.AppendTranscript(sessionID, transcript)
 	}
 
-	buffer = NewSmartUserBufferWithCallback(userID, displayName, ssrc, p.segmentChan, p.config.BufferConfig, onTranscriptionComplete)
-	buffer.SetSessionID(sessionID)
-	buffer.SetUserResolver(userResolver) // Set the resolver for dynamic username resolution
-	p.buffers[ssrc] = buffer
+	p.mu.RLock()
+	vocabulary := p.vocabulary
+	filters := p.filters
+	p.mu.RUnlock()
+
+	buffer := NewSmartUserBufferWithCallback(userID, displayName, ssrc, p.segmentChan, p.config.BufferConfig, onTranscriptionComplete)
+	buffer.SetSessionID(rc.sessionID)
+	buffer.SetUserResolver(rc.resolver) // Set the resolver for dynamic username resolution
+	buffer.SetLanguageResolver(sessionManager.Language)
+	buffer.SetVocabularyResolver(vocabulary)
+	buffer.SetFilterChain(filters)
+	buffer.SetTranslateFunc(func(sessionID string, sequence int64, audio []byte, language string, result *transcriber.TranscriptResult) {
+		p.queueTranslation(translationJob{
+			sessions:  sessionManager,
+			sessionID: sessionID,
+			sequence:  sequence,
+			audio:     audio,
+			language:  language,
+			result:    result,
+		})
+	})
+	rc.buffers[ssrc] = buffer
 
 	p.metrics.mu.Lock()
-	p.metrics.ActiveBuffers = len(p.buffers)
+	p.metrics.ActiveBuffers++
 	p.metrics.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
-		"ssrc":     ssrc,
-		"user_id":  userID,
-		"username": displayName,
+		"session_id": rc.sessionID,
+		"ssrc":       ssrc,
+		"user_id":    userID,
+		"username":   displayName,
 	}).Info("Created new audio buffer for user")
 
 	// Publish speaker started event
-	p.eventBus.Publish(feedback.Event{
-		Type:      feedback.EventSpeakerStarted,
-		SessionID: sessionID,
-		Data: struct {
-			UserID   string
-			Username string
-			SSRC     uint32
-		}{
-			UserID:   userID,
-			Username: displayName,
-			SSRC:     ssrc,
-		},
+	rc.publish(feedback.EventSpeakerStarted, struct {
+		UserID   string
+		Username string
+		SSRC     uint32
+	}{
+		UserID:   userID,
+		Username: displayName,
+		SSRC:     ssrc,
 	})
 
 	return buffer
@@ -294,6 +598,7 @@ func (p *AsyncProcessor) getOrCreateBuffer(ssrc uint32, userID, username, nickna
 // routeSegments routes audio segments to the processing queue
 func (p *AsyncProcessor) routeSegments() {
 	defer p.wg.Done()
+	defer close(p.retainChan)
 
 	for {
 		select {
@@ -307,6 +612,10 @@ func (p *AsyncProcessor) routeSegments() {
 				Audio:       segment.Audio,
 				Duration:    segment.Duration,
 				Context:     segment.Context,
+				Language:    segment.Language,
+				Vocabulary:  segment.Vocabulary,
+				AudioStart:  segment.AudioStart,
+				AudioEnd:    segment.AudioEnd,
 				Priority:    int(segment.Priority),
 				Reason:      segment.Reason,
 				SubmittedAt: segment.SubmittedAt,
@@ -323,10 +632,10 @@ func (p *AsyncProcessor) routeSegments() {
 					})
 				},
 
-				OnComplete: func(text string) {
+				OnComplete: func(result *transcriber.TranscriptResult) {
 					// Call original callback
 					if segment.OnComplete != nil {
-						segment.OnComplete(text)
+						segment.OnComplete(result)
 					}
 
 					// Publish completion event
@@ -334,7 +643,7 @@ func (p *AsyncProcessor) routeSegments() {
 						SegmentID:     segment.ID,
 						UserID:        segment.UserID,
 						Username:      segment.Username,
-						Text:          text,
+						Text:          result.Text,
 						AudioDuration: segment.Duration,
 					})
 
@@ -375,6 +684,9 @@ func (p *AsyncProcessor) routeSegments() {
 				}).Error("Failed to dispatch segment to speaker queue")
 			}
 
+			// Keep the audio so the session can be transcribed again later
+			p.queueRetain(segment)
+
 			// Update metrics
 			p.metrics.mu.Lock()
 			p.metrics.SegmentsCreated++
@@ -414,7 +726,13 @@ func (p *AsyncProcessor) GetMetrics() ProcessorMetrics {
 
 	// Add current buffer count
 	p.mu.RLock()
-	metrics.ActiveBuffers = len(p.buffers)
+	metrics.ActiveBuffers = 0
+	for _, rc := range p.receivers {
+		metrics.ActiveBuffers += rc.bufferCount()
+	}
+	if p.filters != nil {
+		metrics.Filters = p.filters.Metrics()
+	}
 	p.mu.RUnlock()
 
 	return metrics
@@ -444,14 +762,16 @@ func (p *AsyncProcessor) GetQueueMetrics() pipeline.QueueMetrics {
 	}
 }
 
-// GetBufferStatuses returns status of all active buffers
+// GetBufferStatuses returns status of all active buffers, across sessions
 func (p *AsyncProcessor) GetBufferStatuses() []BufferStatus {
 	p.mu.RLock()
 	defer p.mu.RUnlock()
 
-	statuses := make([]BufferStatus, 0, len(p.buffers))
-	for _, buffer := range p.buffers {
-		statuses = append(statuses, buffer.GetStatus())
+	var statuses []BufferStatus
+	for _, rc := range p.receivers {
+		for _, buffer := range rc.bufferList() {
+			statuses = append(statuses, buffer.GetStatus())
+		}
 	}
 
 	return statuses
@@ -464,7 +784,7 @@ func (p *AsyncProcessor) Stop() {
 	// Stop accepting new segments
 	close(p.stopCh)
 
-	// Wait for segment router to finish
+	// Wait for segment router, recording writer and translation worker to finish
 	p.wg.Wait()
 
 	// Stop the queue
@@ -473,11 +793,9 @@ func (p *AsyncProcessor) Stop() {
 	// Stop event bus
 	p.eventBus.Stop()
 
-	// Clear buffers
+	// Drop the state of receive loops that are still running
 	p.mu.Lock()
-	for ssrc := range p.buffers {
-		delete(p.buffers, ssrc)
-	}
+	clear(p.receivers)
 	p.mu.Unlock()
 
 	logrus.Info("Async processor stopped")
@@ -493,9 +811,13 @@ type AudioSegment struct {
 	Audio       []byte
 	Duration    time.Duration
 	Context     string
+	Language    string    // Empty uses the transcriber's default
+	Vocabulary  []string  // Terms to prompt the transcriber with
+	AudioStart  time.Time // When the first packet of the segment was captured
+	AudioEnd    time.Time // When the last packet of the segment was captured
 	Priority    Priority
 	Reason      string
 	SubmittedAt time.Time
-	OnComplete  func(string)
+	OnComplete  func(*transcriber.TranscriptResult)
 	OnError     func(error)
 }
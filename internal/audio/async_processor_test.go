@@ -0,0 +1,89 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"testing"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// toneFrames returns Opus packets of a second of a 440Hz tone, as Discord would send them
+func toneFrames(t *testing.T) [][]byte {
+	samples := make([]int16, defaultSampleRate)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/defaultSampleRate))
+	}
+	frames, err := EncodeOpusFrames(samples, defaultSampleRate, 1)
+	require.NoError(t, err)
+	return frames
+}
+
+func TestReceiveContextsAreIsolated(t *testing.T) {
+	processor := NewAsyncProcessor(&transcriber.MockTranscriber{}, DefaultProcessorConfig())
+	defer processor.Stop()
+	sessions := session.NewManager()
+	frames := toneFrames(t)
+
+	// receive runs a receive loop that hears one speaker on SSRC 42, like
+	// every connection whose voice server hands out the same SSRC
+	receive := func(sessionID, speaker string) (stop func()) {
+		ctx, cancel := context.WithCancel(context.Background())
+		vc := &discordgo.VoiceConnection{OpusRecv: make(chan *discordgo.Packet)}
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			processor.ProcessVoiceReceive(ctx, vc, sessions, sessionID, fileSpeaker(speaker))
+		}()
+		for _, frame := range frames {
+			vc.OpusRecv <- &discordgo.Packet{SSRC: 42, Opus: frame}
+		}
+		return func() {
+			cancel()
+			select {
+			case <-done:
+			case <-time.After(2 * time.Second):
+				t.Fatal("ProcessVoiceReceive did not return after cancellation")
+			}
+		}
+	}
+
+	transcriptsOf := func(sessionID string) []session.Transcript {
+		page, err := sessions.QueryTranscripts(sessionID, session.TranscriptQuery{})
+		require.NoError(t, err)
+		return page.Transcripts
+	}
+
+	// Two connections at once
+	first := sessions.CreateSession("guild1", "channel1")
+	second := sessions.CreateSession("guild2", "channel2")
+	stopFirst := receive(first, "alice")
+	stopSecond := receive(second, "bob")
+	assert.Len(t, processor.GetBufferStatuses(), 2, "one buffer per connection")
+
+	_, err := processor.openReceive(first, sessions, fileSpeaker("mallory"))
+	assert.ErrorContains(t, err, "already receiving")
+
+	stopFirst()
+	stopSecond()
+	assert.Empty(t, processor.GetBufferStatuses(), "buffers are torn down with their loop")
+	assert.Zero(t, processor.GetMetrics().ActiveBuffers)
+
+	// A later session on the same SSRC starts from scratch
+	third := sessions.CreateSession("guild1", "channel1")
+	receive(third, "carol")()
+
+	for sessionID, speaker := range map[string]string{first: "alice", second: "bob", third: "carol"} {
+		assert.Eventually(t, func() bool {
+			return len(transcriptsOf(sessionID)) > 0
+		}, 5*time.Second, 10*time.Millisecond, "session of %s", speaker)
+		for _, transcript := range transcriptsOf(sessionID) {
+			assert.Equal(t, speaker, transcript.Username, "session of %s", speaker)
+		}
+	}
+}
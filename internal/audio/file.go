@@ -0,0 +1,343 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// fileFrameSamples feeds recorded audio in 20ms frames, the size of a Discord voice packet
+	fileFrameSamples = 960
+
+	// defaultFileSpeaker names the speaker of a file when none is given
+	defaultFileSpeaker = "Speaker"
+
+	// Speech gate: frames this far above the noise floor are speech, and this
+	// many frames after speech are still sent, like a Discord client's VAD
+	speechFloorRatio = 3.0
+	speechHangover   = 15
+	floorRiseRate    = 0.0005 // Fraction of the gap to louder frames the floor rises per frame
+)
+
+// speechGate picks the frames of a recording a Discord client would have sent
+type speechGate struct {
+	vad      *IntelligentVAD
+	floor    float64
+	hangover int
+}
+
+func newSpeechGate() *speechGate {
+	vad := NewIntelligentVAD(NewIntelligentVADConfig())
+	return &speechGate{vad: vad, floor: vad.config.MinEnergyLevel}
+}
+
+// frame classifies one frame. The noise floor follows quieter frames at once
+// and louder ones slowly, so it adapts to background noise but not to speech.
+func (g *speechGate) frame(samples []int16) (send, isSpeech bool) {
+	energy := g.vad.calculateEnergy(samples)
+	if energy < g.floor {
+		g.floor = energy
+	} else {
+		g.floor += (energy - g.floor) * floorRiseRate
+	}
+
+	if energy >= max(g.vad.config.MinEnergyLevel, g.floor*speechFloorRatio) {
+		g.hangover = speechHangover
+		return true, true
+	}
+	if g.hangover > 0 {
+		g.hangover--
+		return true, false
+	}
+	return false, false
+}
+
+// fileSpeaker resolves every SSRC to the one speaker of an audio file
+type fileSpeaker string
+
+func (s fileSpeaker) GetUserBySSRC(ssrc uint32) (userID, username, nickname string) {
+	return string(s), string(s), string(s)
+}
+
+func (s fileSpeaker) RegisterAudioPacket(ssrc uint32, packetSize int) {}
+
+// FileOptions configures the transcription of a recorded audio file
+type FileOptions struct {
+	Speaker    string             // Name the transcripts are attributed to
+	Language   string             // Empty uses the transcriber's default
+	Vocabulary []string           // Terms to prompt the transcriber with
+	Filters    *filter.Chain      // Cleans up transcriber output; nil keeps it as is
+	Events     *feedback.EventBus // Receives transcription events; optional
+}
+
+// FileResult summarizes the transcription of an audio file
+type FileResult struct {
+	Duration time.Duration // Length of the audio
+	Segments int           // Segments handed to the transcriber
+	Failed   int           // Segments the transcriber returned an error for
+}
+
+// DecodeAudioFile reads a 16-bit PCM WAV or Ogg Opus file and converts it to
+// 48kHz stereo 16-bit little-endian PCM, the format of decoded Discord audio
+func DecodeAudioFile(path string) ([]byte, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- reading the file the user asked to transcribe
+	if err != nil {
+		return nil, fmt.Errorf("error reading audio file: %w", err)
+	}
+
+	var samples []int16
+	var sampleRate, channels int
+	switch {
+	case bytes.HasPrefix(data, []byte("RIFF")):
+		samples, sampleRate, channels, err = pcm.DecodeWAV(data)
+	case bytes.HasPrefix(data, []byte("OggS")):
+		samples, channels, err = recording.DecodeOpus(bytes.NewReader(data))
+		sampleRate = recording.SampleRate
+	default:
+		return nil, errors.New("unsupported audio file, use WAV (16-bit PCM) or Ogg Opus")
+	}
+	if err != nil {
+		return nil, fmt.Errorf("error decoding audio file: %w", err)
+	}
+
+	return pcm.SamplesToBytes(toPlaybackFormat(samples, sampleRate, channels)), nil
+}
+
+// TranscribeFile decodes an audio file and transcribes it into a session, see TranscribePCM
+func TranscribeFile(ctx context.Context, trans transcriber.Transcriber, sessions *session.Manager, sessionID, path string, opts FileOptions) (FileResult, error) {
+	audio, err := DecodeAudioFile(path)
+	if err != nil {
+		return FileResult{}, err
+	}
+	return TranscribePCM(ctx, trans, sessions, sessionID, audio, opts)
+}
+
+// TranscribePCM transcribes 48kHz stereo PCM into a session the way live audio
+// is: 20ms frames go through a SmartUserBuffer and its IntelligentVAD, on a
+// clock that starts at the session's start time and advances with the audio.
+// Silent frames are not buffered, as Discord clients do not send them, but
+// still advance the VAD's silence timing that decides where segments end.
+// Segments are transcribed as soon as they are cut, before more audio is fed,
+// so segmentation does not depend on how fast the transcriber is.
+func TranscribePCM(ctx context.Context, trans transcriber.Transcriber, sessions *session.Manager, sessionID string, audio []byte, opts FileOptions) (FileResult, error) {
+	sessionData, err := sessions.GetSession(sessionID)
+	if err != nil {
+		return FileResult{}, err
+	}
+	start := sessionData.StartTime
+
+	speaker := opts.Speaker
+	if speaker == "" {
+		speaker = defaultFileSpeaker
+	}
+
+	var elapsed time.Duration
+	segments := make(chan *AudioSegment, 1)
+	buffer := NewSmartUserBufferWithCallback(speaker, speaker, 0, segments, DefaultBufferConfig(), sessions.AppendTranscript)
+	buffer.SetSessionID(sessionID)
+	buffer.SetUserResolver(fileSpeaker(speaker))
+	buffer.SetFilterChain(opts.Filters)
+	buffer.SetClock(func() time.Time { return start.Add(elapsed) })
+	if opts.Language != "" {
+		buffer.SetLanguageResolver(func(sessionID, userID string) string { return opts.Language })
+	}
+	if len(opts.Vocabulary) > 0 {
+		buffer.SetVocabularyResolver(func(sessionID string) []string { return opts.Vocabulary })
+	}
+
+	gate := newSpeechGate()
+	frameBytes := fileFrameSamples * defaultChannels * bytesPerSample
+	result := FileResult{
+		Duration: time.Duration(len(audio)/(defaultChannels*bytesPerSample)) * time.Second / defaultSampleRate,
+	}
+
+	// transcribePending transcribes the segment the buffer just cut, if any
+	transcribePending := func() {
+		select {
+		case segment := <-segments:
+			result.Segments++
+			if !transcribeFileSegment(trans, segment, opts.Events) {
+				result.Failed++
+			}
+		default:
+		}
+	}
+
+	for offset := 0; offset < len(audio); offset += frameBytes {
+		if err := ctx.Err(); err != nil {
+			return result, err
+		}
+
+		frame := audio[offset:min(offset+frameBytes, len(audio))]
+		// A packet arrives once its 20ms have been spoken
+		elapsed = time.Duration((offset+len(frame))/(defaultChannels*bytesPerSample)) * time.Second / defaultSampleRate
+
+		send, isSpeech := gate.frame(pcm.BytesToSamples(frame))
+		switch {
+		case isSpeech:
+			buffer.ProcessAudio(frame, true)
+		case buffer.GetStatus().BufferDuration == 0:
+			// Silence only matters to a buffer holding speech; it would start an empty one
+		case send:
+			buffer.ProcessAudio(frame, false)
+		default:
+			buffer.ProcessAudio(nil, false)
+		}
+		transcribePending()
+	}
+
+	if buffer.Flush() {
+		transcribePending()
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"duration":   result.Duration,
+		"segments":   result.Segments,
+		"failed":     result.Failed,
+	}).Info("Audio file transcribed")
+
+	return result, nil
+}
+
+// transcribeFileSegment transcribes one segment cut from a file and reports
+// the result to its callbacks. It returns false if transcription failed.
+func transcribeFileSegment(trans transcriber.Transcriber, segment *AudioSegment, events *feedback.EventBus) bool {
+	result, err := trans.TranscribeWithContext(segment.Audio, transcriber.TranscriptionOptions{
+		PreviousContext:  segment.Context,
+		Language:         segment.Language,
+		CustomVocabulary: segment.Vocabulary,
+		EnableTimestamps: true,
+	})
+	if err != nil {
+		segment.OnError(err)
+		return false
+	}
+
+	segment.OnComplete(result)
+	if events != nil {
+		events.PublishTranscriptionCompleted(segment.SessionID, feedback.TranscriptionCompletedData{
+			SegmentID:     segment.ID,
+			UserID:        segment.UserID,
+			Username:      segment.Username,
+			Text:          result.Text,
+			AudioDuration: segment.Duration,
+		})
+	}
+	return true
+}
+
+// FileRequest selects an audio file to transcribe and how
+type FileRequest struct {
+	Path        string
+	Transcriber string // Type name for the factory, empty for the live transcriber
+	Model       string // Model for the transcriber, empty for its default
+	Language    string
+	Speaker     string
+	Format      session.ExportFormat // Format of the export written when done
+}
+
+// FileTranscriber transcribes audio files into new sessions in the background,
+// with the filters and transcriber options of the live pipeline
+type FileTranscriber struct {
+	processor *AsyncProcessor
+	sessions  *session.Manager
+	factory   TranscriberFactory
+}
+
+// NewFileTranscriber creates a file transcriber sharing the live pipeline's settings
+func NewFileTranscriber(processor *AsyncProcessor, sessions *session.Manager, factory TranscriberFactory) *FileTranscriber {
+	return &FileTranscriber{
+		processor: processor,
+		sessions:  sessions,
+		factory:   factory,
+	}
+}
+
+// Start decodes an audio file, creates a session for it and transcribes it in
+// the background. The session is ended and exported once the file is done.
+func (f *FileTranscriber) Start(req FileRequest) (string, error) {
+	audio, err := DecodeAudioFile(req.Path)
+	if err != nil {
+		return "", err
+	}
+	trans, label, owned, err := chooseTranscriber(f.processor.transcriber, f.factory, req.Transcriber, req.Model)
+	if err != nil {
+		return "", err
+	}
+	if req.Format == "" {
+		req.Format = session.FormatJSON
+	}
+
+	f.processor.mu.RLock()
+	filters := f.processor.filters
+	vocabulary := f.processor.vocabulary
+	f.processor.mu.RUnlock()
+
+	sessionID := f.sessions.CreateSession("", filepath.Base(req.Path))
+	eventBus := f.processor.eventBus
+	eventBus.Publish(feedback.Event{
+		Type:      feedback.EventSessionCreated,
+		SessionID: sessionID,
+	})
+
+	opts := FileOptions{
+		Speaker:  req.Speaker,
+		Language: req.Language,
+		Filters:  filters,
+		Events:   eventBus,
+	}
+	if vocabulary != nil {
+		opts.Vocabulary = vocabulary(sessionID)
+	}
+
+	logger := logrus.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"file":        req.Path,
+		"transcriber": label,
+	})
+	logger.Info("Started transcribing audio file")
+
+	go func() {
+		if owned {
+			defer func() {
+				if err := trans.Close(); err != nil {
+					logger.WithError(err).Warn("Failed to close file transcriber")
+				}
+			}()
+		}
+
+		if _, err := TranscribePCM(context.Background(), trans, f.sessions, sessionID, audio, opts); err != nil {
+			logger.WithError(err).Error("Failed to transcribe audio file")
+		}
+		if err := f.sessions.EndSession(sessionID); err != nil {
+			logger.WithError(err).Warn("Failed to end file session")
+		}
+		eventBus.Publish(feedback.Event{
+			Type:      feedback.EventSessionEnded,
+			SessionID: sessionID,
+		})
+
+		path, err := f.sessions.ExportSessionAs(sessionID, req.Format)
+		if err != nil {
+			logger.WithError(err).Error("Failed to export file session")
+			return
+		}
+		logger.WithField("export", path).Info("Audio file session exported")
+	}()
+
+	return sessionID, nil
+}
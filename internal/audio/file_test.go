@@ -0,0 +1,79 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// speechLikeWAV writes a 16kHz mono WAV alternating tone bursts and silence
+func speechLikeWAV(t *testing.T, pattern ...time.Duration) string {
+	t.Helper()
+	const rate = 16000
+
+	var samples []int16
+	for i, d := range pattern {
+		n := int(d.Seconds() * rate)
+		for j := 0; j < n; j++ {
+			var sample int16
+			if i%2 == 0 {
+				sample = int16(6000 * math.Sin(2*math.Pi*220*float64(j)/rate))
+			}
+			samples = append(samples, sample)
+		}
+	}
+
+	path := filepath.Join(t.TempDir(), "meeting.wav")
+	require.NoError(t, os.WriteFile(path, pcm.EncodeWAV(samples, rate, 1), 0600))
+	return path
+}
+
+func TestTranscribeFile(t *testing.T) {
+	sessions := session.NewManager()
+	sessionID := sessions.CreateSession("", "meeting.wav")
+	path := speechLikeWAV(t, time.Second, 2*time.Second, 1200*time.Millisecond, time.Second)
+
+	result, err := TranscribeFile(context.Background(), &transcriber.MockTranscriber{}, sessions, sessionID, path, FileOptions{Speaker: "Alice"})
+	require.NoError(t, err)
+	assert.Equal(t, 5200*time.Millisecond, result.Duration)
+	assert.Equal(t, 2, result.Segments)
+	assert.Zero(t, result.Failed)
+
+	sessionData, err := sessions.GetSession(sessionID)
+	require.NoError(t, err)
+	require.Len(t, sessionData.Transcripts, 2)
+
+	// Timestamps follow the audio, not the wall clock
+	for i, wantStart := range []time.Duration{0, 3 * time.Second} {
+		transcript := sessionData.Transcripts[i]
+		assert.Equal(t, "Alice", transcript.Username)
+		start, end := transcript.Span()
+		assert.InDelta(t, wantStart.Seconds(), start.Sub(sessionData.StartTime).Seconds(), 0.05, "segment %d", i)
+		assert.Greater(t, end.Sub(start), 900*time.Millisecond)
+		assert.Less(t, end.Sub(start), 1600*time.Millisecond)
+	}
+}
+
+func TestDecodeAudioFile(t *testing.T) {
+	path := speechLikeWAV(t, 100*time.Millisecond)
+	audio, err := DecodeAudioFile(path)
+	require.NoError(t, err)
+	assert.Len(t, audio, 4800*defaultChannels*bytesPerSample, "16kHz mono becomes 48kHz stereo")
+
+	other := filepath.Join(t.TempDir(), "notes.txt")
+	require.NoError(t, os.WriteFile(other, []byte("not audio"), 0600))
+	_, err = DecodeAudioFile(other)
+	assert.ErrorContains(t, err, "unsupported")
+
+	_, err = TranscribeFile(context.Background(), &transcriber.MockTranscriber{}, session.NewManager(), "missing", path, FileOptions{})
+	assert.Error(t, err)
+}
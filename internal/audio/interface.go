@@ -1,14 +1,17 @@
 package audio
 
 import (
+	"context"
+
 	"github.com/bwmarrin/discordgo"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 )
 
 // VoiceProcessor is the interface for audio processors
 type VoiceProcessor interface {
-	// ProcessVoiceReceive handles incoming voice packets
-	ProcessVoiceReceive(vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver)
+	// ProcessVoiceReceive handles incoming voice packets until ctx is cancelled or
+	// the receive channel closes, then flushes any buffered audio before returning
+	ProcessVoiceReceive(ctx context.Context, vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver)
 }
 
 // Ensure both processors implement the interface
@@ -0,0 +1,74 @@
+package pcm
+
+import (
+	"encoding/binary"
+)
+
+const (
+	// Discord delivers decoded voice as 48kHz stereo
+	DiscordSampleRate = 48000
+	DiscordChannels   = 2
+
+	// SpeechSampleRate is the mono rate speech recognizers expect
+	SpeechSampleRate = 16000
+)
+
+// speechResampler is shared by all transcribers; Resampler is stateless
+var speechResampler = NewResampler(DiscordSampleRate, SpeechSampleRate)
+
+// BytesToSamples converts little-endian 16-bit PCM bytes to samples
+func BytesToSamples(data []byte) []int16 {
+	samples := make([]int16, len(data)/2)
+	for i := range samples {
+		samples[i] = int16(binary.LittleEndian.Uint16(data[i*2:]))
+	}
+	return samples
+}
+
+// SamplesToBytes converts samples to little-endian 16-bit PCM bytes
+func SamplesToBytes(samples []int16) []byte {
+	data := make([]byte, 0, len(samples)*2)
+	for _, s := range samples {
+		data = binary.LittleEndian.AppendUint16(data, uint16(s))
+	}
+	return data
+}
+
+// Downmix averages interleaved channels into mono
+func Downmix(samples []int16, channels int) []int16 {
+	if channels <= 1 {
+		return append([]int16(nil), samples...)
+	}
+
+	mono := make([]int16, len(samples)/channels)
+	for i := range mono {
+		var sum int32
+		for ch := 0; ch < channels; ch++ {
+			sum += int32(samples[i*channels+ch])
+		}
+		mono[i] = int16(sum / int32(channels))
+	}
+	return mono
+}
+
+// Upmix copies mono samples into every one of channels interleaved channels
+func Upmix(mono []int16, channels int) []int16 {
+	out := make([]int16, 0, len(mono)*channels)
+	for _, s := range mono {
+		for ch := 0; ch < channels; ch++ {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// SpeechPCM converts Discord audio (48kHz stereo little-endian bytes)
+// to 16kHz mono samples for speech recognition
+func SpeechPCM(audio []byte) []int16 {
+	return speechResampler.Resample(Downmix(BytesToSamples(audio), DiscordChannels), 1)
+}
+
+// SpeechWAV converts Discord audio to a 16kHz mono WAV file
+func SpeechWAV(audio []byte) []byte {
+	return EncodeWAV(SpeechPCM(audio), SpeechSampleRate, 1)
+}
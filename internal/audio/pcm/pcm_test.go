@@ -0,0 +1,147 @@
+package pcm
+
+import (
+	"encoding/binary"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// sine generates a mono tone with the given peak amplitude
+func sine(freq float64, rate, n int, amplitude float64) []int16 {
+	samples := make([]int16, n)
+	for i := range samples {
+		samples[i] = int16(math.Round(amplitude * math.Sin(2*math.Pi*freq*float64(i)/float64(rate))))
+	}
+	return samples
+}
+
+// rmsError compares samples against a reference, ignoring margin samples at
+// both ends where the filter runs past the signal
+func rmsError(got, want []int16, margin int) float64 {
+	var sum float64
+	n := 0
+	for i := margin; i < len(want)-margin && i < len(got); i++ {
+		d := float64(got[i]) - float64(want[i])
+		sum += d * d
+		n++
+	}
+	return math.Sqrt(sum / float64(n))
+}
+
+func rms(samples []int16, margin int) float64 {
+	return rmsError(samples, make([]int16, len(samples)), margin)
+}
+
+func TestResampleDownPreservesPassband(t *testing.T) {
+	const amplitude = 16000
+	in := sine(1000, 48000, 48000/4, amplitude)
+	out := Resample(in, 1, 48000, 16000)
+
+	require.Len(t, out, 16000/4)
+	want := sine(1000, 16000, 16000/4, amplitude)
+	// Better than 70 dB signal-to-error ratio away from the edges
+	assert.Less(t, rmsError(out, want, 200)/amplitude, math.Pow(10, -70.0/20))
+}
+
+func TestResampleDownRejectsAliases(t *testing.T) {
+	// 12kHz is above the 8kHz output Nyquist and would fold to 4kHz unfiltered
+	const amplitude = 16000
+	out := Resample(sine(12000, 48000, 48000/4, amplitude), 1, 48000, 16000)
+
+	assert.Less(t, rms(out, 200)/amplitude, math.Pow(10, -60.0/20))
+}
+
+func TestResampleUp(t *testing.T) {
+	const amplitude = 12000
+	in := sine(440, 22050, 22050/2, amplitude)
+	out := Resample(in, 1, 22050, 48000)
+
+	require.Len(t, out, 48000/2)
+	want := sine(440, 48000, 48000/2, amplitude)
+	assert.Less(t, rmsError(out, want, 200)/amplitude, math.Pow(10, -70.0/20))
+}
+
+func TestResampleStereoAndDC(t *testing.T) {
+	// Constant channels stay constant and don't bleed into each other
+	in := make([]int16, 2*4800)
+	for i := 0; i < len(in); i += 2 {
+		in[i], in[i+1] = 1000, -2000
+	}
+	out := Resample(in, 2, 48000, 16000)
+
+	require.Len(t, out, 2*1600)
+	for i := 200; i < 1400; i++ {
+		require.Equal(t, int16(1000), out[i*2])
+		require.Equal(t, int16(-2000), out[i*2+1])
+	}
+}
+
+func TestResampleSameRateCopies(t *testing.T) {
+	in := []int16{1, 2, 3}
+	out := Resample(in, 1, 16000, 16000)
+	assert.Equal(t, in, out)
+	out[0] = 9
+	assert.Equal(t, int16(1), in[0])
+}
+
+func TestSpeechWAV(t *testing.T) {
+	// 0.1s of 48kHz stereo
+	var audio []byte
+	for _, s := range sine(500, 48000, 4800, 8000) {
+		audio = binary.LittleEndian.AppendUint16(audio, uint16(s))
+		audio = binary.LittleEndian.AppendUint16(audio, uint16(s))
+	}
+
+	wav := SpeechWAV(audio)
+	require.Len(t, wav, wavHeaderSize+1600*2)
+	assert.Equal(t, "RIFF", string(wav[0:4]))
+	assert.Equal(t, uint32(36+3200), binary.LittleEndian.Uint32(wav[4:8]))
+	assert.Equal(t, "WAVEfmt ", string(wav[8:16]))
+	assert.Equal(t, uint16(1), binary.LittleEndian.Uint16(wav[22:24]), "mono")
+	assert.Equal(t, uint32(16000), binary.LittleEndian.Uint32(wav[24:28]))
+	assert.Equal(t, uint32(32000), binary.LittleEndian.Uint32(wav[28:32]))
+	assert.Equal(t, "data", string(wav[36:40]))
+	assert.Equal(t, uint32(3200), binary.LittleEndian.Uint32(wav[40:44]))
+
+	samples := BytesToSamples(wav[wavHeaderSize:])
+	assert.Less(t, rmsError(samples, sine(500, 16000, 1600, 8000), 100)/8000, 0.001)
+}
+
+func TestDownmix(t *testing.T) {
+	assert.Equal(t, []int16{200, -150}, Downmix([]int16{100, 300, -100, -200}, 2))
+	assert.Equal(t, []int16{1, 2}, Downmix([]int16{1, 2}, 1))
+	assert.Equal(t, []int16{1, 1, -2, -2}, Upmix([]int16{1, -2}, 2))
+	assert.Equal(t, []byte{1, 0, 0xff, 0xff}, SamplesToBytes([]int16{1, -1}))
+}
+
+func TestDecodeWAV(t *testing.T) {
+	in := []int16{1, -1, 300, -300, 32767, -32768}
+	samples, rate, channels, err := DecodeWAV(EncodeWAV(in, 16000, 2))
+	require.NoError(t, err)
+	assert.Equal(t, in, samples)
+	assert.Equal(t, 16000, rate)
+	assert.Equal(t, 2, channels)
+
+	// A LIST chunk before the audio is skipped
+	wav := EncodeWAV(in, 8000, 1)
+	list := append([]byte("LIST"), binary.LittleEndian.AppendUint32(nil, 3)...)
+	list = append(list, 'a', 'b', 'c', 0)
+	withList := append(append(append([]byte{}, wav[:36]...), list...), wav[36:]...)
+	samples, rate, channels, err = DecodeWAV(withList)
+	require.NoError(t, err)
+	assert.Equal(t, in, samples)
+	assert.Equal(t, 8000, rate)
+	assert.Equal(t, 1, channels)
+
+	// 8-bit audio is not supported
+	unsupported := append([]byte{}, wav...)
+	binary.LittleEndian.PutUint16(unsupported[34:], 8)
+	_, _, _, err = DecodeWAV(unsupported)
+	assert.ErrorContains(t, err, "unsupported")
+
+	_, _, _, err = DecodeWAV([]byte("OggS"))
+	assert.Error(t, err)
+}
@@ -0,0 +1,173 @@
+// Package pcm converts raw 16-bit PCM between sample rates, channel layouts
+// and WAV files without external tools such as ffmpeg.
+package pcm
+
+import (
+	"math"
+)
+
+const (
+	// zeroCrossings of the sinc kept on each side of the filter centre;
+	// more gives a steeper transition band at the cost of CPU time
+	zeroCrossings = 32
+	// rolloff places the cutoff just below the output Nyquist frequency
+	// so the transition band ends before aliasing starts
+	rolloff = 0.92
+	// kaiserBeta sets the stopband attenuation of the window (~85 dB)
+	kaiserBeta = 8.6
+	// maxCachedPhases bounds the precomputed filter table for unusual rate ratios
+	maxCachedPhases = 4096
+)
+
+// Resampler converts between two sample rates with a polyphase windowed-sinc
+// low-pass filter. It holds no per-call state, so it is safe for concurrent use.
+type Resampler struct {
+	inRate   int
+	outRate  int
+	up       int // Interpolation factor of the reduced rate ratio
+	down     int // Decimation factor of the reduced rate ratio
+	cutoff   float64
+	halfTaps int         // Filter half-length in input samples
+	phases   [][]float64 // Coefficients per output phase, nil if computed on demand
+}
+
+// NewResampler creates a resampler from inRate to outRate (both in Hz)
+func NewResampler(inRate, outRate int) *Resampler {
+	g := gcd(inRate, outRate)
+	r := &Resampler{
+		inRate:  inRate,
+		outRate: outRate,
+		up:      outRate / g,
+		down:    inRate / g,
+	}
+	if r.up == r.down {
+		return r
+	}
+
+	// When downsampling the cutoff follows the lower output rate
+	r.cutoff = rolloff * math.Min(1, float64(outRate)/float64(inRate))
+	r.halfTaps = int(math.Ceil(zeroCrossings / r.cutoff))
+
+	if r.up <= maxCachedPhases {
+		r.phases = make([][]float64, r.up)
+		for p := range r.phases {
+			r.phases[p] = r.design(p)
+		}
+	}
+	return r
+}
+
+// Resample converts interleaved samples with the given channel count.
+// The output holds floor(frames * outRate / inRate) frames.
+func (r *Resampler) Resample(samples []int16, channels int) []int16 {
+	if channels <= 0 {
+		return nil
+	}
+	if r.up == r.down {
+		return append([]int16(nil), samples...)
+	}
+
+	inFrames := len(samples) / channels
+	outFrames := int(int64(inFrames) * int64(r.up) / int64(r.down))
+	out := make([]int16, outFrames*channels)
+
+	for n := 0; n < outFrames; n++ {
+		pos := int64(n) * int64(r.down)
+		center := int(pos / int64(r.up))
+		coeffs := r.phase(int(pos % int64(r.up)))
+
+		first := center - r.halfTaps + 1
+		for ch := 0; ch < channels; ch++ {
+			var acc float64
+			for k, c := range coeffs {
+				i := first + k
+				if i < 0 || i >= inFrames {
+					continue
+				}
+				acc += c * float64(samples[i*channels+ch])
+			}
+			out[n*channels+ch] = clamp16(acc)
+		}
+	}
+	return out
+}
+
+// phase returns the filter coefficients for one output phase
+func (r *Resampler) phase(p int) []float64 {
+	if r.phases != nil {
+		return r.phases[p]
+	}
+	return r.design(p)
+}
+
+// design computes the coefficients applied to input samples
+// center-halfTaps+1 ... center+halfTaps for output phase p.
+// They are normalized to unity DC gain.
+func (r *Resampler) design(p int) []float64 {
+	frac := float64(p) / float64(r.up)
+	coeffs := make([]float64, 2*r.halfTaps)
+
+	var sum float64
+	for k := range coeffs {
+		t := float64(k-r.halfTaps+1) - frac // Distance from the output position in input samples
+		c := r.cutoff * sinc(r.cutoff*t) * kaiser(t/float64(r.halfTaps))
+		coeffs[k] = c
+		sum += c
+	}
+	for k := range coeffs {
+		coeffs[k] /= sum
+	}
+	return coeffs
+}
+
+// Resample converts interleaved samples between two rates.
+// Reuse a Resampler when converting many buffers at the same rates.
+func Resample(samples []int16, channels, inRate, outRate int) []int16 {
+	return NewResampler(inRate, outRate).Resample(samples, channels)
+}
+
+func sinc(x float64) float64 {
+	if x == 0 {
+		return 1
+	}
+	return math.Sin(math.Pi*x) / (math.Pi * x)
+}
+
+// kaiser is the Kaiser window over [-1, 1]
+func kaiser(x float64) float64 {
+	if x <= -1 || x >= 1 {
+		return 0
+	}
+	return besselI0(kaiserBeta*math.Sqrt(1-x*x)) / besselI0(kaiserBeta)
+}
+
+// besselI0 is the zeroth-order modified Bessel function of the first kind
+func besselI0(x float64) float64 {
+	sum, term := 1.0, 1.0
+	for k := 1; k < 50; k++ {
+		term *= (x / (2 * float64(k))) * (x / (2 * float64(k)))
+		sum += term
+		if term < sum*1e-12 {
+			break
+		}
+	}
+	return sum
+}
+
+func clamp16(v float64) int16 {
+	v = math.Round(v)
+	switch {
+	case v > math.MaxInt16:
+		return math.MaxInt16
+	case v < math.MinInt16:
+		return math.MinInt16
+	}
+	return int16(v)
+}
+
+func gcd(a, b int) int {
+	for b != 0 {
+		a, b = b, a%b
+	}
+	return a
+}
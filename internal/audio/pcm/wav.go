@@ -0,0 +1,86 @@
+package pcm
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+)
+
+const (
+	// wavHeaderSize is the size of a canonical RIFF/WAVE header with one fmt and one data chunk
+	wavHeaderSize = 44
+
+	wavFormatPCM        = 1
+	wavFormatExtensible = 0xFFFE
+)
+
+// EncodeWAV wraps interleaved 16-bit samples in a RIFF/WAVE header
+func EncodeWAV(samples []int16, sampleRate, channels int) []byte {
+	dataSize := len(samples) * 2
+	blockAlign := channels * 2
+
+	wav := make([]byte, 0, wavHeaderSize+dataSize)
+	wav = append(wav, "RIFF"...)
+	wav = binary.LittleEndian.AppendUint32(wav, uint32(wavHeaderSize-8+dataSize))
+	wav = append(wav, "WAVEfmt "...)
+	wav = binary.LittleEndian.AppendUint32(wav, 16) // fmt chunk size
+	wav = binary.LittleEndian.AppendUint16(wav, 1)  // PCM
+	wav = binary.LittleEndian.AppendUint16(wav, uint16(channels))
+	wav = binary.LittleEndian.AppendUint32(wav, uint32(sampleRate))
+	wav = binary.LittleEndian.AppendUint32(wav, uint32(sampleRate*blockAlign))
+	wav = binary.LittleEndian.AppendUint16(wav, uint16(blockAlign))
+	wav = binary.LittleEndian.AppendUint16(wav, 16) // Bits per sample
+	wav = append(wav, "data"...)
+	wav = binary.LittleEndian.AppendUint32(wav, uint32(dataSize))
+	for _, s := range samples {
+		wav = binary.LittleEndian.AppendUint16(wav, uint16(s))
+	}
+	return wav
+}
+
+// DecodeWAV reads the interleaved samples of a 16-bit PCM RIFF/WAVE file.
+// Chunks other than fmt and data are skipped.
+func DecodeWAV(data []byte) (samples []int16, sampleRate, channels int, err error) {
+	if len(data) < 12 || string(data[0:4]) != "RIFF" || string(data[8:12]) != "WAVE" {
+		return nil, 0, 0, errors.New("not a WAV file")
+	}
+
+	var haveFormat bool
+	for offset := 12; offset+8 <= len(data); {
+		id := string(data[offset : offset+4])
+		size := int(binary.LittleEndian.Uint32(data[offset+4:]))
+		body := data[offset+8:]
+		if size > len(body) {
+			// Recorders that were cut off leave the size of the data chunk too large
+			size = len(body)
+		}
+		body = body[:size]
+
+		switch id {
+		case "fmt ":
+			if size < 16 {
+				return nil, 0, 0, errors.New("invalid WAV format chunk")
+			}
+			format := binary.LittleEndian.Uint16(body[0:])
+			channels = int(binary.LittleEndian.Uint16(body[2:]))
+			sampleRate = int(binary.LittleEndian.Uint32(body[4:]))
+			bits := binary.LittleEndian.Uint16(body[14:])
+			if (format != wavFormatPCM && format != wavFormatExtensible) || bits != 16 {
+				return nil, 0, 0, fmt.Errorf("unsupported WAV encoding (format %d, %d-bit), only 16-bit PCM is supported", format, bits)
+			}
+			if channels == 0 || sampleRate == 0 {
+				return nil, 0, 0, errors.New("invalid WAV format chunk")
+			}
+			haveFormat = true
+		case "data":
+			if !haveFormat {
+				return nil, 0, 0, errors.New("WAV data chunk before format chunk")
+			}
+			return BytesToSamples(body), sampleRate, channels, nil
+		}
+
+		// Chunks are padded to an even size
+		offset += 8 + size + size%2
+	}
+	return nil, 0, 0, errors.New("WAV file has no data chunk")
+}
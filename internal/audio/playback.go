@@ -0,0 +1,177 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"layeh.com/gopus"
+)
+
+const (
+	// Discord voice sends 48kHz stereo Opus in 20ms frames
+	playbackSampleRate   = 48000
+	playbackChannels     = 2
+	playbackFrameSamples = 960 // Samples per channel in one 20ms frame
+	playbackMaxFrameSize = playbackFrameSamples * playbackChannels * 2
+)
+
+// EncodeOpusFrames converts 16-bit PCM at any rate and channel count into the
+// 20ms 48kHz stereo Opus frames Discord expects. The last frame is padded with silence.
+func EncodeOpusFrames(samples []int16, sampleRate, channels int) ([][]byte, error) {
+	if sampleRate <= 0 || channels <= 0 {
+		return nil, fmt.Errorf("invalid audio format: %d Hz, %d channels", sampleRate, channels)
+	}
+
+	stereo := toPlaybackFormat(samples, sampleRate, channels)
+
+	encoder, err := gopus.NewEncoder(playbackSampleRate, playbackChannels, gopus.Audio)
+	if err != nil {
+		return nil, fmt.Errorf("error creating opus encoder: %w", err)
+	}
+
+	frameLen := playbackFrameSamples * playbackChannels
+	frames := make([][]byte, 0, (len(stereo)+frameLen-1)/frameLen)
+	for start := 0; start < len(stereo); start += frameLen {
+		frame := stereo[start:min(start+frameLen, len(stereo))]
+		if len(frame) < frameLen {
+			padded := make([]int16, frameLen)
+			copy(padded, frame)
+			frame = padded
+		}
+
+		opus, err := encoder.Encode(frame, playbackFrameSamples, playbackMaxFrameSize)
+		if err != nil {
+			return nil, fmt.Errorf("error encoding opus frame: %w", err)
+		}
+		frames = append(frames, opus)
+	}
+
+	return frames, nil
+}
+
+// SendOpusFrames writes frames to a voice connection's send channel. Discord's
+// sender paces them at 20ms, so this blocks for roughly the audio duration.
+func SendOpusFrames(ctx context.Context, send chan<- []byte, frames [][]byte) error {
+	for _, frame := range frames {
+		select {
+		case send <- frame:
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+	}
+	return nil
+}
+
+// toPlaybackFormat resamples to 48kHz and mixes to stereo. Audio that isn't
+// stereo is mixed down to mono first and plays the same on both channels.
+func toPlaybackFormat(samples []int16, sampleRate, channels int) []int16 {
+	if channels == playbackChannels {
+		return pcm.Resample(samples, channels, sampleRate, playbackSampleRate)
+	}
+	mono := pcm.Resample(pcm.Downmix(samples, channels), 1, sampleRate, playbackSampleRate)
+	return pcm.Upmix(mono, playbackChannels)
+}
+
+// pcmSource encodes 48kHz stereo 16-bit little-endian PCM from a reader frame by frame
+type pcmSource struct {
+	reader  io.Reader
+	closer  func() error
+	encoder *gopus.Encoder
+	buf     []byte
+	pcm     []int16
+}
+
+// NewPCMSource streams 48kHz stereo signed 16-bit little-endian PCM as Opus frames
+func NewPCMSource(r io.Reader) (FrameSource, error) {
+	return newPCMSource(r, func() error { return nil })
+}
+
+func newPCMSource(r io.Reader, closer func() error) (*pcmSource, error) {
+	encoder, err := gopus.NewEncoder(playbackSampleRate, playbackChannels, gopus.Audio)
+	if err != nil {
+		return nil, fmt.Errorf("error creating opus encoder: %w", err)
+	}
+	return &pcmSource{
+		reader:  r,
+		closer:  closer,
+		encoder: encoder,
+		buf:     make([]byte, playbackFrameSamples*playbackChannels*2),
+		pcm:     make([]int16, playbackFrameSamples*playbackChannels),
+	}, nil
+}
+
+func (s *pcmSource) NextFrame() ([]byte, error) {
+	n, err := io.ReadFull(s.reader, s.buf)
+	if n == 0 {
+		if err == nil || errors.Is(err, io.ErrUnexpectedEOF) {
+			err = io.EOF
+		}
+		return nil, err
+	}
+	if err != nil && !errors.Is(err, io.ErrUnexpectedEOF) {
+		return nil, err
+	}
+
+	// A short final read is padded with silence
+	clear(s.buf[n:])
+	for i := range s.pcm {
+		s.pcm[i] = int16(binary.LittleEndian.Uint16(s.buf[i*2:]))
+	}
+
+	frame, err := s.encoder.Encode(s.pcm, playbackFrameSamples, playbackMaxFrameSize)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding opus frame: %w", err)
+	}
+	return frame, nil
+}
+
+func (s *pcmSource) Close() error {
+	return s.closer()
+}
+
+// OpenFFmpegSource decodes a local file or http(s) URL with ffmpeg and streams it
+// as Opus frames. Decoding happens as playback progresses, so long files start immediately.
+func OpenFFmpegSource(ctx context.Context, ffmpegPath, input string) (FrameSource, error) {
+	// #nosec G204 - ffmpegPath comes from exec.LookPath and input is passed as a single argument
+	cmd := exec.CommandContext(ctx, ffmpegPath,
+		"-hide_banner", "-loglevel", "error",
+		"-i", input,
+		"-f", "s16le",
+		"-ar", strconv.Itoa(playbackSampleRate),
+		"-ac", strconv.Itoa(playbackChannels),
+		"pipe:1",
+	)
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, fmt.Errorf("error creating ffmpeg pipe: %w", err)
+	}
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("error starting ffmpeg: %w", err)
+	}
+
+	closer := func() error {
+		_ = stdout.Close()
+		if err := cmd.Wait(); err != nil && ctx.Err() == nil {
+			return fmt.Errorf("ffmpeg failed: %w (stderr: %s)", err, strings.TrimSpace(stderr.String()))
+		}
+		return nil
+	}
+
+	source, err := newPCMSource(stdout, closer)
+	if err != nil {
+		_ = closer()
+		return nil, err
+	}
+	return source, nil
+}
@@ -0,0 +1,65 @@
+package audio
+
+import (
+	"context"
+	"math"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"layeh.com/gopus"
+)
+
+func TestEncodeOpusFrames(t *testing.T) {
+	// Half a second of 440Hz mono audio at 22050Hz
+	samples := make([]int16, 11025)
+	for i := range samples {
+		samples[i] = int16(8000 * math.Sin(2*math.Pi*440*float64(i)/22050))
+	}
+
+	frames, err := EncodeOpusFrames(samples, 22050, 1)
+	require.NoError(t, err)
+	assert.Len(t, frames, 25) // 500ms in 20ms frames
+
+	// Frames decode back to 20ms of 48kHz stereo
+	decoder, err := gopus.NewDecoder(playbackSampleRate, playbackChannels)
+	require.NoError(t, err)
+	pcm, err := decoder.Decode(frames[0], playbackFrameSamples, false)
+	require.NoError(t, err)
+	assert.Len(t, pcm, playbackFrameSamples*playbackChannels)
+
+	_, err = EncodeOpusFrames(samples, 0, 1)
+	assert.Error(t, err)
+}
+
+func TestToPlaybackFormat(t *testing.T) {
+	// 24kHz mono doubles in length and is duplicated to both channels
+	mono := make([]int16, 480)
+	for i := range mono {
+		mono[i] = 1000
+	}
+	out := toPlaybackFormat(mono, 24000, 1)
+	require.Len(t, out, 1920)
+	for i := 0; i < len(out); i += 2 {
+		assert.Equal(t, out[i], out[i+1])
+	}
+	assert.InDelta(t, 1000, out[960], 5, "level is kept away from the edges")
+
+	// 48kHz stereo passes through unchanged
+	in := []int16{1, 2, 3, 4}
+	assert.Equal(t, in, toPlaybackFormat(in, 48000, 2))
+}
+
+func TestSendOpusFramesStopsOnCancel(t *testing.T) {
+	send := make(chan []byte, 1)
+	ctx, cancel := context.WithCancel(context.Background())
+
+	// Nothing drains the channel, so the second frame blocks until cancelled
+	cancel()
+	err := SendOpusFrames(ctx, send, [][]byte{{1}, {2}})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	send = make(chan []byte, 2)
+	require.NoError(t, SendOpusFrames(context.Background(), send, [][]byte{{1}, {2}}))
+	assert.Len(t, send, 2)
+}
@@ -0,0 +1,449 @@
+package audio
+
+import (
+	"context"
+	"errors"
+	"io"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+// frameDuration is the playback length of one Opus frame
+const frameDuration = 20 * time.Millisecond
+
+// PlaybackState describes what a Player is doing
+type PlaybackState string
+
+const (
+	PlaybackIdle    PlaybackState = "idle"
+	PlaybackPlaying PlaybackState = "playing"
+	PlaybackPaused  PlaybackState = "paused"
+)
+
+// FrameSource yields Opus frames for one track. NextFrame returns io.EOF once exhausted.
+type FrameSource interface {
+	NextFrame() ([]byte, error)
+	Close() error
+}
+
+// TrackInfo describes a queued or playing track
+type TrackInfo struct {
+	ID       string
+	Title    string
+	Source   string
+	Position time.Duration // How much has been played so far
+}
+
+// PlaybackStatus is a snapshot of a Player
+type PlaybackStatus struct {
+	State   PlaybackState
+	Current *TrackInfo
+	Queue   []TrackInfo
+}
+
+// Track is one item of playback. Its source is opened only when it starts playing.
+type Track struct {
+	info   TrackInfo
+	open   func(ctx context.Context) (FrameSource, error)
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+	err    error
+	urgent bool // Queued with Interrupt; plays even while paused
+}
+
+// NewTrack creates a track that opens its frame source when it starts playing
+func NewTrack(title, source string, open func(ctx context.Context) (FrameSource, error)) *Track {
+	return &Track{
+		info:   TrackInfo{ID: uuid.New().String(), Title: title, Source: source},
+		open:   open,
+		done:   make(chan struct{}),
+		cancel: func() {},
+	}
+}
+
+// NewFramesTrack creates a track from already encoded Opus frames
+func NewFramesTrack(title string, frames [][]byte) *Track {
+	return NewTrack(title, "", func(context.Context) (FrameSource, error) {
+		return &sliceSource{frames: frames}, nil
+	})
+}
+
+// Info returns the track's identity
+func (t *Track) Info() TrackInfo {
+	return TrackInfo{ID: t.info.ID, Title: t.info.Title, Source: t.info.Source}
+}
+
+// Cancel ends the track early, or drops it if it hasn't started.
+// Only meaningful once the track has been handed to a Player.
+func (t *Track) Cancel() {
+	t.cancel()
+}
+
+// Wait blocks until the track has finished, been skipped or stopped, or ctx is done
+func (t *Track) Wait(ctx context.Context) error {
+	select {
+	case <-t.done:
+		return t.err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// Player plays queued tracks on a voice connection's send channel, one at a
+// time. It only ever writes to the send channel, so it never touches the
+// receive loop. onStart runs before audio begins after an idle period and
+// onIdle once the queue has drained, so the caller can unmute only while playing.
+type Player struct {
+	send    chan<- []byte
+	onStart func()
+	onIdle  func()
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	wake   chan struct{}
+
+	queue  []*Track
+	urgent []*Track // Played ahead of the queue, interrupting the current track
+	active []*Track // Tracks being played; the last one is audible
+	paused bool
+	mu     sync.Mutex
+}
+
+// NewPlayer starts a player writing to send
+func NewPlayer(send chan<- []byte, onStart, onIdle func()) *Player {
+	ctx, cancel := context.WithCancel(context.Background())
+	p := &Player{
+		send:    send,
+		onStart: onStart,
+		onIdle:  onIdle,
+		ctx:     ctx,
+		cancel:  cancel,
+		wake:    make(chan struct{}, 1),
+	}
+	go p.run()
+	return p
+}
+
+// ErrPlayerClosed is returned when queueing on a player whose connection has closed
+var ErrPlayerClosed = errors.New("player closed")
+
+// Enqueue adds a track to the end of the queue and returns its position (0 = playing next)
+func (p *Player) Enqueue(track *Track) (int, error) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.prepareLocked(track); err != nil {
+		return 0, err
+	}
+	p.queue = append(p.queue, track)
+	p.signal()
+	return len(p.queue) - 1, nil
+}
+
+// Interrupt plays a track right away, suspending whatever is playing until it finishes.
+// Used for speech, which should not wait behind queued music.
+func (p *Player) Interrupt(track *Track) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if err := p.prepareLocked(track); err != nil {
+		return err
+	}
+	track.urgent = true
+	p.urgent = append(p.urgent, track)
+	p.signal()
+	return nil
+}
+
+// Pause suspends playback of the current track
+func (p *Player) Pause() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = true
+}
+
+// Resume continues a paused track
+func (p *Player) Resume() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.paused = false
+	p.signal()
+}
+
+// Skip ends the audible track and moves on. Returns false if nothing was playing.
+func (p *Player) Skip() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.active) == 0 {
+		return false
+	}
+	p.active[len(p.active)-1].cancel()
+	return true
+}
+
+// Stop ends all playback and clears the queue
+func (p *Player) Stop() {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	for _, track := range p.active {
+		track.cancel()
+	}
+	p.failPendingLocked()
+	p.paused = false
+	p.signal()
+}
+
+// Close stops playback for good. It does not wait for the player to finish,
+// so it is safe to call while holding locks the onIdle hook needs.
+func (p *Player) Close() {
+	p.cancel()
+}
+
+// Status returns a snapshot of the player
+func (p *Player) Status() PlaybackStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	status := PlaybackStatus{State: PlaybackIdle}
+	if len(p.active) > 0 {
+		current := p.active[len(p.active)-1].info
+		status.Current = &current
+		status.State = PlaybackPlaying
+		if p.paused {
+			status.State = PlaybackPaused
+		}
+	}
+	for _, track := range append(append([]*Track(nil), p.urgent...), p.queue...) {
+		status.Queue = append(status.Queue, track.Info())
+	}
+	return status
+}
+
+// prepareLocked ties a track's lifetime to the player. Caller must hold p.mu.
+func (p *Player) prepareLocked(track *Track) error {
+	if p.ctx.Err() != nil {
+		return ErrPlayerClosed
+	}
+	track.ctx, track.cancel = context.WithCancel(p.ctx)
+	return nil
+}
+
+// signal wakes the playback loop without blocking
+func (p *Player) signal() {
+	select {
+	case p.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (p *Player) run() {
+	idle := true
+	for {
+		track := p.next()
+		if track == nil {
+			if !idle {
+				p.onIdle()
+			}
+			return
+		}
+
+		if idle {
+			p.onStart()
+			idle = false
+		}
+		p.play(track)
+
+		if p.drained() {
+			p.onIdle()
+			idle = true
+		}
+	}
+}
+
+// next blocks until a track is available, or returns nil once the player is closed
+func (p *Player) next() *Track {
+	for {
+		p.mu.Lock()
+		track := p.popLocked()
+		p.mu.Unlock()
+		if track != nil {
+			return track
+		}
+
+		select {
+		case <-p.wake:
+		case <-p.ctx.Done():
+			p.mu.Lock()
+			p.failPendingLocked()
+			p.mu.Unlock()
+			return nil
+		}
+	}
+}
+
+// popLocked takes the next track to play, urgent ones first. Caller must hold p.mu.
+func (p *Player) popLocked() *Track {
+	var track *Track
+	switch {
+	case len(p.urgent) > 0:
+		track, p.urgent = p.urgent[0], p.urgent[1:]
+	case len(p.queue) > 0:
+		track, p.queue = p.queue[0], p.queue[1:]
+	}
+	return track
+}
+
+// popUrgent takes the next urgent track, if any
+func (p *Player) popUrgent() *Track {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	if len(p.urgent) == 0 {
+		return nil
+	}
+	track := p.urgent[0]
+	p.urgent = p.urgent[1:]
+	return track
+}
+
+// drained reports whether nothing is left to play
+func (p *Player) drained() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.urgent) == 0 && len(p.queue) == 0
+}
+
+// failPendingLocked drops queued tracks and releases their waiters. Caller must hold p.mu.
+func (p *Player) failPendingLocked() {
+	for _, tracks := range [][]*Track{p.urgent, p.queue} {
+		for _, track := range tracks {
+			track.cancel()
+			track.err = context.Canceled
+			close(track.done)
+		}
+	}
+	p.urgent = nil
+	p.queue = nil
+}
+
+// play streams one track, handling pause and interruptions, until it ends
+func (p *Player) play(track *Track) {
+	defer close(track.done)
+
+	if err := track.ctx.Err(); err != nil {
+		track.err = err
+		return
+	}
+
+	source, err := track.open(track.ctx)
+	if err != nil {
+		logrus.WithError(err).WithField("source", track.info.Source).Warn("Failed to open audio track")
+		track.err = err
+		return
+	}
+	defer func() {
+		if err := source.Close(); err != nil {
+			logrus.WithError(err).Debug("Error closing audio track")
+		}
+	}()
+
+	p.mu.Lock()
+	p.active = append(p.active, track)
+	p.mu.Unlock()
+	defer func() {
+		p.mu.Lock()
+		p.active = p.active[:len(p.active)-1]
+		p.mu.Unlock()
+	}()
+
+	logrus.WithFields(logrus.Fields{
+		"track_id": track.info.ID,
+		"title":    track.info.Title,
+	}).Debug("Playing audio track")
+
+	for {
+		if urgent := p.popUrgent(); urgent != nil {
+			p.play(urgent)
+			continue
+		}
+		// Pause holds the queue; interruptions such as speech play through it
+		if !track.urgent && !p.waitWhilePaused(track.ctx) {
+			track.err = track.ctx.Err()
+			return
+		}
+		if p.hasUrgent() {
+			continue
+		}
+
+		frame, err := source.NextFrame()
+		if errors.Is(err, io.EOF) {
+			return
+		}
+		if err != nil {
+			logrus.WithError(err).WithField("source", track.info.Source).Warn("Audio track failed during playback")
+			track.err = err
+			return
+		}
+
+		select {
+		case p.send <- frame:
+			p.mu.Lock()
+			track.info.Position += frameDuration
+			p.mu.Unlock()
+		case <-track.ctx.Done():
+			track.err = track.ctx.Err()
+			return
+		}
+	}
+}
+
+// waitWhilePaused blocks while paused and nothing urgent is waiting.
+// Returns false if ctx ends first.
+func (p *Player) waitWhilePaused(ctx context.Context) bool {
+	for {
+		p.mu.Lock()
+		waiting := p.paused && len(p.urgent) == 0
+		p.mu.Unlock()
+		if !waiting {
+			return ctx.Err() == nil
+		}
+
+		select {
+		case <-p.wake:
+		case <-ctx.Done():
+			return false
+		}
+	}
+}
+
+// hasUrgent reports whether an interruption is waiting
+func (p *Player) hasUrgent() bool {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return len(p.urgent) > 0
+}
+
+// sliceSource replays already encoded frames
+type sliceSource struct {
+	frames [][]byte
+	next   int
+}
+
+func (s *sliceSource) NextFrame() ([]byte, error) {
+	if s.next >= len(s.frames) {
+		return nil, io.EOF
+	}
+	frame := s.frames[s.next]
+	s.next++
+	return frame, nil
+}
+
+func (s *sliceSource) Close() error {
+	return nil
+}
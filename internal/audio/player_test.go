@@ -0,0 +1,222 @@
+package audio
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// testFrames builds n one-byte frames all holding tag
+func testFrames(tag byte, n int) [][]byte {
+	frames := make([][]byte, n)
+	for i := range frames {
+		frames[i] = []byte{tag}
+	}
+	return frames
+}
+
+// receiveFrame waits for the next frame sent by a player
+func receiveFrame(t *testing.T, send <-chan []byte) byte {
+	t.Helper()
+	select {
+	case frame := <-send:
+		return frame[0]
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for frame")
+		return 0
+	}
+}
+
+func newTestPlayer(t *testing.T) (*Player, chan []byte, *atomic.Int32, *atomic.Int32) {
+	t.Helper()
+	send := make(chan []byte)
+	var starts, idles atomic.Int32
+	player := NewPlayer(send, func() { starts.Add(1) }, func() { idles.Add(1) })
+	t.Cleanup(player.Close)
+	return player, send, &starts, &idles
+}
+
+func TestPlayerPlaysQueueInOrder(t *testing.T) {
+	player, send, starts, idles := newTestPlayer(t)
+
+	first := NewFramesTrack("first", testFrames('a', 2))
+	second := NewFramesTrack("second", testFrames('b', 1))
+	_, err := player.Enqueue(first)
+	require.NoError(t, err)
+	position, err := player.Enqueue(second)
+	require.NoError(t, err)
+	assert.Equal(t, 1, position)
+
+	assert.Equal(t, byte('a'), receiveFrame(t, send))
+	assert.Equal(t, byte('a'), receiveFrame(t, send))
+	assert.Equal(t, byte('b'), receiveFrame(t, send))
+
+	require.NoError(t, second.Wait(context.Background()))
+	require.NoError(t, first.Wait(context.Background()))
+	assert.Eventually(t, func() bool { return idles.Load() == 1 }, time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(1), starts.Load(), "back-to-back tracks share one unmute")
+	assert.Equal(t, PlaybackIdle, player.Status().State)
+}
+
+func TestPlayerInterruptSuspendsCurrentTrack(t *testing.T) {
+	player, send, _, _ := newTestPlayer(t)
+
+	_, err := player.Enqueue(NewFramesTrack("music", testFrames('m', 3)))
+	require.NoError(t, err)
+	assert.Equal(t, byte('m'), receiveFrame(t, send))
+
+	speech := NewFramesTrack("speech", testFrames('s', 2))
+	require.NoError(t, player.Interrupt(speech))
+
+	// One music frame may already be waiting to be sent
+	frames := []byte{receiveFrame(t, send), receiveFrame(t, send), receiveFrame(t, send), receiveFrame(t, send)}
+	assert.Equal(t, 2, bytes.Count(frames, []byte{'s'}))
+	assert.Equal(t, byte('m'), frames[3], "music resumes after speech")
+	require.NoError(t, speech.Wait(context.Background()))
+}
+
+func TestPlayerPauseResumeAndStatus(t *testing.T) {
+	player, send, _, _ := newTestPlayer(t)
+
+	_, err := player.Enqueue(NewFramesTrack("song", testFrames('a', 10)))
+	require.NoError(t, err)
+	_, err = player.Enqueue(NewFramesTrack("next", testFrames('b', 1)))
+	require.NoError(t, err)
+	receiveFrame(t, send)
+
+	player.Pause()
+	// Drain the frame that may have been in flight when pausing
+	select {
+	case <-send:
+	case <-time.After(50 * time.Millisecond):
+	}
+	select {
+	case <-send:
+		t.Fatal("received frame while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+
+	status := player.Status()
+	assert.Equal(t, PlaybackPaused, status.State)
+	require.NotNil(t, status.Current)
+	assert.Equal(t, "song", status.Current.Title)
+	assert.GreaterOrEqual(t, status.Current.Position, frameDuration)
+	require.Len(t, status.Queue, 1)
+	assert.Equal(t, "next", status.Queue[0].Title)
+
+	player.Resume()
+	assert.Equal(t, byte('a'), receiveFrame(t, send))
+	assert.Equal(t, PlaybackPlaying, player.Status().State)
+}
+
+func TestPlayerInterruptPlaysWhilePaused(t *testing.T) {
+	player, send, _, _ := newTestPlayer(t)
+
+	_, err := player.Enqueue(NewFramesTrack("song", testFrames('a', 10)))
+	require.NoError(t, err)
+	receiveFrame(t, send)
+
+	player.Pause()
+	// Drain the frame that may have been in flight when pausing
+	select {
+	case <-send:
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	speech := NewFramesTrack("speech", testFrames('s', 2))
+	require.NoError(t, player.Interrupt(speech))
+	assert.Equal(t, byte('s'), receiveFrame(t, send))
+	assert.Equal(t, byte('s'), receiveFrame(t, send))
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	require.NoError(t, speech.Wait(ctx))
+
+	// The paused song stays paused after the speech
+	select {
+	case <-send:
+		t.Fatal("received frame while paused")
+	case <-time.After(100 * time.Millisecond):
+	}
+	assert.Equal(t, PlaybackPaused, player.Status().State)
+
+	player.Resume()
+	assert.Equal(t, byte('a'), receiveFrame(t, send))
+}
+
+func TestPlayerSkipAndStop(t *testing.T) {
+	player, send, _, _ := newTestPlayer(t)
+
+	long := NewFramesTrack("long", testFrames('a', 1000))
+	_, err := player.Enqueue(long)
+	require.NoError(t, err)
+	_, err = player.Enqueue(NewFramesTrack("short", testFrames('b', 1000)))
+	require.NoError(t, err)
+	queued := NewFramesTrack("queued", testFrames('c', 1))
+	_, err = player.Enqueue(queued)
+	require.NoError(t, err)
+
+	assert.Equal(t, byte('a'), receiveFrame(t, send))
+	assert.True(t, player.Skip())
+	assert.ErrorIs(t, long.Wait(context.Background()), context.Canceled)
+
+	// The next track takes over
+	for receiveFrame(t, send) != 'b' {
+	}
+
+	player.Stop()
+	assert.ErrorIs(t, queued.Wait(context.Background()), context.Canceled)
+	assert.Eventually(t, func() bool { return player.Status().State == PlaybackIdle }, time.Second, 10*time.Millisecond)
+	assert.False(t, player.Skip())
+}
+
+func TestPlayerClose(t *testing.T) {
+	player, send, _, idles := newTestPlayer(t)
+
+	playing := NewFramesTrack("playing", testFrames('a', 1000))
+	_, err := player.Enqueue(playing)
+	require.NoError(t, err)
+	receiveFrame(t, send)
+
+	player.Close()
+	assert.Error(t, playing.Wait(context.Background()))
+	assert.Eventually(t, func() bool { return idles.Load() == 1 }, time.Second, 10*time.Millisecond)
+
+	_, err = player.Enqueue(NewFramesTrack("late", testFrames('b', 1)))
+	assert.ErrorIs(t, err, ErrPlayerClosed)
+}
+
+func TestPlayerReportsOpenErrors(t *testing.T) {
+	player, _, _, _ := newTestPlayer(t)
+
+	openErr := errors.New("no such file")
+	track := NewTrack("broken", "missing.mp3", func(context.Context) (FrameSource, error) {
+		return nil, openErr
+	})
+	_, err := player.Enqueue(track)
+	require.NoError(t, err)
+	assert.ErrorIs(t, track.Wait(context.Background()), openErr)
+}
+
+func TestPCMSource(t *testing.T) {
+	// One and a half frames of 48kHz stereo PCM
+	pcm := make([]byte, playbackFrameSamples*playbackChannels*2*3/2)
+	source, err := NewPCMSource(bytes.NewReader(pcm))
+	require.NoError(t, err)
+
+	for i := 0; i < 2; i++ {
+		frame, err := source.NextFrame()
+		require.NoError(t, err)
+		assert.NotEmpty(t, frame)
+	}
+	_, err = source.NextFrame()
+	assert.ErrorIs(t, err, io.EOF)
+	assert.NoError(t, source.Close())
+}
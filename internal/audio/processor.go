@@ -2,6 +2,7 @@ package audio
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"fmt"
 	"os"
@@ -153,7 +154,7 @@ func NewProcessor(t transcriber.Transcriber) *Processor {
 }
 
 // ProcessVoiceReceive handles incoming voice packets
-func (p *Processor) ProcessVoiceReceive(vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver) {
+func (p *Processor) ProcessVoiceReceive(ctx context.Context, vc *discordgo.VoiceConnection, sessionManager *session.Manager, activeSessionID string, userResolver UserResolver) {
 	// Create opus decoder
 	decoder, err := gopus.NewDecoder(sampleRate, channels)
 	if err != nil {
@@ -163,9 +164,25 @@ func (p *Processor) ProcessVoiceReceive(vc *discordgo.VoiceConnection, sessionMa
 
 	logrus.Info("Started processing voice receive")
 
+	// Transcribe whatever is still buffered before the session closes
+	defer p.flushStreams(sessionManager, activeSessionID)
+
 	packetCount := 0
 	// Process incoming audio
-	for packet := range vc.OpusRecv {
+	for {
+		var packet *discordgo.Packet
+		select {
+		case <-ctx.Done():
+			logrus.Info("Voice receive stopped")
+			return
+		case pkt, ok := <-vc.OpusRecv:
+			if !ok {
+				logrus.Info("Voice receive channel closed")
+				return
+			}
+			packet = pkt
+		}
+
 		packetCount++
 		if packetCount%100 == 0 {
 			logrus.WithField("packets_received", packetCount).Debug("Voice packets received")
@@ -251,8 +268,26 @@ func (p *Processor) ProcessVoiceReceive(vc *discordgo.VoiceConnection, sessionMa
 			go p.transcribeAndClear(stream, sessionManager, activeSessionID)
 		}
 	}
+}
+
+// flushStreams synchronously transcribes the remaining audio of every stream
+func (p *Processor) flushStreams(sessionManager *session.Manager, sessionID string) {
+	p.mu.Lock()
+	streams := make([]*Stream, 0, len(p.activeStreams))
+	for _, stream := range p.activeStreams {
+		streams = append(streams, stream)
+	}
+	p.mu.Unlock()
 
-	logrus.Info("Voice receive channel closed")
+	for _, stream := range streams {
+		stream.mu.Lock()
+		bufferSize := stream.Buffer.Len()
+		stream.mu.Unlock()
+
+		if bufferSize > minAudioBuffer {
+			p.transcribeAndClear(stream, sessionManager, sessionID)
+		}
+	}
 }
 
 func (p *Processor) getOrCreateStream(ssrc uint32, userID, username, nickname string, sessionManager *session.Manager, sessionID string) *Stream {
@@ -336,6 +371,7 @@ func (p *Processor) transcribeAndClear(stream *Stream, sessionManager *session.M
 	}
 
 	audioData := stream.Buffer.Bytes()
+	audioEnd := stream.lastAudioTime
 
 	// Save audio for overlap to prevent word cutoffs
 	// Get overlap duration from environment or use default
@@ -430,8 +466,10 @@ func (p *Processor) transcribeAndClear(stream *Stream, sessionManager *session.M
 
 	// Transcribe audio with context for better accuracy
 	result, err := p.transcriber.TranscribeWithContext(audioData, transcriber.TranscriptionOptions{
-		PreviousContext: lastTranscript,
-		OverlapAudio:    stream.overlapBuffer,
+		PreviousContext:  lastTranscript,
+		OverlapAudio:     stream.overlapBuffer,
+		Language:         sessionManager.Language(sessionID, stream.UserID),
+		EnableTimestamps: true,
 	})
 	if err != nil {
 		logrus.WithError(err).Error("Error transcribing audio")
@@ -452,7 +490,17 @@ func (p *Processor) transcribeAndClear(stream *Stream, sessionManager *session.M
 		stream.mu.Unlock()
 
 		// Add to session (this will also remove the pending transcription)
-		err = sessionManager.AddTranscript(sessionID, stream.UserID, stream.Username, text)
+		err = sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+			UserID:     stream.UserID,
+			Username:   stream.Username,
+			Text:       text,
+			Duration:   audioDuration,
+			Language:   result.Language,
+			Confidence: result.Confidence,
+			AudioStart: audioEnd.Add(-time.Duration(audioDuration * float64(time.Second))),
+			AudioEnd:   audioEnd,
+			Words:      sessionWords(result.Words),
+		})
 		if err != nil {
 			logrus.WithError(err).Error("Error adding transcript")
 		} else {
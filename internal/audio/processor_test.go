@@ -2,6 +2,7 @@ package audio
 
 import (
 	"bytes"
+	"context"
 	"encoding/binary"
 	"errors"
 	"fmt"
@@ -10,6 +11,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/bwmarrin/discordgo"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
 	"github.com/stretchr/testify/assert"
@@ -716,3 +718,42 @@ func TestIsTranscribingFlagReset(t *testing.T) {
 
 	mockTranscriber.AssertExpectations(t)
 }
+
+func TestProcessVoiceReceiveFlushesOnCancel(t *testing.T) {
+	mockTranscriber := new(MockTranscriber)
+	processor := NewProcessor(mockTranscriber)
+	sessionManager := session.NewManager()
+	sessionID := sessionManager.CreateSession("test-guild", "test-channel")
+
+	// Audio still buffered when the bot leaves the channel
+	processor.activeStreams["1234"] = &Stream{
+		UserID:   "test-user",
+		Username: "TestUser",
+		Buffer:   bytes.NewBuffer(make([]byte, minAudioBuffer+1000)),
+	}
+	mockTranscriber.On("TranscribeWithContext", mock.Anything, mock.Anything).Return(&transcriber.TranscriptResult{
+		Text: "last words",
+	}, nil)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	vc := &discordgo.VoiceConnection{OpusRecv: make(chan *discordgo.Packet)}
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		processor.ProcessVoiceReceive(ctx, vc, sessionManager, sessionID, nil)
+	}()
+
+	cancel()
+	select {
+	case <-done:
+	case <-time.After(2 * time.Second):
+		t.Fatal("ProcessVoiceReceive did not return after cancellation")
+	}
+
+	sess, err := sessionManager.GetSession(sessionID)
+	assert.NoError(t, err)
+	if assert.Len(t, sess.Transcripts, 1) {
+		assert.Equal(t, "last words", sess.Transcripts[0].Text)
+	}
+}
@@ -0,0 +1,82 @@
+package audio
+
+import (
+	"sync"
+
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/sirupsen/logrus"
+	"layeh.com/gopus"
+)
+
+// receiveContext is the audio state of one voice connection's receive loop:
+// the buffers of its speakers, its Opus decoder and its recording, all bound
+// to one session. It is torn down when the loop ends, so neither concurrent
+// connections nor a later session in the same channel share a buffer.
+type receiveContext struct {
+	sessionID string
+	sessions  *session.Manager
+	resolver  UserResolver
+	decoder   *gopus.Decoder
+	recording *recording.Recording // nil if recording is disabled
+	eventBus  *feedback.EventBus
+
+	// Speaker buffers by SSRC; SSRCs are only unique within a connection
+	buffers map[uint32]*SmartUserBuffer
+	mu      sync.RWMutex
+}
+
+// buffer returns the buffer of an SSRC, if it has one
+func (rc *receiveContext) buffer(ssrc uint32) (*SmartUserBuffer, bool) {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	buffer, exists := rc.buffers[ssrc]
+	return buffer, exists
+}
+
+// bufferList returns every speaker buffer of the connection
+func (rc *receiveContext) bufferList() []*SmartUserBuffer {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+
+	buffers := make([]*SmartUserBuffer, 0, len(rc.buffers))
+	for _, buffer := range rc.buffers {
+		buffers = append(buffers, buffer)
+	}
+	return buffers
+}
+
+// bufferCount returns the number of speaker buffers
+func (rc *receiveContext) bufferCount() int {
+	rc.mu.RLock()
+	defer rc.mu.RUnlock()
+	return len(rc.buffers)
+}
+
+// publish sends an event scoped to the context's session
+func (rc *receiveContext) publish(eventType feedback.EventType, data interface{}) {
+	rc.eventBus.Publish(feedback.Event{
+		Type:      eventType,
+		SessionID: rc.sessionID,
+		Data:      data,
+	})
+}
+
+// flush submits the remaining audio of every speaker buffer
+func (rc *receiveContext) flush() {
+	buffers := rc.bufferList()
+
+	flushed := 0
+	for _, buffer := range buffers {
+		if buffer.Flush() {
+			flushed++
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": rc.sessionID,
+		"buffers":    len(buffers),
+		"flushed":    flushed,
+	}).Debug("Flushed pending audio for session")
+}
@@ -0,0 +1,316 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/internal/pipeline"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/google/uuid"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// retranscribeTimeout bounds one segment; offline models can be much slower than live ones
+	retranscribeTimeout = 5 * time.Minute
+
+	// dispatchRetryDelay is how long to wait for room in a full speaker queue
+	dispatchRetryDelay = 50 * time.Millisecond
+)
+
+// TranscriberFactory creates a transcriber by type name (whisper, openai, ...)
+// and model, for replaying stored audio
+type TranscriberFactory func(kind, model string) (transcriber.Transcriber, error)
+
+// RetranscribeRequest selects how a stored session is transcribed again
+type RetranscribeRequest struct {
+	SessionID   string
+	Transcriber string // Type name for the factory, empty for the live transcriber
+	Model       string // Model for the transcriber, empty for its default
+	Language    string // Overrides the language each segment was transcribed in
+}
+
+// RetranscribeStatus reports the progress of a re-transcription
+type RetranscribeStatus struct {
+	ID          string
+	SessionID   string
+	Transcriber string
+	Total       int
+	Completed   int // Including failed segments
+	Failed      int
+	Done        bool
+	Error       string
+}
+
+// Retranscriber replays the retained segment audio of a session through a
+// chosen transcriber and stores the result as an alternate transcript. It
+// uses its own speaker-aware dispatcher so speakers are processed in parallel
+// without competing with live transcription.
+type Retranscriber struct {
+	processor *AsyncProcessor
+	sessions  *session.Manager
+	factory   TranscriberFactory
+	jobs      map[string]*RetranscribeStatus
+	mu        sync.Mutex
+}
+
+// NewRetranscriber creates a retranscriber for the sessions recorded by processor
+func NewRetranscriber(processor *AsyncProcessor, sessions *session.Manager, factory TranscriberFactory) *Retranscriber {
+	return &Retranscriber{
+		processor: processor,
+		sessions:  sessions,
+		factory:   factory,
+		jobs:      make(map[string]*RetranscribeStatus),
+	}
+}
+
+// Start begins re-transcribing a session in the background. Progress is
+// published on the processor's event bus as the segments complete.
+func (r *Retranscriber) Start(req RetranscribeRequest) (RetranscribeStatus, error) {
+	r.processor.mu.RLock()
+	recorder := r.processor.recorder
+	filters := r.processor.filters
+	vocabulary := r.processor.vocabulary
+	r.processor.mu.RUnlock()
+
+	if recorder == nil {
+		return RetranscribeStatus{}, errors.New("audio is only retained when recording is enabled")
+	}
+	if _, err := r.sessions.GetSession(req.SessionID); err != nil {
+		return RetranscribeStatus{}, err
+	}
+	segments, err := recorder.Segments(req.SessionID)
+	if err != nil {
+		return RetranscribeStatus{}, err
+	}
+	if len(segments) == 0 {
+		return RetranscribeStatus{}, fmt.Errorf("no audio retained for session %s", req.SessionID)
+	}
+
+	trans, label, owned, err := chooseTranscriber(r.processor.transcriber, r.factory, req.Transcriber, req.Model)
+	if err != nil {
+		return RetranscribeStatus{}, err
+	}
+	if req.Language != "" {
+		label += " (" + req.Language + ")"
+	}
+
+	status := &RetranscribeStatus{
+		ID:          uuid.New().String(),
+		SessionID:   req.SessionID,
+		Transcriber: label,
+		Total:       len(segments),
+	}
+	r.mu.Lock()
+	r.jobs[status.ID] = status
+	snapshot := *status
+	r.mu.Unlock()
+
+	go func() {
+		if owned {
+			defer func() {
+				if err := trans.Close(); err != nil {
+					logrus.WithError(err).Warn("Failed to close re-transcription transcriber")
+				}
+			}()
+		}
+		var prompt []string
+		if vocabulary != nil {
+			prompt = vocabulary(req.SessionID)
+		}
+		r.run(status, req, trans, recorder, segments, filters, prompt)
+	}()
+
+	logrus.WithFields(logrus.Fields{
+		"job_id":      snapshot.ID,
+		"session_id":  req.SessionID,
+		"transcriber": label,
+		"segments":    len(segments),
+	}).Info("Started re-transcription")
+
+	return snapshot, nil
+}
+
+// chooseTranscriber creates the transcriber a request asked for, or returns the
+// live one if kind is empty. It also returns a label naming the transcriber and
+// whether the caller owns it and must close it.
+func chooseTranscriber(live transcriber.Transcriber, factory TranscriberFactory, kind, model string) (trans transcriber.Transcriber, label string, owned bool, err error) {
+	if kind == "" {
+		return live, "live transcriber", false, nil
+	}
+	if factory == nil {
+		return nil, "", false, errors.New("choosing a transcriber is not supported")
+	}
+
+	trans, err = factory(kind, model)
+	if err != nil {
+		return nil, "", false, fmt.Errorf("failed to create transcriber: %w", err)
+	}
+	label = strings.ToLower(kind)
+	if model != "" {
+		label += " " + filepath.Base(model)
+	}
+	return trans, label, true, nil
+}
+
+// Status returns the progress of a re-transcription
+func (r *Retranscriber) Status(jobID string) (RetranscribeStatus, bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	status, exists := r.jobs[jobID]
+	if !exists {
+		return RetranscribeStatus{}, false
+	}
+	return *status, true
+}
+
+// run transcribes every segment and stores the alternate transcript
+func (r *Retranscriber) run(status *RetranscribeStatus, req RetranscribeRequest, trans transcriber.Transcriber, recorder *recording.Recorder, segments []recording.Segment, filters *filter.Chain, vocabulary []string) {
+	config := pipeline.DefaultSpeakerDispatcherConfig()
+	config.WorkerCount = r.processor.config.WorkerCount
+	config.ProcessTimeout = retranscribeTimeout
+	dispatcher := pipeline.NewSpeakerAwareDispatcher(trans, config)
+	defer dispatcher.Stop()
+
+	eventBus := r.processor.eventBus
+	results := make([]*session.Transcript, len(segments))
+	var wg sync.WaitGroup
+
+	finish := func(failed bool) {
+		r.mu.Lock()
+		status.Completed++
+		if failed {
+			status.Failed++
+		}
+		data := feedback.RetranscriptionData{
+			JobID:     status.ID,
+			Completed: status.Completed,
+			Failed:    status.Failed,
+			Total:     status.Total,
+		}
+		r.mu.Unlock()
+
+		eventBus.Publish(feedback.Event{
+			Type:      feedback.EventRetranscriptionProgress,
+			SessionID: status.SessionID,
+			Data:      data,
+		})
+		wg.Done()
+	}
+
+	for i, segment := range segments {
+		audio, err := recorder.SegmentAudio(req.SessionID, segment)
+		wg.Add(1)
+		if err != nil {
+			logrus.WithError(err).WithField("segment_id", segment.ID).Warn("Skipping unreadable segment")
+			finish(true)
+			continue
+		}
+
+		language := segment.Language
+		if req.Language != "" {
+			language = req.Language
+		}
+		pipelineSegment := &pipeline.AudioSegment{
+			ID:         segment.ID,
+			UserID:     segment.UserID,
+			Username:   segment.Username,
+			Audio:      audio,
+			Duration:   segment.Duration(),
+			Language:   language,
+			Vocabulary: vocabulary,
+			AudioStart: segment.AudioStart,
+			AudioEnd:   segment.AudioEnd,
+			OnComplete: func(result *transcriber.TranscriptResult) {
+				text, keep := filters.Apply(filter.Segment{
+					Text:       result.Text,
+					Confidence: result.Confidence,
+					Language:   result.Language,
+				})
+				if keep && text != "" {
+					results[i] = &session.Transcript{
+						Timestamp:  segment.AudioEnd,
+						UserID:     segment.UserID,
+						Username:   segment.Username,
+						Text:       text,
+						Duration:   segment.Duration().Seconds(),
+						Language:   result.Language,
+						Confidence: result.Confidence,
+						AudioStart: segment.AudioStart,
+						AudioEnd:   segment.AudioEnd,
+						Words:      sessionWords(result.Words),
+					}
+				}
+				finish(false)
+			},
+			OnError: func(err error) {
+				logrus.WithError(err).WithField("segment_id", segment.ID).Warn("Re-transcription of segment failed")
+				finish(true)
+			},
+		}
+
+		// The per-speaker queues are bounded, so wait for room instead of dropping audio
+		for dispatcher.DispatchSegment(pipelineSegment) != nil {
+			time.Sleep(dispatchRetryDelay)
+		}
+	}
+	wg.Wait()
+
+	alternate := session.Alternate{
+		ID:          status.ID,
+		Transcriber: status.Transcriber,
+		Created:     time.Now(),
+		Transcripts: []session.Transcript{},
+	}
+	for _, t := range results {
+		if t != nil {
+			alternate.Transcripts = append(alternate.Transcripts, *t)
+		}
+	}
+	session.SortBySpeechStart(alternate.Transcripts)
+	for i := range alternate.Transcripts {
+		alternate.Transcripts[i].Sequence = int64(i + 1)
+	}
+
+	var errMessage string
+	if status.Failed == status.Total {
+		errMessage = "every segment failed to transcribe"
+	} else if err := r.sessions.AddAlternate(req.SessionID, alternate); err != nil {
+		errMessage = err.Error()
+	}
+
+	r.mu.Lock()
+	status.Done = true
+	status.Error = errMessage
+	data := feedback.RetranscriptionData{
+		JobID:     status.ID,
+		Completed: status.Completed,
+		Failed:    status.Failed,
+		Total:     status.Total,
+		Error:     errMessage,
+	}
+	r.mu.Unlock()
+
+	eventBus.Publish(feedback.Event{
+		Type:      feedback.EventRetranscriptionCompleted,
+		SessionID: status.SessionID,
+		Data:      data,
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"job_id":      status.ID,
+		"session_id":  status.SessionID,
+		"transcripts": len(alternate.Transcripts),
+		"failed":      status.Failed,
+		"error":       errMessage,
+	}).Info("Re-transcription finished")
+}
@@ -0,0 +1,119 @@
+package audio
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func mockFactory(kind, model string) (transcriber.Transcriber, error) {
+	if kind != "mock" {
+		return nil, errors.New("unknown transcriber")
+	}
+	return &transcriber.MockTranscriber{}, nil
+}
+
+func TestRetranscribeSession(t *testing.T) {
+	processor := NewAsyncProcessor(&transcriber.MockTranscriber{}, DefaultProcessorConfig())
+	defer processor.Stop()
+	sessions := session.NewManager()
+	retranscriber := NewRetranscriber(processor, sessions, mockFactory)
+
+	sessionID := sessions.CreateSession("guild", "channel")
+	_, err := retranscriber.Start(RetranscribeRequest{SessionID: sessionID})
+	assert.Error(t, err, "recording is disabled")
+
+	recorder, err := recording.NewRecorder(recording.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	processor.SetRecorder(recorder)
+
+	_, err = retranscriber.Start(RetranscribeRequest{SessionID: sessionID})
+	assert.Error(t, err, "no audio retained yet")
+
+	start := time.Now()
+	for i, speaker := range []string{"bob", "alice", "bob"} {
+		require.NoError(t, recorder.SaveSegment(sessionID, recording.Segment{
+			ID:         fmt.Sprintf("segment-%d", i),
+			UserID:     speaker,
+			Username:   speaker,
+			AudioStart: start.Add(time.Duration(i) * time.Second),
+			AudioEnd:   start.Add(time.Duration(i)*time.Second + 500*time.Millisecond),
+		}, make([]byte, (i+1)*9600)))
+	}
+
+	_, err = retranscriber.Start(RetranscribeRequest{SessionID: sessionID, Transcriber: "unknown"})
+	assert.Error(t, err)
+
+	completed := make(chan feedback.RetranscriptionData, 1)
+	processor.GetEventBus().Subscribe(feedback.EventRetranscriptionCompleted, func(event feedback.Event) {
+		completed <- event.Data.(feedback.RetranscriptionData)
+	})
+
+	status, err := retranscriber.Start(RetranscribeRequest{SessionID: sessionID, Transcriber: "mock", Model: "/models/large.bin"})
+	require.NoError(t, err)
+	assert.Equal(t, 3, status.Total)
+	assert.Equal(t, "mock large.bin", status.Transcriber)
+
+	select {
+	case data := <-completed:
+		assert.Equal(t, status.ID, data.JobID)
+		assert.Equal(t, 3, data.Completed)
+		assert.Zero(t, data.Failed)
+		assert.Empty(t, data.Error)
+	case <-time.After(5 * time.Second):
+		t.Fatal("re-transcription did not complete")
+	}
+
+	status, exists := retranscriber.Status(status.ID)
+	require.True(t, exists)
+	assert.True(t, status.Done)
+
+	// The live transcript is untouched; the alternate is in speaking order
+	sessionData, err := sessions.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Empty(t, sessionData.Transcripts)
+
+	page, err := sessions.QueryTranscripts(sessionID, session.TranscriptQuery{Alternate: status.ID})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 3)
+	for i, transcript := range page.Transcripts {
+		assert.Equal(t, int64(i+1), transcript.Sequence)
+		assert.Equal(t, fmt.Sprintf("[Mock transcript: %d bytes of audio]", (i+1)*9600), transcript.Text)
+	}
+	assert.Equal(t, "alice", page.Transcripts[1].UserID)
+}
+
+func TestRoutedSegmentsAreRetained(t *testing.T) {
+	processor := NewAsyncProcessor(&transcriber.MockTranscriber{}, DefaultProcessorConfig())
+	recorder, err := recording.NewRecorder(recording.Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	processor.SetRecorder(recorder)
+
+	start := time.Now()
+	processor.segmentChan <- &AudioSegment{
+		ID:         "segment-1",
+		SessionID:  "session-1",
+		UserID:     "bob",
+		Username:   "bob",
+		Audio:      make([]byte, 9600),
+		AudioStart: start,
+		AudioEnd:   start.Add(100 * time.Millisecond),
+	}
+
+	// Stop waits for the recording writer to drain
+	require.Eventually(t, func() bool { return len(processor.segmentChan) == 0 }, time.Second, 10*time.Millisecond)
+	processor.Stop()
+
+	segments, err := recorder.Segments("session-1")
+	require.NoError(t, err)
+	require.Len(t, segments, 1)
+	assert.Equal(t, "segment-1", segments[0].ID)
+}
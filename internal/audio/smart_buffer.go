@@ -6,6 +6,9 @@ import (
 	"sync"
 	"time"
 
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
 	"github.com/google/uuid"
 	"github.com/sirupsen/logrus"
 )
@@ -31,6 +34,7 @@ type AudioBuffer struct {
 	sampleRate     int
 	channels       int
 	bytesPerSample int
+	now            func() time.Time
 }
 
 // NewAudioBuffer creates a new audio buffer
@@ -40,18 +44,23 @@ func NewAudioBuffer(sampleRate, channels int) *AudioBuffer {
 		sampleRate:     sampleRate,
 		channels:       channels,
 		bytesPerSample: 2, // 16-bit audio
+		now:            time.Now,
 	}
 }
 
-// Append adds PCM data to the buffer
+// Append adds PCM data to the buffer. Calls without audio, such as Discord's
+// comfort noise, only count as silence and don't move the start or end time.
 func (b *AudioBuffer) Append(pcm []byte, isSpeech bool) {
-	if b.firstWriteTime.IsZero() {
-		b.firstWriteTime = time.Now()
+	now := b.now()
+	if len(pcm) > 0 {
+		if b.firstWriteTime.IsZero() {
+			b.firstWriteTime = now
+		}
+		b.lastWriteTime = now
 	}
-	b.lastWriteTime = time.Now()
 
 	if isSpeech {
-		b.lastSpeechTime = time.Now()
+		b.lastSpeechTime = now
 	}
 
 	b.data.Write(pcm)
@@ -72,6 +81,16 @@ func (b *AudioBuffer) GetPCM() []byte {
 	return b.data.Bytes()
 }
 
+// StartTime returns when the first audio was written
+func (b *AudioBuffer) StartTime() time.Time {
+	return b.firstWriteTime
+}
+
+// EndTime returns when the last audio was written
+func (b *AudioBuffer) EndTime() time.Time {
+	return b.lastWriteTime
+}
+
 // LastSpeechTime returns when speech was last detected
 func (b *AudioBuffer) LastSpeechTime() time.Time {
 	return b.lastSpeechTime
@@ -82,7 +101,7 @@ func (b *AudioBuffer) SilenceDuration() time.Duration {
 	if b.lastSpeechTime.IsZero() {
 		return 0
 	}
-	return time.Since(b.lastSpeechTime)
+	return b.now().Sub(b.lastSpeechTime)
 }
 
 // Size returns the buffer size in bytes
@@ -131,10 +150,29 @@ type SmartUserBuffer struct {
 	// Output channel for segments
 	outputChan chan<- *AudioSegment
 
-	// Callback for transcription completion
-	onTranscriptionComplete func(sessionID, userID, username, text string) error
+	// Callback for transcription completion, returns the sequence the transcript was stored under
+	onTranscriptionComplete func(sessionID string, transcript session.Transcript) (int64, error)
+
+	// Looks up the language to transcribe this user in, per segment
+	languageResolver func(sessionID, userID string) string
+
+	// Looks up the terms to prompt the transcriber with, per segment
+	vocabularyResolver func(sessionID string) []string
+
+	// Translates finished segments, if the session asks for it
+	translate TranslateFunc
+
+	// Cleans up transcriber output before it is stored; nil keeps it as is
+	filters *filter.Chain
+
+	// Clock for timestamps and silence timing; replaced when replaying recorded audio
+	now func() time.Time
 }
 
+// TranslateFunc translates a finished segment of a session once its transcript
+// has been stored under sequence. It must not block the transcription worker.
+type TranslateFunc func(sessionID string, sequence int64, audio []byte, language string, result *transcriber.TranscriptResult)
+
 // BufferConfig holds configuration for smart buffer
 type BufferConfig struct {
 	SampleRate        int
@@ -174,7 +212,7 @@ func NewSmartUserBuffer(userID, username string, ssrc uint32, outputChan chan<-
 }
 
 // NewSmartUserBufferWithCallback creates a new smart buffer for a user with transcription callback
-func NewSmartUserBufferWithCallback(userID, username string, ssrc uint32, outputChan chan<- *AudioSegment, config BufferConfig, onTranscriptionComplete func(sessionID, userID, username, text string) error) *SmartUserBuffer {
+func NewSmartUserBufferWithCallback(userID, username string, ssrc uint32, outputChan chan<- *AudioSegment, config BufferConfig, onTranscriptionComplete func(sessionID string, transcript session.Transcript) (int64, error)) *SmartUserBuffer {
 	return &SmartUserBuffer{
 		userID:                  userID,
 		ssrc:                    ssrc,
@@ -185,6 +223,7 @@ func NewSmartUserBufferWithCallback(userID, username string, ssrc uint32, output
 		metrics:                 &BufferMetrics{},
 		outputChan:              outputChan,
 		onTranscriptionComplete: onTranscriptionComplete,
+		now:                     time.Now,
 	}
 }
 
@@ -195,6 +234,13 @@ func (b *SmartUserBuffer) SetSessionID(sessionID string) {
 	b.sessionID = sessionID
 }
 
+// SessionID returns the session this buffer records into
+func (b *SmartUserBuffer) SessionID() string {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.sessionID
+}
+
 // SetUserResolver sets the user resolver for dynamic username resolution
 func (b *SmartUserBuffer) SetUserResolver(resolver UserResolver) {
 	b.mu.Lock()
@@ -202,6 +248,50 @@ func (b *SmartUserBuffer) SetUserResolver(resolver UserResolver) {
 	b.userResolver = resolver
 }
 
+// SetLanguageResolver sets how the transcription language is looked up for each segment
+func (b *SmartUserBuffer) SetLanguageResolver(resolver func(sessionID, userID string) string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.languageResolver = resolver
+}
+
+// SetVocabularyResolver sets how custom vocabulary is looked up for each segment
+func (b *SmartUserBuffer) SetVocabularyResolver(resolver func(sessionID string) []string) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.vocabularyResolver = resolver
+}
+
+// SetFilterChain sets the text filters applied to transcriptions before they are stored
+func (b *SmartUserBuffer) SetFilterChain(filters *filter.Chain) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.filters = filters
+}
+
+// SetTranslateFunc sets how finished segments are translated
+func (b *SmartUserBuffer) SetTranslateFunc(translate TranslateFunc) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.translate = translate
+}
+
+// SetClock replaces the clock used for audio timestamps and silence timing, so
+// recorded audio can be fed faster than real time with the same segmentation
+func (b *SmartUserBuffer) SetClock(now func() time.Time) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.now = now
+	b.activeBuffer.now = now
+}
+
+// newAudioBuffer creates an empty buffer on the buffer's clock
+func (b *SmartUserBuffer) newAudioBuffer() *AudioBuffer {
+	buffer := NewAudioBuffer(b.config.SampleRate, b.config.Channels)
+	buffer.now = b.now
+	return buffer
+}
+
 // getCurrentUsername gets the current username for this SSRC
 func (b *SmartUserBuffer) getCurrentUsername() string {
 	if b.userResolver != nil {
@@ -227,7 +317,7 @@ func (b *SmartUserBuffer) ProcessAudio(pcm []byte, isSpeech bool) {
 		decision := b.vad.ShouldTranscribe(b.activeBuffer)
 
 		// Or rapid conversational response (within 5s of last transcript)
-		if !decision.Should && time.Since(b.lastTranscriptTime) < 5*time.Second {
+		if !decision.Should && b.now().Sub(b.lastTranscriptTime) < 5*time.Second {
 			if b.activeBuffer.Duration() > 500*time.Millisecond && b.activeBuffer.SilenceDuration() > 300*time.Millisecond {
 				decision = TranscribeDecision{
 					Should:   true,
@@ -268,20 +358,37 @@ func (b *SmartUserBuffer) triggerTranscription(decision TranscribeDecision) {
 
 	// Swap buffers - instant, non-blocking
 	b.processingBuffer = b.activeBuffer
-	b.activeBuffer = NewAudioBuffer(b.config.SampleRate, b.config.Channels)
+	b.activeBuffer = b.newAudioBuffer()
 	b.isProcessing = true
 
 	// Get context if not expired
 	var context string
-	if time.Since(b.lastTranscriptTime) < b.config.ContextExpiration && b.lastTranscript != "" {
+	if b.now().Sub(b.lastTranscriptTime) < b.config.ContextExpiration && b.lastTranscript != "" {
 		context = b.lastTranscript
 		logrus.WithFields(logrus.Fields{
 			"user":          b.getCurrentUsername(),
-			"context_age":   time.Since(b.lastTranscriptTime),
+			"context_age":   b.now().Sub(b.lastTranscriptTime),
 			"context_chars": len(context),
 		}).Debug("Using previous transcript as context")
 	}
 
+	audio := b.processingBuffer.GetPCM()
+	audioDuration := b.processingBuffer.Duration()
+	audioStart := b.processingBuffer.StartTime()
+	audioEnd := b.processingBuffer.EndTime()
+	translate := b.translate
+	filters := b.filters
+
+	// Resolved per segment so language changes apply mid-session
+	var language string
+	if b.languageResolver != nil {
+		language = b.languageResolver(b.sessionID, b.userID)
+	}
+	var vocabulary []string
+	if b.vocabularyResolver != nil {
+		vocabulary = b.vocabularyResolver(b.sessionID)
+	}
+
 	// Create segment for processing
 	segment := &AudioSegment{
 		ID:          uuid.New().String(),
@@ -289,20 +396,46 @@ func (b *SmartUserBuffer) triggerTranscription(decision TranscribeDecision) {
 		UserID:      b.userID,
 		Username:    b.getCurrentUsername(),
 		SSRC:        b.ssrc,
-		Audio:       b.processingBuffer.GetPCM(),
-		Duration:    b.processingBuffer.Duration(),
+		Audio:       audio,
+		Duration:    audioDuration,
 		Context:     context,
+		Language:    language,
+		Vocabulary:  vocabulary,
+		AudioStart:  audioStart,
+		AudioEnd:    audioEnd,
 		Priority:    decision.Priority,
 		Reason:      decision.Reason,
 		SubmittedAt: time.Now(),
-		OnComplete: func(text string) {
+		OnComplete: func(result *transcriber.TranscriptResult) {
+			text, keep := filters.Apply(filter.Segment{
+				Text:       result.Text,
+				Confidence: result.Confidence,
+				Language:   result.Language,
+			})
 			b.mu.Lock()
-			b.lastTranscript = text
-			b.lastTranscriptTime = time.Now()
 			b.isProcessing = false
+			if keep {
+				// Dropped text must not become the next segment's prompt
+				b.lastTranscript = text
+				b.lastTranscriptTime = b.now()
+			}
 			sessionID := b.sessionID
 			b.mu.Unlock()
 
+			if !keep {
+				logrus.WithFields(logrus.Fields{
+					"user":       b.getCurrentUsername(),
+					"session_id": sessionID,
+					"text":       result.Text,
+				}).Debug("Transcription filtered out")
+				return
+			}
+			if text != result.Text {
+				filtered := *result
+				filtered.Text = text
+				result = &filtered
+			}
+
 			logrus.WithFields(logrus.Fields{
 				"user":       b.getCurrentUsername(),
 				"length":     len(text),
@@ -312,7 +445,17 @@ func (b *SmartUserBuffer) triggerTranscription(decision TranscribeDecision) {
 
 			// Call session manager callback if available
 			if b.onTranscriptionComplete != nil && text != "" {
-				err := b.onTranscriptionComplete(sessionID, b.userID, b.getCurrentUsername(), text)
+				sequence, err := b.onTranscriptionComplete(sessionID, session.Transcript{
+					UserID:     b.userID,
+					Username:   b.getCurrentUsername(),
+					Text:       text,
+					Duration:   audioDuration.Seconds(),
+					Language:   result.Language,
+					Confidence: result.Confidence,
+					AudioStart: audioStart,
+					AudioEnd:   audioEnd,
+					Words:      sessionWords(result.Words),
+				})
 				if err != nil {
 					logrus.WithError(err).WithFields(logrus.Fields{
 						"user":       b.getCurrentUsername(),
@@ -325,6 +468,10 @@ func (b *SmartUserBuffer) triggerTranscription(decision TranscribeDecision) {
 						"session_id": sessionID,
 						"text":       text,
 					}).Info("Transcript successfully added to session")
+
+					if translate != nil {
+						translate(sessionID, sequence, audio, language, result)
+					}
 				}
 			} else {
 				logrus.WithFields(logrus.Fields{
@@ -368,6 +515,25 @@ func (b *SmartUserBuffer) triggerTranscription(decision TranscribeDecision) {
 	}
 }
 
+// Flush submits whatever audio is in the active buffer, bypassing VAD.
+// It returns true if a segment was queued.
+func (b *SmartUserBuffer) Flush() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.activeBuffer.Size() == 0 {
+		return false
+	}
+
+	before := b.metrics.SegmentsCreated
+	b.triggerTranscription(TranscribeDecision{
+		Should:   true,
+		Priority: PriorityHigh,
+		Reason:   "Session ending",
+	})
+	return b.metrics.SegmentsCreated > before && b.isProcessing
+}
+
 // GetMetrics returns buffer metrics
 func (b *SmartUserBuffer) GetMetrics() BufferMetrics {
 	b.mu.Lock()
@@ -391,7 +557,7 @@ func (b *SmartUserBuffer) GetStatus() BufferStatus {
 		BufferDuration:  b.activeBuffer.Duration(),
 		IsProcessing:    b.isProcessing,
 		HasContext:      b.lastTranscript != "",
-		ContextAge:      time.Since(b.lastTranscriptTime),
+		ContextAge:      b.now().Sub(b.lastTranscriptTime),
 		SegmentsCreated: b.metrics.SegmentsCreated,
 		DroppedSegments: b.metrics.DroppedSegments,
 	}
@@ -422,3 +588,20 @@ func (b *SmartUserBuffer) Reset() {
 	b.lastTranscriptTime = time.Time{}
 	b.isProcessing = false
 }
+
+// sessionWords converts recognizer word timings for storage on a transcript
+func sessionWords(words []transcriber.WordTiming) []session.Word {
+	if len(words) == 0 {
+		return nil
+	}
+	converted := make([]session.Word, len(words))
+	for i, w := range words {
+		converted[i] = session.Word{
+			Text:       w.Word,
+			Start:      w.StartTime.Seconds(),
+			End:        w.EndTime.Seconds(),
+			Confidence: w.Confidence,
+		}
+	}
+	return converted
+}
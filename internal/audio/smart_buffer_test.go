@@ -0,0 +1,142 @@
+package audio
+
+import (
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSmartUserBufferFlush(t *testing.T) {
+	config := DefaultBufferConfig()
+	outputChan := make(chan *AudioSegment, 1)
+	buffer := NewSmartUserBuffer("user", "User", 1234, outputChan, config)
+	buffer.SetSessionID("session-1")
+
+	// Nothing buffered - nothing to flush
+	assert.False(t, buffer.Flush())
+
+	// 400ms of speech is below every automatic trigger
+	frame := make([]byte, frameSize*channels*bytesPerSample)
+	for i := 0; i < 20; i++ {
+		buffer.ProcessAudio(frame, true)
+	}
+	require.Empty(t, outputChan)
+
+	assert.True(t, buffer.Flush())
+
+	select {
+	case segment := <-outputChan:
+		assert.Equal(t, "session-1", segment.SessionID)
+		assert.Equal(t, "Session ending", segment.Reason)
+		assert.Equal(t, 400*time.Millisecond, segment.Duration)
+	default:
+		t.Fatal("expected flushed segment")
+	}
+}
+
+func TestSmartUserBufferIgnoresComfortNoiseTimes(t *testing.T) {
+	outputChan := make(chan *AudioSegment, 1)
+	buffer := NewSmartUserBuffer("user", "User", 1234, outputChan, DefaultBufferConfig())
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	now := start
+	buffer.SetClock(func() time.Time { return now })
+
+	// Trailing silence packets of an earlier segment reach the empty buffer
+	for i := 0; i < 5; i++ {
+		buffer.ProcessAudio(nil, false)
+		now = now.Add(20 * time.Millisecond)
+	}
+
+	now = start.Add(10 * time.Second)
+	frame := make([]byte, frameSize*channels*bytesPerSample)
+	for i := 0; i < 20; i++ {
+		buffer.ProcessAudio(frame, true)
+		now = now.Add(20 * time.Millisecond)
+	}
+	buffer.ProcessAudio(nil, false)
+	require.True(t, buffer.Flush())
+
+	segment := <-outputChan
+	assert.Equal(t, start.Add(10*time.Second), segment.AudioStart, "speech starts when audio arrives")
+	assert.Equal(t, start.Add(10*time.Second+380*time.Millisecond), segment.AudioEnd, "ends with the last audio")
+}
+
+func TestSmartUserBufferResolvesLanguagePerSegment(t *testing.T) {
+	config := DefaultBufferConfig()
+	outputChan := make(chan *AudioSegment, 1)
+	buffer := NewSmartUserBuffer("user", "User", 1234, outputChan, config)
+	buffer.SetSessionID("session-1")
+
+	language := "de"
+	buffer.SetLanguageResolver(func(sessionID, userID string) string {
+		assert.Equal(t, "session-1", sessionID)
+		assert.Equal(t, "user", userID)
+		return language
+	})
+
+	frame := make([]byte, frameSize*channels*bytesPerSample)
+	for i := 0; i < 20; i++ {
+		buffer.ProcessAudio(frame, true)
+	}
+	require.True(t, buffer.Flush())
+	assert.Equal(t, "de", (<-outputChan).Language)
+}
+
+func TestSmartUserBufferFiltersTranscripts(t *testing.T) {
+	config := DefaultBufferConfig()
+	outputChan := make(chan *AudioSegment, 1)
+	var stored []session.Transcript
+	buffer := NewSmartUserBufferWithCallback("user", "User", 1234, outputChan, config, func(sessionID string, transcript session.Transcript) (int64, error) {
+		stored = append(stored, transcript)
+		return int64(len(stored)), nil
+	})
+	buffer.SetSessionID("session-1")
+	buffer.SetFilterChain(filter.NewChain(filter.NewHallucinationFilter(nil), filter.NewNoiseTagFilter()))
+
+	// Translation follows the stored transcript
+	var translated []int64
+	buffer.SetTranslateFunc(func(sessionID string, sequence int64, audio []byte, language string, result *transcriber.TranscriptResult) {
+		require.Len(t, stored, int(sequence), "transcript is stored first")
+		translated = append(translated, sequence)
+	})
+
+	frame := make([]byte, frameSize*channels*bytesPerSample)
+	transcribe := func(text string) {
+		for i := 0; i < 20; i++ {
+			buffer.ProcessAudio(frame, true)
+		}
+		require.True(t, buffer.Flush())
+		(<-outputChan).OnComplete(&transcriber.TranscriptResult{Text: text})
+	}
+
+	transcribe("[MUSIC] Ship it")
+	transcribe("Thanks for watching!")
+
+	require.Len(t, stored, 1)
+	assert.Equal(t, "Ship it", stored[0].Text)
+	assert.Equal(t, []int64{1}, translated, "filtered text isn't translated")
+
+	// Dropped text is not used as the next segment's context
+	for i := 0; i < 20; i++ {
+		buffer.ProcessAudio(frame, true)
+	}
+	require.True(t, buffer.Flush())
+	assert.Equal(t, "Ship it", (<-outputChan).Context)
+}
+
+func TestSmartUserBufferFlushSkipsTinyBuffer(t *testing.T) {
+	config := DefaultBufferConfig()
+	outputChan := make(chan *AudioSegment, 1)
+	buffer := NewSmartUserBuffer("user", "User", 1234, outputChan, config)
+
+	// A single 20ms frame is below MinSpeechDuration
+	buffer.ProcessAudio(make([]byte, frameSize*channels*bytesPerSample), true)
+
+	assert.False(t, buffer.Flush())
+	assert.Empty(t, outputChan)
+}
@@ -0,0 +1,124 @@
+package audio
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/translator"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// translationTimeout bounds one translation so a slow backend can't back up the translation queue
+	translationTimeout = 20 * time.Second
+
+	noSpeechText = "[No speech detected]"
+)
+
+// TranslationStage translates finished transcripts. English targets use the
+// transcriber's translate mode when it has one; other targets, and transcribers
+// without it, need a text translator.
+type TranslationStage struct {
+	speech transcriber.SpeechTranslator // nil if the transcriber can't translate
+	text   translator.Translator        // nil if no text translator is configured
+
+	// Cleans up speech translations, which hallucinate like transcriptions do
+	filters *filter.Chain
+
+	timeout time.Duration // Bounds one translation
+	mu      sync.RWMutex
+}
+
+// NewTranslationStage creates a translation stage for a transcriber
+func NewTranslationStage(trans transcriber.Transcriber) *TranslationStage {
+	stage := &TranslationStage{timeout: translationTimeout}
+	if speech, ok := trans.(transcriber.SpeechTranslator); ok {
+		stage.speech = speech
+	}
+	return stage
+}
+
+// SetTranslator configures the text translator used for targets the transcriber can't handle
+func (s *TranslationStage) SetTranslator(t translator.Translator) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.text = t
+}
+
+// SetFilterChain sets the text filters applied to speech translations
+func (s *TranslationStage) SetFilterChain(filters *filter.Chain) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.filters = filters
+}
+
+// Translate returns the transcript of audio in the target language. language is
+// the language the audio was transcribed with, result the transcription. The
+// translation is cancelled when ctx ends or the stage's timeout passes.
+func (s *TranslationStage) Translate(ctx context.Context, audio []byte, language string, result *transcriber.TranscriptResult, target string) (string, error) {
+	if target == "" || result.Text == "" || result.Text == noSpeechText {
+		return "", nil
+	}
+
+	// Nothing to do if the speaker already used the target language
+	if result.Language != "" && primaryLanguage(result.Language) == primaryLanguage(target) {
+		return result.Text, nil
+	}
+
+	s.mu.RLock()
+	text := s.text
+	filters := s.filters
+	s.mu.RUnlock()
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	startTime := time.Now()
+	var translation string
+	switch {
+	case primaryLanguage(target) == "en" && s.speech != nil:
+		translated, err := s.speech.TranslateSpeech(ctx, audio, transcriber.TranscriptionOptions{Language: language})
+		if err != nil {
+			return "", fmt.Errorf("error translating speech: %w", err)
+		}
+		filtered, keep := filters.Apply(filter.Segment{
+			Text:       translated.Text,
+			Confidence: translated.Confidence,
+			Language:   target,
+		})
+		if !keep {
+			logrus.WithField("text", translated.Text).Debug("Speech translation filtered out")
+			return "", nil
+		}
+		translation = filtered
+
+	case text != nil:
+		translated, err := text.Translate(ctx, result.Text, result.Language, target)
+		if err != nil {
+			return "", fmt.Errorf("error translating text: %w", err)
+		}
+		translation = translated
+
+	default:
+		return "", fmt.Errorf("no translator configured for %s", target)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"source":   result.Language,
+		"target":   target,
+		"duration": time.Since(startTime),
+	}).Debug("Transcript translated")
+
+	return translation, nil
+}
+
+// primaryLanguage reduces a language tag to its primary subtag (de-DE -> de)
+func primaryLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return primary
+}
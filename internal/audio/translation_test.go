@@ -0,0 +1,113 @@
+package audio
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/filter"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/translator"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// speechTranslatingTranscriber is a mock transcriber with whisper's translate mode
+type speechTranslatingTranscriber struct {
+	transcriber.MockTranscriber
+	calls []transcriber.TranscriptionOptions
+	text  string        // Translation to return; empty returns "Hello everyone"
+	delay time.Duration // How long translating takes
+}
+
+func (st *speechTranslatingTranscriber) TranslateSpeech(ctx context.Context, audio []byte, opts transcriber.TranscriptionOptions) (*transcriber.TranscriptResult, error) {
+	st.calls = append(st.calls, opts)
+	select {
+	case <-time.After(st.delay):
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+	text := st.text
+	if text == "" {
+		text = "Hello everyone"
+	}
+	return &transcriber.TranscriptResult{Text: text, Language: "en"}, nil
+}
+
+func TestTranslationStage(t *testing.T) {
+	trans := &speechTranslatingTranscriber{}
+	stage := NewTranslationStage(trans)
+	result := &transcriber.TranscriptResult{Text: "Hallo zusammen", Language: "de"}
+
+	// No target - no translation
+	text, err := stage.Translate(context.Background(), nil, "", result, "")
+	require.NoError(t, err)
+	assert.Empty(t, text)
+
+	// Already in the target language
+	text, err = stage.Translate(context.Background(), nil, "", result, "de-DE")
+	require.NoError(t, err)
+	assert.Equal(t, "Hallo zusammen", text)
+
+	// English comes from the transcriber's translate mode
+	text, err = stage.Translate(context.Background(), []byte{1, 2}, "de", result, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello everyone", text)
+	require.Len(t, trans.calls, 1)
+	assert.Equal(t, "de", trans.calls[0].Language)
+
+	// Other targets need a text translator
+	_, err = stage.Translate(context.Background(), nil, "", result, "fr")
+	assert.Error(t, err)
+
+	stage.SetTranslator(&translator.MockTranslator{})
+	text, err = stage.Translate(context.Background(), nil, "", result, "fr")
+	require.NoError(t, err)
+	assert.Equal(t, "[fr] Hallo zusammen", text)
+
+	// Placeholders aren't translated
+	text, err = stage.Translate(context.Background(), nil, "", &transcriber.TranscriptResult{Text: noSpeechText}, "fr")
+	require.NoError(t, err)
+	assert.Empty(t, text)
+}
+
+func TestTranslationStageWithoutSpeechTranslation(t *testing.T) {
+	stage := NewTranslationStage(&transcriber.MockTranscriber{})
+	result := &transcriber.TranscriptResult{Text: "Hallo", Language: "de"}
+
+	_, err := stage.Translate(context.Background(), nil, "", result, "en")
+	assert.Error(t, err)
+
+	stage.SetTranslator(&translator.MockTranslator{})
+	text, err := stage.Translate(context.Background(), nil, "", result, "en")
+	require.NoError(t, err)
+	assert.Equal(t, "[en] Hallo", text)
+}
+
+func TestTranslationStageFiltersSpeechTranslation(t *testing.T) {
+	trans := &speechTranslatingTranscriber{text: "Please subscribe to my channel"}
+	stage := NewTranslationStage(trans)
+	stage.SetFilterChain(filter.NewChain(filter.NewHallucinationFilter(nil)))
+	result := &transcriber.TranscriptResult{Text: "Hallo zusammen", Language: "de"}
+
+	text, err := stage.Translate(context.Background(), []byte{1, 2}, "de", result, "en")
+	require.NoError(t, err)
+	assert.Empty(t, text, "hallucinated translation is dropped")
+}
+
+func TestTranslationStageSpeechTimeout(t *testing.T) {
+	trans := &speechTranslatingTranscriber{delay: 500 * time.Millisecond}
+	stage := NewTranslationStage(trans)
+	stage.timeout = 20 * time.Millisecond
+	result := &transcriber.TranscriptResult{Text: "Hallo zusammen", Language: "de"}
+
+	start := time.Now()
+	_, err := stage.Translate(context.Background(), []byte{1, 2}, "de", result, "en")
+	assert.ErrorIs(t, err, context.DeadlineExceeded, "the transcriber is cancelled")
+	assert.Less(t, time.Since(start), 400*time.Millisecond)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = stage.Translate(ctx, []byte{1, 2}, "de", result, "en")
+	assert.ErrorIs(t, err, context.Canceled)
+}
@@ -1,16 +1,24 @@
 package bot
 
 import (
+	"context"
 	"fmt"
+	"sort"
 	"sync"
 	"time"
 
 	"github.com/bwmarrin/discordgo"
 	"github.com/fankserver/discord-voice-mcp/internal/audio"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/internal/vocabulary"
+	"github.com/fankserver/discord-voice-mcp/pkg/tts"
 	"github.com/sirupsen/logrus"
 )
 
+// receiveShutdownTimeout bounds how long leaving a channel waits for the
+// receive loop to flush buffered audio
+const receiveShutdownTimeout = 5 * time.Second
+
 // UserInfo stores user information for transcription
 type UserInfo struct {
 	UserID   string
@@ -18,16 +26,19 @@ type UserInfo struct {
 	Nickname string // Server-specific nickname if available
 }
 
-// VoiceBot manages Discord voice connections
+// VoiceBot manages Discord voice connections, at most one per guild as
+// Discord allows
 type VoiceBot struct {
-	discord           *discordgo.Session
-	sessions          *session.Manager
-	audioProcessor    audio.VoiceProcessor // Now uses interface for flexibility
-	voiceConn         *discordgo.VoiceConnection
-	followUserID      string             // User ID to follow
-	autoFollow        bool               // Whether to auto-follow user
-	simpleSSRCManager *SimpleSSRCManager // Simple deterministic SSRC mapping
-	mu                sync.Mutex
+	discord        *discordgo.Session
+	sessions       *session.Manager
+	audioProcessor audio.VoiceProcessor        // Now uses interface for flexibility
+	connections    map[string]*voiceConnection // Active voice connections by guild ID
+	guildLocks     map[string]*sync.Mutex      // Serializes joining and leaving per guild
+	synthesizer    tts.Synthesizer             // Text-to-speech backend, nil if speaking is disabled
+	vocabulary     *vocabulary.Store           // Per-guild terms to prompt the transcriber with
+	followUserID   string                      // User ID to follow
+	autoFollow     bool                        // Whether to auto-follow user
+	mu             sync.Mutex
 }
 
 // New creates a new VoiceBot instance
@@ -38,10 +49,12 @@ func New(token string, sessionManager *session.Manager, audioProcessor audio.Voi
 	}
 
 	bot := &VoiceBot{
-		discord:           discord,
-		sessions:          sessionManager,
-		audioProcessor:    audioProcessor,
-		simpleSSRCManager: NewSimpleSSRCManager(),
+		discord:        discord,
+		sessions:       sessionManager,
+		audioProcessor: audioProcessor,
+		connections:    make(map[string]*voiceConnection),
+		guildLocks:     make(map[string]*sync.Mutex),
+		vocabulary:     vocabulary.NewStore(),
 	}
 
 	// Register handlers
@@ -63,20 +76,21 @@ func (vb *VoiceBot) Connect() error {
 
 // Disconnect closes Discord connection
 func (vb *VoiceBot) Disconnect() error {
-	vb.LeaveChannel()
+	vb.LeaveChannel("")
 	return vb.discord.Close()
 }
 
-// JoinChannel joins a voice channel
+// JoinChannel joins a voice channel. Connections in other guilds are kept;
+// one in the same guild is closed first, ending its session.
 func (vb *VoiceBot) JoinChannel(guildID, channelID string) error {
-	vb.mu.Lock()
-	defer vb.mu.Unlock()
+	unlock := vb.lockGuild(guildID)
+	defer unlock()
 
-	// Leave current channel if connected
-	if vb.voiceConn != nil {
-		if err := vb.voiceConn.Disconnect(); err != nil {
-			logrus.WithError(err).Debug("Error disconnecting from previous channel")
-		}
+	vb.mu.Lock()
+	previous := vb.takeConnectionLocked(guildID)
+	vb.mu.Unlock()
+	if previous != nil {
+		previous.close()
 	}
 
 	// Join new channel - muted but NOT deafened to receive voice
@@ -96,7 +110,40 @@ func (vb *VoiceBot) JoinChannel(guildID, channelID string) error {
 		"receiving":  true,
 	}).Debug("Voice connection established")
 
-	vb.voiceConn = vc
+	conn := newVoiceConnection(guildID, channelID, vc)
+	conn.player = vb.newPlayer(vc)
+
+	// Start a new session
+	sessionID := vb.sessions.CreateSession(guildID, channelID)
+	logrus.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"guild_id":   guildID,
+		"channel_id": channelID,
+	}).Info("Started voice session")
+
+	// Important limitation: Users already speaking need to toggle their mic
+	logrus.WithFields(logrus.Fields{
+		"guild_id":   guildID,
+		"channel_id": channelID,
+	}).Warn("⚠️ Users currently speaking need to toggle mute/unmute once for identification (Discord API limitation)")
+
+	// Start processing voice (the connection resolves its own SSRCs). The
+	// session is ended once the loop returns, whatever the reason.
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+	conn.sessionID = sessionID
+	conn.stopReceive = cancel
+	conn.receiveDone = done
+	go func() {
+		defer close(done)
+		vb.audioProcessor.ProcessVoiceReceive(ctx, vc, vb.sessions, sessionID, conn)
+		vb.endSession(sessionID)
+	}()
+
+	vb.mu.Lock()
+	vb.connections[guildID] = conn
+	vb.mu.Unlock()
+
 	// Register voice speaking handler on the voice connection
 	vc.AddHandler(vb.voiceSpeakingUpdate)
 	logrus.WithField("handler_count", len(vc.OpusRecv)).Debug("Registered VoiceSpeakingUpdate handler on voice connection")
@@ -118,44 +165,86 @@ func (vb *VoiceBot) JoinChannel(guildID, channelID string) error {
 		logrus.Debug("Triggered speaking state change to activate voice events")
 	}()
 
-	// Set channel context for simple SSRC manager
-	vb.simpleSSRCManager.SetChannel(guildID, channelID)
+	return nil
+}
 
-	// Start a new session
-	sessionID := vb.sessions.CreateSession(guildID, channelID)
-	logrus.WithFields(logrus.Fields{
-		"session_id": sessionID,
-		"guild_id":   guildID,
-		"channel_id": channelID,
-	}).Info("Started voice session")
+// LeaveChannel leaves the voice channel in a guild, or every voice channel
+// if guildID is empty. Returns the number of channels left.
+func (vb *VoiceBot) LeaveChannel(guildID string) int {
+	guildIDs := []string{guildID}
+	if guildID == "" {
+		vb.mu.Lock()
+		guildIDs = make([]string, 0, len(vb.connections))
+		for id := range vb.connections {
+			guildIDs = append(guildIDs, id)
+		}
+		vb.mu.Unlock()
+	}
 
-	// Important limitation: Users already speaking need to toggle their mic
-	logrus.WithFields(logrus.Fields{
-		"guild_id":   guildID,
-		"channel_id": channelID,
-	}).Warn("⚠️ Users currently speaking need to toggle mute/unmute once for identification (Discord API limitation)")
+	left := 0
+	for _, id := range guildIDs {
+		if vb.leaveGuild(id) {
+			logrus.WithField("guild_id", id).Info("Left voice channel")
+			left++
+		}
+	}
+	return left
+}
 
-	// Start processing voice (pass bot as UserResolver)
-	go vb.audioProcessor.ProcessVoiceReceive(vc, vb.sessions, sessionID, vb)
+// leaveGuild closes the connection in a guild, if any
+func (vb *VoiceBot) leaveGuild(guildID string) bool {
+	unlock := vb.lockGuild(guildID)
+	defer unlock()
 
-	return nil
+	vb.mu.Lock()
+	conn := vb.takeConnectionLocked(guildID)
+	vb.mu.Unlock()
+	if conn == nil {
+		return false
+	}
+	conn.close()
+	return true
 }
 
-// LeaveChannel leaves the current voice channel
-func (vb *VoiceBot) LeaveChannel() {
+// lockGuild serializes joining and leaving in one guild without blocking
+// other guilds, and returns the unlock function
+func (vb *VoiceBot) lockGuild(guildID string) func() {
 	vb.mu.Lock()
-	defer vb.mu.Unlock()
+	lock, exists := vb.guildLocks[guildID]
+	if !exists {
+		lock = &sync.Mutex{}
+		vb.guildLocks[guildID] = lock
+	}
+	vb.mu.Unlock()
 
-	if vb.voiceConn != nil {
-		if err := vb.voiceConn.Disconnect(); err != nil {
-			logrus.WithError(err).Debug("Error disconnecting from voice channel")
-		}
-		vb.voiceConn = nil
-		logrus.Info("Left voice channel")
+	lock.Lock()
+	return lock.Unlock
+}
+
+// takeConnectionLocked forgets the connection in a guild and returns it, or
+// nil if there is none. The caller closes it after releasing vb.mu.
+func (vb *VoiceBot) takeConnectionLocked(guildID string) *voiceConnection {
+	conn, exists := vb.connections[guildID]
+	if !exists {
+		return nil
+	}
+	delete(vb.connections, guildID)
+	return conn
+}
+
+// endSession closes a session once its receive loop has finished
+func (vb *VoiceBot) endSession(sessionID string) {
+	if err := vb.sessions.EndSession(sessionID); err != nil {
+		logrus.WithError(err).WithField("session_id", sessionID).Warn("Failed to end voice session")
+		return
 	}
 
-	// Clear simple SSRC manager state when leaving channel
-	vb.simpleSSRCManager.Clear()
+	fields := logrus.Fields{"session_id": sessionID}
+	if sess, err := vb.sessions.Snapshot(sessionID); err == nil {
+		fields["duration"] = sess.Duration().Round(time.Second)
+		fields["transcripts"] = len(sess.Transcripts)
+	}
+	logrus.WithFields(fields).Info("Ended voice session")
 }
 
 // FindUserVoiceChannel finds which voice channel a user is in
@@ -212,25 +301,68 @@ func (vb *VoiceBot) GetFollowStatus() (userID string, autoFollow bool) {
 	return vb.followUserID, vb.autoFollow
 }
 
-// GetStatus returns current bot status
+// GetStatus returns current bot status. "connections" lists the active
+// voice connections as []ConnectionStatus, ordered by guild.
 func (vb *VoiceBot) GetStatus() map[string]interface{} {
+	// Check if state exists and has a session ID (indicates ready)
+	connected := vb.discord.State != nil && vb.discord.State.SessionID != ""
+	connections := vb.Connections()
+
+	return map[string]interface{}{
+		"connected":   connected,
+		"inVoice":     len(connections) > 0,
+		"connections": connections,
+	}
+}
+
+// Connections returns the active voice connections, ordered by guild
+func (vb *VoiceBot) Connections() []ConnectionStatus {
 	vb.mu.Lock()
 	defer vb.mu.Unlock()
 
-	// Check if state exists and has a session ID (indicates ready)
-	connected := vb.discord.State != nil && vb.discord.State.SessionID != ""
+	connections := make([]ConnectionStatus, 0, len(vb.connections))
+	for _, conn := range vb.connections {
+		connections = append(connections, conn.status())
+	}
+	sort.Slice(connections, func(i, j int) bool {
+		return connections[i].GuildID < connections[j].GuildID
+	})
+	return connections
+}
+
+// Connection returns the voice connection in a guild
+func (vb *VoiceBot) Connection(guildID string) (ConnectionStatus, bool) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
 
-	status := map[string]interface{}{
-		"connected": connected,
-		"inVoice":   vb.voiceConn != nil,
+	conn, exists := vb.connections[guildID]
+	if !exists {
+		return ConnectionStatus{}, false
 	}
+	return conn.status(), true
+}
 
-	if vb.voiceConn != nil {
-		status["guildID"] = vb.voiceConn.GuildID
-		status["channelID"] = vb.voiceConn.ChannelID
+// connectionLocked returns the connection in a guild, or the only connection
+// if guildID is empty. Caller must hold vb.mu.
+func (vb *VoiceBot) connectionLocked(guildID string) (*voiceConnection, error) {
+	if guildID == "" {
+		switch len(vb.connections) {
+		case 0:
+			return nil, fmt.Errorf("not connected to a voice channel")
+		case 1:
+			for _, conn := range vb.connections {
+				return conn, nil
+			}
+		default:
+			return nil, fmt.Errorf("connected to voice channels in %d guilds; specify the guild", len(vb.connections))
+		}
 	}
 
-	return status
+	conn, exists := vb.connections[guildID]
+	if !exists {
+		return nil, fmt.Errorf("not connected to a voice channel in guild %s", guildID)
+	}
+	return conn, nil
 }
 
 // Event handlers
@@ -246,6 +378,9 @@ func (vb *VoiceBot) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceS
 	// Handle bot's own voice state updates
 	if vsu.UserID == s.State.User.ID {
 		logrus.WithField("channel_id", vsu.ChannelID).Debug("Bot voice state updated")
+		if vsu.ChannelID == "" && vsu.BeforeUpdate != nil {
+			vb.handleVoiceDisconnect(vsu.GuildID, vsu.BeforeUpdate.ChannelID)
+		}
 		return
 	}
 
@@ -258,8 +393,11 @@ func (vb *VoiceBot) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceS
 	if followUserID != "" && vsu.UserID == followUserID && autoFollow {
 		if vsu.ChannelID == "" {
 			// User left voice channel
-			logrus.WithField("user_id", followUserID).Info("Followed user left voice, leaving channel")
-			vb.LeaveChannel()
+			logrus.WithFields(logrus.Fields{
+				"user_id":  followUserID,
+				"guild_id": vsu.GuildID,
+			}).Info("Followed user left voice, leaving channel")
+			vb.LeaveChannel(vsu.GuildID)
 		} else {
 			// User joined or moved to a new channel
 			logrus.WithFields(logrus.Fields{
@@ -269,11 +407,8 @@ func (vb *VoiceBot) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceS
 			}).Info("Followed user changed voice channel, following")
 
 			// Only join if we're not already in that channel
-			vb.mu.Lock()
-			currentConn := vb.voiceConn
-			vb.mu.Unlock()
-
-			if currentConn == nil || currentConn.ChannelID != vsu.ChannelID {
+			current, exists := vb.Connection(vsu.GuildID)
+			if !exists || current.ChannelID != vsu.ChannelID {
 				if err := vb.JoinChannel(vsu.GuildID, vsu.ChannelID); err != nil {
 					logrus.WithError(err).Error("Failed to follow user to new channel")
 				}
@@ -282,6 +417,51 @@ func (vb *VoiceBot) voiceStateUpdate(s *discordgo.Session, vsu *discordgo.VoiceS
 	}
 }
 
+// handleVoiceDisconnect tears down the connection when Discord drops the bot
+// from the channel it is recording (kicked, channel deleted, network loss)
+func (vb *VoiceBot) handleVoiceDisconnect(guildID, channelID string) {
+	unlock := vb.lockGuild(guildID)
+	defer unlock()
+
+	// Ignore the echo of our own leave or of a previous channel during a move
+	vb.mu.Lock()
+	conn, exists := vb.connections[guildID]
+	vb.mu.Unlock()
+	if !exists || conn.channelID != channelID {
+		return
+	}
+
+	// Handlers run concurrently, so the echo of a leave can arrive after the
+	// bot has rejoined. Discord's voice state is current; trust it over the event.
+	if vb.inVoiceChannel(guildID) {
+		logrus.WithField("guild_id", guildID).Debug("Ignoring stale voice disconnect, bot has rejoined")
+		return
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"guild_id":   guildID,
+		"channel_id": channelID,
+		"session_id": conn.sessionID,
+	}).Warn("Bot was disconnected from voice channel, ending session")
+
+	vb.mu.Lock()
+	if vb.connections[guildID] == conn {
+		delete(vb.connections, guildID)
+	}
+	vb.mu.Unlock()
+	conn.close()
+}
+
+// inVoiceChannel reports whether Discord's state has the bot in a voice channel of a guild
+func (vb *VoiceBot) inVoiceChannel(guildID string) bool {
+	state := vb.discord.State
+	if state == nil || state.User == nil {
+		return false
+	}
+	voiceState, err := state.VoiceState(guildID, state.User.ID)
+	return err == nil && voiceState.ChannelID != ""
+}
+
 func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *discordgo.VoiceSpeakingUpdate) {
 	// CRITICAL: This is the ONLY deterministic way to map SSRCs to users
 	logrus.WithFields(logrus.Fields{
@@ -298,10 +478,19 @@ func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *disc
 
 	ssrc := uint32(vsu.SSRC)
 
+	// Speaking updates of a connection that has since been replaced are stale
+	vb.mu.Lock()
+	conn, exists := vb.connections[vc.GuildID]
+	vb.mu.Unlock()
+	if !exists || conn.vc != vc {
+		logrus.WithField("guild_id", vc.GuildID).Debug("Ignoring speaking update of a closed voice connection")
+		return
+	}
+
 	// Try to get member from guild state first
 	var username, nickname string
-	if vb.voiceConn != nil && vb.voiceConn.GuildID != "" {
-		member, err := vb.discord.State.Member(vb.voiceConn.GuildID, vsu.UserID)
+	if vc.GuildID != "" {
+		member, err := vb.discord.State.Member(vc.GuildID, vsu.UserID)
 		if err == nil && member != nil && member.User != nil {
 			username = member.User.Username
 			nickname = member.Nick
@@ -312,10 +501,10 @@ func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *disc
 			// If not in state, try fetching from API
 			logrus.WithFields(logrus.Fields{
 				"user_id":  vsu.UserID,
-				"guild_id": vb.voiceConn.GuildID,
+				"guild_id": vc.GuildID,
 			}).Debug("Member not in state, fetching from API")
 
-			member, err = vb.discord.GuildMember(vb.voiceConn.GuildID, vsu.UserID)
+			member, err = vb.discord.GuildMember(vc.GuildID, vsu.UserID)
 			if err == nil && member != nil && member.User != nil {
 				username = member.User.Username
 				nickname = member.Nick
@@ -343,7 +532,7 @@ func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *disc
 	}
 
 	// Register the mapping with the SIMPLE SSRC manager (deterministic approach)
-	vb.simpleSSRCManager.MapSSRC(ssrc, vsu.UserID, username, nickname)
+	conn.ssrc.MapSSRC(ssrc, vsu.UserID, username, nickname)
 
 	action := "stopped"
 	if vsu.Speaking {
@@ -351,9 +540,10 @@ func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *disc
 	}
 
 	// Get current statistics
-	stats := vb.simpleSSRCManager.GetStatistics()
+	stats := conn.ssrc.GetStatistics()
 
 	logrus.WithFields(logrus.Fields{
+		"guild_id":       vc.GuildID,
 		"ssrc":           ssrc,
 		"user_id":        vsu.UserID,
 		"username":       username,
@@ -363,17 +553,3 @@ func (vb *VoiceBot) voiceSpeakingUpdate(vc *discordgo.VoiceConnection, vsu *disc
 		"method":         "deterministic",
 	}).Info(fmt.Sprintf("✅ User %s speaking - SSRC mapped via VoiceSpeakingUpdate (DETERMINISTIC)", action))
 }
-
-// GetUserBySSRC returns user information for a given SSRC (implements UserResolver)
-// DETERMINISTIC APPROACH: Only returns exact mappings from VoiceSpeakingUpdate events
-func (vb *VoiceBot) GetUserBySSRC(ssrc uint32) (userID, username, nickname string) {
-	// Use the simple deterministic SSRC manager
-	return vb.simpleSSRCManager.GetUserBySSRC(ssrc)
-}
-
-// RegisterAudioPacket is called by the audio processor for each packet
-// DETERMINISTIC APPROACH: We don't analyze packets to guess mappings
-func (vb *VoiceBot) RegisterAudioPacket(ssrc uint32, packetSize int) {
-	// No-op in deterministic approach - we only map via VoiceSpeakingUpdate events
-	vb.simpleSSRCManager.RegisterAudioPacket(ssrc, packetSize)
-}
@@ -1,6 +1,7 @@
 package bot
 
 import (
+	"context"
 	"fmt"
 	"sync"
 	"testing"
@@ -9,7 +10,9 @@ import (
 	"github.com/fankserver/discord-voice-mcp/internal/audio"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/tts"
 	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
 )
 
 func TestNewBot(t *testing.T) {
@@ -93,47 +96,41 @@ func TestBotStatus(t *testing.T) {
 }
 
 func TestGetUserBySSRC(t *testing.T) {
-	// Create a bot instance
-	sessionManager := session.NewManager()
-	trans := &transcriber.MockTranscriber{}
-	audioProcessor := audio.NewProcessor(trans)
-
-	bot, err := New("test-token", sessionManager, audioProcessor)
-	assert.NoError(t, err)
-	assert.NotNil(t, bot)
+	// Each voice connection resolves the SSRCs of its own channel
+	conn := newVoiceConnection("guild1", "channel1", nil)
 
 	// Test with no mappings - should return SSRC as fallback with Unknown prefix
 	ssrc := uint32(12345)
-	userID, username, nickname := bot.GetUserBySSRC(ssrc)
+	userID, username, nickname := conn.GetUserBySSRC(ssrc)
 	assert.Equal(t, "12345", userID)
 	assert.Equal(t, "Unknown-12345", username)
 	assert.Equal(t, "Unknown-12345", nickname)
 
-	// Add a user mapping via the simple SSRC manager
-	bot.simpleSSRCManager.MapSSRC(ssrc, "user-123", "TestUser", "TestNick")
+	// Add a user mapping via the connection's SSRC manager
+	conn.ssrc.MapSSRC(ssrc, "user-123", "TestUser", "TestNick")
 
 	// Test with existing mapping
-	userID, username, nickname = bot.GetUserBySSRC(ssrc)
+	userID, username, nickname = conn.GetUserBySSRC(ssrc)
 	assert.Equal(t, "user-123", userID)
 	assert.Equal(t, "TestUser", username)
 	assert.Equal(t, "TestNick", nickname)
 
 	// Test with different SSRC - should return fallback with Unknown prefix
 	differentSSRC := uint32(67890)
-	userID, username, nickname = bot.GetUserBySSRC(differentSSRC)
+	userID, username, nickname = conn.GetUserBySSRC(differentSSRC)
 	assert.Equal(t, "67890", userID)
 	assert.Equal(t, "Unknown-67890", username)
 	assert.Equal(t, "Unknown-67890", nickname)
+
+	// Another guild's connection does not see the mapping
+	other := newVoiceConnection("guild2", "channel2", nil)
+	userID, _, _ = other.GetUserBySSRC(ssrc)
+	assert.Equal(t, "12345", userID)
 }
 
 func TestSSRCMappingConcurrency(t *testing.T) {
 	// Test concurrent access to SSRC mappings
-	sessionManager := session.NewManager()
-	trans := &transcriber.MockTranscriber{}
-	audioProcessor := audio.NewProcessor(trans)
-
-	bot, err := New("test-token", sessionManager, audioProcessor)
-	assert.NoError(t, err)
+	conn := newVoiceConnection("guild1", "channel1", nil)
 
 	var wg sync.WaitGroup
 	numGoroutines := 10
@@ -145,8 +142,8 @@ func TestSSRCMappingConcurrency(t *testing.T) {
 			defer wg.Done()
 			ssrc := uint32(1000 + id)
 
-			// Simulate adding user mapping via simple SSRC manager
-			bot.simpleSSRCManager.MapSSRC(ssrc,
+			// Simulate adding user mapping via the connection's SSRC manager
+			conn.ssrc.MapSSRC(ssrc,
 				fmt.Sprintf("user-%d", id),
 				fmt.Sprintf("User%d", id),
 				fmt.Sprintf("Nick%d", id))
@@ -161,7 +158,7 @@ func TestSSRCMappingConcurrency(t *testing.T) {
 			ssrc := uint32(1000 + id)
 
 			// Read user info
-			userID, _, _ := bot.GetUserBySSRC(ssrc)
+			userID, _, _ := conn.GetUserBySSRC(ssrc)
 			// May get fallback or actual value depending on timing
 			assert.NotEmpty(t, userID)
 		}(i)
@@ -170,7 +167,7 @@ func TestSSRCMappingConcurrency(t *testing.T) {
 	wg.Wait()
 
 	// Verify all mappings were added via statistics
-	stats := bot.simpleSSRCManager.GetStatistics()
+	stats := conn.ssrc.GetStatistics()
 	assert.Equal(t, numGoroutines, stats["exact_mappings"])
 }
 
@@ -183,21 +180,92 @@ func TestLeaveChannelClearsSSRCMappings(t *testing.T) {
 	bot, err := New("test-token", sessionManager, audioProcessor)
 	assert.NoError(t, err)
 
-	// Add some SSRC mappings via the simple manager
-	bot.simpleSSRCManager.MapSSRC(1001, "user1", "User1", "Nick1")
-	bot.simpleSSRCManager.MapSSRC(1002, "user2", "User2", "Nick2")
-	bot.simpleSSRCManager.MapSSRC(1003, "user3", "User3", "Nick3")
+	// Add some SSRC mappings to a connection
+	conn := newVoiceConnection("guild1", "channel1", nil)
+	bot.connections["guild1"] = conn
+	conn.ssrc.MapSSRC(1001, "user1", "User1", "Nick1")
+	conn.ssrc.MapSSRC(1002, "user2", "User2", "Nick2")
+	conn.ssrc.MapSSRC(1003, "user3", "User3", "Nick3")
 
 	// Verify mappings exist
-	stats := bot.simpleSSRCManager.GetStatistics()
+	stats := conn.ssrc.GetStatistics()
 	assert.Equal(t, 3, stats["exact_mappings"])
 
 	// Leave channel (should clear mappings)
-	bot.LeaveChannel()
+	assert.Equal(t, 1, bot.LeaveChannel("guild1"))
 
 	// Verify mappings were cleared
-	stats = bot.simpleSSRCManager.GetStatistics()
+	stats = conn.ssrc.GetStatistics()
 	assert.Equal(t, 0, stats["exact_mappings"])
+	assert.Empty(t, bot.Connections())
+}
+
+func TestMultipleGuildConnections(t *testing.T) {
+	sessionManager := session.NewManager()
+	bot, err := New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	require.NoError(t, err)
+
+	for _, guild := range []string{"guild2", "guild1", "guild3"} {
+		conn := newVoiceConnection(guild, guild+"-voice", nil)
+		conn.sessionID = sessionManager.CreateSession(guild, guild+"-voice")
+		bot.connections[guild] = conn
+	}
+
+	connections := bot.Connections()
+	require.Len(t, connections, 3)
+	assert.Equal(t, "guild1", connections[0].GuildID, "ordered by guild")
+	assert.Equal(t, "guild1-voice", connections[0].ChannelID)
+	assert.NotEmpty(t, connections[0].SessionID)
+
+	status := bot.GetStatus()
+	assert.Equal(t, true, status["inVoice"])
+	assert.Equal(t, connections, status["connections"])
+
+	// Without a guild, only an unambiguous connection is picked
+	_, err = bot.PlaybackStatus("")
+	assert.ErrorContains(t, err, "specify the guild")
+	_, err = bot.PlaybackStatus("guild4")
+	assert.ErrorContains(t, err, "not connected")
+
+	// A disconnect in one guild leaves the others recording
+	bot.handleVoiceDisconnect("guild2", "other-channel")
+	assert.Len(t, bot.Connections(), 3, "not the channel the bot is in")
+	bot.handleVoiceDisconnect("guild2", "guild2-voice")
+	_, exists := bot.Connection("guild2")
+	assert.False(t, exists)
+	assert.Len(t, bot.Connections(), 2)
+
+	assert.Equal(t, 0, bot.LeaveChannel("guild2"))
+	assert.Equal(t, 2, bot.LeaveChannel(""))
+	assert.Empty(t, bot.Connections())
+}
+
+func TestStaleVoiceDisconnectAfterRejoin(t *testing.T) {
+	sessionManager := session.NewManager()
+	bot, err := New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	require.NoError(t, err)
+
+	conn := newVoiceConnection("guild1", "voice1", nil)
+	conn.sessionID = sessionManager.CreateSession("guild1", "voice1")
+	bot.connections["guild1"] = conn
+
+	// Discord's state has the bot back in the channel: the leave echo is stale
+	bot.discord.State.User = &discordgo.User{ID: "bot-user"}
+	require.NoError(t, bot.discord.State.GuildAdd(&discordgo.Guild{
+		ID:          "guild1",
+		VoiceStates: []*discordgo.VoiceState{{UserID: "bot-user", GuildID: "guild1", ChannelID: "voice1"}},
+	}))
+	bot.handleVoiceDisconnect("guild1", "voice1")
+	_, exists := bot.Connection("guild1")
+	assert.True(t, exists, "rejoined connection is kept")
+
+	// Once the state has the bot out of the channel, the disconnect is real
+	guild, err := bot.discord.State.Guild("guild1")
+	require.NoError(t, err)
+	guild.VoiceStates = nil
+	bot.handleVoiceDisconnect("guild1", "voice1")
+	_, exists = bot.Connection("guild1")
+	assert.False(t, exists)
 }
 
 func TestFindUserVoiceChannel(t *testing.T) {
@@ -297,3 +365,49 @@ func TestJoinUserChannel(t *testing.T) {
 	// Note: We can't test the actual join without a valid Discord connection
 	// as it would require mocking the Discord API
 }
+
+func TestSpeakRequiresSynthesizerAndVoice(t *testing.T) {
+	bot, err := New("dummy_token", session.NewManager(), audio.NewProcessor(&transcriber.MockTranscriber{}))
+	if err != nil {
+		t.Fatalf("Failed to create bot: %v", err)
+	}
+
+	_, err = bot.Speak(context.Background(), "", "hello")
+	assert.ErrorContains(t, err, "not configured")
+
+	bot.SetSynthesizer(&tts.ToneSynthesizer{})
+	_, err = bot.Speak(context.Background(), "", "hello")
+	assert.ErrorContains(t, err, "not connected")
+}
+
+func TestPromptVocabulary(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+
+	bot, err := New("test-token", sessionManager, audioProcessor)
+	assert.NoError(t, err)
+
+	bot.discord.State.User = &discordgo.User{ID: "bot-user"}
+	assert.NoError(t, bot.discord.State.GuildAdd(&discordgo.Guild{
+		ID: "guild1",
+		VoiceStates: []*discordgo.VoiceState{
+			{UserID: "bot-user", ChannelID: "voice"},
+			{UserID: "user1", ChannelID: "voice"},
+			{UserID: "user2", ChannelID: "voice", Member: &discordgo.Member{User: &discordgo.User{ID: "user2", Username: "bob", GlobalName: "Bobby"}}},
+			{UserID: "user3", ChannelID: "elsewhere"},
+		},
+		Members: []*discordgo.Member{
+			{User: &discordgo.User{ID: "user1", Username: "alice"}, Nick: "Ali"},
+		},
+	}))
+
+	assert.Equal(t, []string{"Ali", "Bobby"}, bot.ChannelMemberNames("guild1", "voice"))
+
+	_, err = bot.Vocabulary().Add("guild1", []string{"Valheim", "ali"})
+	assert.NoError(t, err)
+
+	sessionID := sessionManager.CreateSession("guild1", "voice")
+	assert.Equal(t, []string{"Valheim", "ali", "Bobby"}, bot.PromptVocabulary(sessionID))
+	assert.Empty(t, bot.PromptVocabulary("missing-session"))
+}
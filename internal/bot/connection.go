@@ -0,0 +1,88 @@
+package bot
+
+import (
+	"context"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/sirupsen/logrus"
+)
+
+// voiceConnection is the bot's voice connection in one guild, with the session
+// it records and the SSRC mapping of the speakers in its channel
+type voiceConnection struct {
+	guildID     string
+	channelID   string
+	vc          *discordgo.VoiceConnection
+	ssrc        *SimpleSSRCManager // Deterministic SSRC mapping for this channel
+	sessionID   string             // Session recorded on this connection
+	stopReceive context.CancelFunc // Stops the receive loop
+	receiveDone chan struct{}      // Closed once the receive loop has ended its session
+	player      *audio.Player      // Plays speech and audio in this channel
+}
+
+// newVoiceConnection creates the state of a connection to a guild's voice channel
+func newVoiceConnection(guildID, channelID string, vc *discordgo.VoiceConnection) *voiceConnection {
+	ssrc := NewSimpleSSRCManager()
+	ssrc.SetChannel(guildID, channelID)
+	return &voiceConnection{
+		guildID:   guildID,
+		channelID: channelID,
+		vc:        vc,
+		ssrc:      ssrc,
+	}
+}
+
+// close stops the receive loop, waits for it to flush and end the session,
+// and disconnects from the channel
+func (c *voiceConnection) close() {
+	if c.stopReceive != nil {
+		c.stopReceive()
+		select {
+		case <-c.receiveDone:
+		case <-time.After(receiveShutdownTimeout):
+			logrus.WithField("session_id", c.sessionID).Warn("Timed out waiting for voice receive to stop")
+		}
+	}
+
+	if c.player != nil {
+		c.player.Close()
+	}
+
+	if c.vc != nil {
+		if err := c.vc.Disconnect(); err != nil {
+			logrus.WithError(err).WithField("guild_id", c.guildID).Debug("Error disconnecting from voice channel")
+		}
+	}
+
+	c.ssrc.Clear()
+}
+
+// status describes the connection
+func (c *voiceConnection) status() ConnectionStatus {
+	return ConnectionStatus{
+		GuildID:   c.guildID,
+		ChannelID: c.channelID,
+		SessionID: c.sessionID,
+	}
+}
+
+// GetUserBySSRC returns user information for a given SSRC (implements UserResolver)
+// DETERMINISTIC APPROACH: Only returns exact mappings from VoiceSpeakingUpdate events
+func (c *voiceConnection) GetUserBySSRC(ssrc uint32) (userID, username, nickname string) {
+	return c.ssrc.GetUserBySSRC(ssrc)
+}
+
+// RegisterAudioPacket is called by the audio processor for each packet
+// DETERMINISTIC APPROACH: We don't analyze packets to guess mappings
+func (c *voiceConnection) RegisterAudioPacket(ssrc uint32, packetSize int) {
+	c.ssrc.RegisterAudioPacket(ssrc, packetSize)
+}
+
+// ConnectionStatus describes one of the bot's voice connections
+type ConnectionStatus struct {
+	GuildID   string
+	ChannelID string
+	SessionID string // Session recorded on the connection
+}
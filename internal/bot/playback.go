@@ -0,0 +1,231 @@
+package bot
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"time"
+
+	"github.com/bwmarrin/discordgo"
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/pkg/tts"
+	"github.com/sirupsen/logrus"
+)
+
+// SetSynthesizer configures the text-to-speech backend used by Speak
+func (vb *VoiceBot) SetSynthesizer(synthesizer tts.Synthesizer) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+	vb.synthesizer = synthesizer
+}
+
+// Speak synthesizes text and plays it in a guild's voice channel (the only
+// one if guildID is empty), ahead of any queued audio. Returns the length of
+// the played speech.
+func (vb *VoiceBot) Speak(ctx context.Context, guildID, text string) (time.Duration, error) {
+	vb.mu.Lock()
+	synthesizer := vb.synthesizer
+	vb.mu.Unlock()
+
+	if synthesizer == nil {
+		return 0, fmt.Errorf("text-to-speech is not configured")
+	}
+	if _, err := vb.currentPlayer(guildID); err != nil {
+		return 0, err
+	}
+
+	speech, err := synthesizer.Synthesize(ctx, text)
+	if err != nil {
+		return 0, fmt.Errorf("error synthesizing speech: %w", err)
+	}
+	frames, err := audio.EncodeOpusFrames(speech.Samples, speech.SampleRate, speech.Channels)
+	if err != nil {
+		return 0, err
+	}
+
+	// The connection may have changed while synthesizing
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return 0, err
+	}
+
+	track := audio.NewFramesTrack("Speech", frames)
+	if err := player.Interrupt(track); err != nil {
+		return 0, err
+	}
+	if err := track.Wait(ctx); err != nil {
+		track.Cancel()
+		return 0, fmt.Errorf("playback interrupted: %w", err)
+	}
+	return speech.Duration(), nil
+}
+
+// PlayAudio queues a local audio file or http(s) URL, decoded with ffmpeg, in
+// a guild's voice channel. Returns the queued track and its queue position (0 = plays next).
+func (vb *VoiceBot) PlayAudio(guildID, source, title string) (audio.TrackInfo, int, error) {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return audio.TrackInfo{}, 0, err
+	}
+
+	input, err := resolveAudioSource(source)
+	if err != nil {
+		return audio.TrackInfo{}, 0, err
+	}
+
+	ffmpegPath, err := exec.LookPath("ffmpeg")
+	if err != nil {
+		return audio.TrackInfo{}, 0, fmt.Errorf("ffmpeg executable not found in PATH: %w", err)
+	}
+
+	if title == "" {
+		title = filepath.Base(input)
+	}
+	track := audio.NewTrack(title, input, func(ctx context.Context) (audio.FrameSource, error) {
+		return audio.OpenFFmpegSource(ctx, ffmpegPath, input)
+	})
+
+	position, err := player.Enqueue(track)
+	if err != nil {
+		return audio.TrackInfo{}, 0, err
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"track_id": track.Info().ID,
+		"source":   input,
+		"position": position,
+	}).Info("Queued audio for playback")
+
+	return track.Info(), position, nil
+}
+
+// PausePlayback pauses the current track
+func (vb *VoiceBot) PausePlayback(guildID string) error {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return err
+	}
+	player.Pause()
+	return nil
+}
+
+// ResumePlayback resumes a paused track
+func (vb *VoiceBot) ResumePlayback(guildID string) error {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return err
+	}
+	player.Resume()
+	return nil
+}
+
+// SkipTrack ends the current track. Returns false if nothing was playing.
+func (vb *VoiceBot) SkipTrack(guildID string) (bool, error) {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return false, err
+	}
+	return player.Skip(), nil
+}
+
+// StopPlayback ends the current track and clears the queue
+func (vb *VoiceBot) StopPlayback(guildID string) error {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return err
+	}
+	player.Stop()
+	return nil
+}
+
+// PlaybackStatus returns what is playing and queued in a guild's voice channel
+func (vb *VoiceBot) PlaybackStatus(guildID string) (audio.PlaybackStatus, error) {
+	player, err := vb.currentPlayer(guildID)
+	if err != nil {
+		return audio.PlaybackStatus{}, err
+	}
+	return player.Status(), nil
+}
+
+// currentPlayer returns the player of the voice connection in a guild, or of
+// the only connection if guildID is empty
+func (vb *VoiceBot) currentPlayer(guildID string) (*audio.Player, error) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+
+	conn, err := vb.connectionLocked(guildID)
+	if err != nil {
+		return nil, err
+	}
+	return conn.player, nil
+}
+
+// newPlayer creates the player for a voice connection. The bot stays
+// self-muted except while the player has something to play.
+func (vb *VoiceBot) newPlayer(vc *discordgo.VoiceConnection) *audio.Player {
+	onStart := func() {
+		if err := vb.setSelfMute(vc, false); err != nil {
+			logrus.WithError(err).Debug("Error unmuting for playback")
+		}
+		if err := vc.Speaking(true); err != nil {
+			logrus.WithError(err).Debug("Error setting speaking flag")
+		}
+	}
+	onIdle := func() {
+		if err := vc.Speaking(false); err != nil {
+			logrus.WithError(err).Debug("Error unsetting speaking flag")
+		}
+		if err := vb.setSelfMute(vc, true); err != nil {
+			logrus.WithError(err).Debug("Error muting after playback")
+		}
+	}
+	return audio.NewPlayer(vc.OpusSend, onStart, onIdle)
+}
+
+// setSelfMute updates the bot's mute state on the channel it is connected to.
+// Does nothing once vc is no longer its guild's connection, so a late call can't rejoin.
+// The voice state update is sent without vb.mu held, as it blocks on the gateway.
+func (vb *VoiceBot) setSelfMute(vc *discordgo.VoiceConnection, mute bool) error {
+	vb.mu.Lock()
+	conn, exists := vb.connections[vc.GuildID]
+	current := exists && conn.vc == vc
+	vb.mu.Unlock()
+
+	if !current {
+		return fmt.Errorf("voice connection closed")
+	}
+	if err := vb.discord.ChannelVoiceJoinManual(vc.GuildID, vc.ChannelID, mute, false); err != nil {
+		return fmt.Errorf("error updating mute state: %w", err)
+	}
+	return nil
+}
+
+// resolveAudioSource validates a play_audio source: an http(s) URL or an existing local file
+func resolveAudioSource(source string) (string, error) {
+	if source == "" {
+		return "", fmt.Errorf("audio source is required")
+	}
+
+	if u, err := url.Parse(source); err == nil && u.Scheme != "" && len(u.Scheme) > 1 {
+		if u.Scheme != "http" && u.Scheme != "https" {
+			return "", fmt.Errorf("unsupported URL scheme %q (use http or https)", u.Scheme)
+		}
+		return source, nil
+	}
+
+	path, err := filepath.Abs(source)
+	if err != nil {
+		return "", fmt.Errorf("invalid audio path: %w", err)
+	}
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", fmt.Errorf("audio file not accessible: %w", err)
+	}
+	if info.IsDir() {
+		return "", fmt.Errorf("audio source is a directory: %s", path)
+	}
+	return path, nil
+}
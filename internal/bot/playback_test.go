@@ -0,0 +1,44 @@
+package bot
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestResolveAudioSource(t *testing.T) {
+	dir := t.TempDir()
+	file := filepath.Join(dir, "clip.wav")
+	require.NoError(t, os.WriteFile(file, []byte("RIFF"), 0600))
+
+	resolved, err := resolveAudioSource(file)
+	require.NoError(t, err)
+	assert.Equal(t, file, resolved)
+
+	resolved, err = resolveAudioSource("https://example.com/clip.mp3")
+	require.NoError(t, err)
+	assert.Equal(t, "https://example.com/clip.mp3", resolved)
+
+	for _, source := range []string{"", dir, filepath.Join(dir, "missing.mp3"), "file:///etc/passwd", "ftp://example.com/a.mp3"} {
+		_, err := resolveAudioSource(source)
+		assert.Error(t, err, source)
+	}
+}
+
+func TestPlaybackRequiresVoiceConnection(t *testing.T) {
+	bot, err := New("dummy_token", session.NewManager(), audio.NewProcessor(&transcriber.MockTranscriber{}))
+	require.NoError(t, err)
+
+	_, _, err = bot.PlayAudio("", "https://example.com/clip.mp3", "")
+	assert.ErrorContains(t, err, "not connected")
+	assert.Error(t, bot.PausePlayback(""))
+	assert.Error(t, bot.StopPlayback(""))
+	_, err = bot.PlaybackStatus("")
+	assert.Error(t, err)
+}
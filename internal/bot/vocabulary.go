@@ -0,0 +1,80 @@
+package bot
+
+import (
+	"github.com/bwmarrin/discordgo"
+	"github.com/fankserver/discord-voice-mcp/internal/vocabulary"
+)
+
+// SetVocabularyStore replaces the per-guild vocabulary store, e.g. with a persistent one
+func (vb *VoiceBot) SetVocabularyStore(store *vocabulary.Store) {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+	vb.vocabulary = store
+}
+
+// Vocabulary returns the per-guild vocabulary store
+func (vb *VoiceBot) Vocabulary() *vocabulary.Store {
+	vb.mu.Lock()
+	defer vb.mu.Unlock()
+	return vb.vocabulary
+}
+
+// ChannelMemberNames returns the display names of everyone in a voice channel,
+// other than the bot itself, as known from the Discord state
+func (vb *VoiceBot) ChannelMemberNames(guildID, channelID string) []string {
+	state := vb.discord.State
+	if state == nil {
+		return nil
+	}
+	guild, err := state.Guild(guildID)
+	if err != nil {
+		return nil
+	}
+
+	var botID string
+	if state.User != nil {
+		botID = state.User.ID
+	}
+
+	var names []string
+	for _, vs := range guild.VoiceStates {
+		if vs == nil || vs.ChannelID != channelID || vs.UserID == botID {
+			continue
+		}
+		member, err := state.Member(guildID, vs.UserID)
+		if err != nil || member == nil {
+			member = vs.Member
+		}
+		if name := memberDisplayName(member); name != "" {
+			names = append(names, name)
+		}
+	}
+	return names
+}
+
+// PromptVocabulary returns the terms to prompt the transcriber with for a
+// session: the guild's vocabulary followed by the names of current channel members
+func (vb *VoiceBot) PromptVocabulary(sessionID string) []string {
+	sess, err := vb.sessions.GetSession(sessionID)
+	if err != nil {
+		return nil
+	}
+	return vocabulary.Merge(
+		vb.Vocabulary().List(sess.GuildID),
+		vb.ChannelMemberNames(sess.GuildID, sess.ChannelID),
+	)
+}
+
+// memberDisplayName returns a member's nickname, global name or username
+func memberDisplayName(member *discordgo.Member) string {
+	if member == nil {
+		return ""
+	}
+	if member.Nick != "" {
+		return member.Nick
+	}
+	if member.User == nil {
+		return ""
+	}
+	return member.User.DisplayName()
+}
@@ -16,6 +16,11 @@ const (
 	EventTranscriptionProgress  EventType = "transcription.progress"
 	EventTranscriptionCompleted EventType = "transcription.completed"
 	EventTranscriptionFailed    EventType = "transcription.failed"
+	EventTranscriptTranslated   EventType = "transcript.translated"
+
+	// Re-transcription events
+	EventRetranscriptionProgress  EventType = "retranscription.progress"
+	EventRetranscriptionCompleted EventType = "retranscription.completed"
 
 	// Audio events
 	EventAudioBuffering EventType = "audio.buffering"
@@ -67,6 +72,22 @@ type TranscriptionCompletedData struct {
 	AudioDuration time.Duration
 }
 
+// TranscriptTranslatedData contains data for transcript translated events
+type TranscriptTranslatedData struct {
+	Sequence    int64 // Sequence of the translated transcript
+	Translation string
+	Language    string
+}
+
+// RetranscriptionData contains data for re-transcription progress and completion events
+type RetranscriptionData struct {
+	JobID     string
+	Completed int // Segments transcribed so far, including failures
+	Failed    int
+	Total     int
+	Error     string // Why the job failed, completion events only
+}
+
 // AudioBufferingData contains data for audio buffering events
 type AudioBufferingData struct {
 	UserID         string
@@ -0,0 +1,160 @@
+// Package filter cleans up transcriber output before it is stored: it drops
+// hallucinated and low-confidence segments and strips noise from the rest.
+package filter
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/sirupsen/logrus"
+)
+
+// Segment is the transcriber output a filter sees
+type Segment struct {
+	Text       string
+	Confidence float32 // 0 means the transcriber didn't report one
+	Language   string
+}
+
+// Filter transforms the text of a segment. Returning an empty string drops it.
+type Filter interface {
+	Name() string
+	Apply(segment Segment) string
+}
+
+// Metrics counts what a filter did
+type Metrics struct {
+	Processed int64 // Segments the filter saw
+	Modified  int64 // Segments whose text it changed
+	Dropped   int64 // Segments it dropped
+}
+
+// Chain runs filters in order until one drops the segment
+type Chain struct {
+	filters []Filter
+	metrics map[string]*Metrics
+	mu      sync.Mutex
+}
+
+// NewChain creates a chain running filters in the given order
+func NewChain(filters ...Filter) *Chain {
+	metrics := make(map[string]*Metrics, len(filters))
+	for _, f := range filters {
+		metrics[f.Name()] = &Metrics{}
+	}
+	return &Chain{filters: filters, metrics: metrics}
+}
+
+// Apply filters a segment's text. It returns false if the segment was dropped.
+func (c *Chain) Apply(segment Segment) (string, bool) {
+	if c == nil {
+		return segment.Text, segment.Text != ""
+	}
+
+	for _, f := range c.filters {
+		text := f.Apply(segment)
+
+		c.mu.Lock()
+		m := c.metrics[f.Name()]
+		m.Processed++
+		switch {
+		case text == "":
+			m.Dropped++
+		case text != segment.Text:
+			m.Modified++
+		}
+		c.mu.Unlock()
+
+		if text == "" {
+			logrus.WithFields(logrus.Fields{
+				"filter": f.Name(),
+				"text":   segment.Text,
+			}).Debug("Transcript dropped by filter")
+			return "", false
+		}
+		segment.Text = text
+	}
+	return segment.Text, true
+}
+
+// Names returns the filters in the order they run
+func (c *Chain) Names() []string {
+	names := make([]string, len(c.filters))
+	for i, f := range c.filters {
+		names[i] = f.Name()
+	}
+	return names
+}
+
+// Metrics returns a snapshot of each filter's counters
+func (c *Chain) Metrics() map[string]Metrics {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	snapshot := make(map[string]Metrics, len(c.metrics))
+	for name, m := range c.metrics {
+		snapshot[name] = *m
+	}
+	return snapshot
+}
+
+// DefaultFilters is the chain used when TRANSCRIPT_FILTERS is not set
+const DefaultFilters = "hallucination,noise,repetition,confidence"
+
+// NewChainFromEnv builds a chain from the environment:
+//   - TRANSCRIPT_FILTERS: comma-separated filters in order (default DefaultFilters, "none" for no filtering)
+//   - TRANSCRIPT_BLOCKLIST: extra hallucination phrases, separated by "|"
+//   - TRANSCRIPT_MIN_CONFIDENCE: confidence below which segments are dropped (default 0.3)
+//   - PROFANITY_WORDS: comma-separated words to mask (default: a small English list)
+func NewChainFromEnv() (*Chain, error) {
+	names := os.Getenv("TRANSCRIPT_FILTERS")
+	if names == "" {
+		names = DefaultFilters
+	}
+	if strings.TrimSpace(strings.ToLower(names)) == "none" {
+		return NewChain(), nil
+	}
+
+	var filters []Filter
+	for _, name := range strings.Split(names, ",") {
+		switch strings.TrimSpace(strings.ToLower(name)) {
+		case "":
+			continue
+		case "hallucination":
+			filters = append(filters, NewHallucinationFilter(splitList(os.Getenv("TRANSCRIPT_BLOCKLIST"), "|")))
+		case "noise":
+			filters = append(filters, NewNoiseTagFilter())
+		case "repetition":
+			filters = append(filters, NewRepetitionFilter(DefaultMaxRepeats))
+		case "profanity":
+			filters = append(filters, NewProfanityFilter(splitList(os.Getenv("PROFANITY_WORDS"), ",")))
+		case "confidence":
+			var minConfidence float32 = DefaultMinConfidence
+			if value := os.Getenv("TRANSCRIPT_MIN_CONFIDENCE"); value != "" {
+				parsed, err := strconv.ParseFloat(value, 32)
+				if err != nil || parsed < 0 || parsed > 1 {
+					return nil, fmt.Errorf("invalid TRANSCRIPT_MIN_CONFIDENCE %q: must be between 0 and 1", value)
+				}
+				minConfidence = float32(parsed)
+			}
+			filters = append(filters, NewConfidenceFilter(minConfidence))
+		default:
+			return nil, fmt.Errorf("unknown transcript filter %q", name)
+		}
+	}
+	return NewChain(filters...), nil
+}
+
+// splitList splits a separated list, dropping blank entries
+func splitList(value, sep string) []string {
+	var items []string
+	for _, item := range strings.Split(value, sep) {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
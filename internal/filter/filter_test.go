@@ -0,0 +1,72 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainAppliesInOrderWithMetrics(t *testing.T) {
+	chain := NewChain(NewHallucinationFilter(nil), NewNoiseTagFilter(), NewRepetitionFilter(2), NewConfidenceFilter(0.3))
+
+	text, ok := chain.Apply(Segment{Text: "[laughs] go go go go team", Confidence: 0.8})
+	assert.True(t, ok)
+	assert.Equal(t, "go go team", text)
+
+	_, ok = chain.Apply(Segment{Text: "Thanks for watching!", Confidence: 0.8})
+	assert.False(t, ok)
+
+	_, ok = chain.Apply(Segment{Text: "mumble", Confidence: 0.1})
+	assert.False(t, ok)
+
+	metrics := chain.Metrics()
+	assert.Equal(t, Metrics{Processed: 3, Dropped: 1}, metrics["hallucination"])
+	assert.Equal(t, Metrics{Processed: 2, Modified: 1}, metrics["noise"])
+	assert.Equal(t, Metrics{Processed: 2, Modified: 1}, metrics["repetition"])
+	assert.Equal(t, Metrics{Processed: 2, Dropped: 1}, metrics["confidence"])
+}
+
+func TestNilChainPassesThrough(t *testing.T) {
+	var chain *Chain
+	text, ok := chain.Apply(Segment{Text: "hello"})
+	assert.True(t, ok)
+	assert.Equal(t, "hello", text)
+
+	_, ok = chain.Apply(Segment{})
+	assert.False(t, ok)
+}
+
+func TestNewChainFromEnv(t *testing.T) {
+	t.Setenv("TRANSCRIPT_FILTERS", "")
+	t.Setenv("TRANSCRIPT_MIN_CONFIDENCE", "")
+	chain, err := NewChainFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"hallucination", "noise", "repetition", "confidence"}, chain.Names())
+
+	t.Setenv("TRANSCRIPT_FILTERS", "profanity, hallucination")
+	t.Setenv("TRANSCRIPT_BLOCKLIST", "Bis zum nächsten Mal|Tschüss*")
+	t.Setenv("PROFANITY_WORDS", "mist")
+	chain, err = NewChainFromEnv()
+	require.NoError(t, err)
+	assert.Equal(t, []string{"profanity", "hallucination"}, chain.Names())
+	text, ok := chain.Apply(Segment{Text: "So ein Mist"})
+	assert.True(t, ok)
+	assert.Equal(t, "So ein M***", text)
+	_, ok = chain.Apply(Segment{Text: "Bis zum nächsten Mal!"})
+	assert.False(t, ok)
+
+	t.Setenv("TRANSCRIPT_FILTERS", "none")
+	chain, err = NewChainFromEnv()
+	require.NoError(t, err)
+	assert.Empty(t, chain.Names())
+
+	t.Setenv("TRANSCRIPT_FILTERS", "confidence")
+	t.Setenv("TRANSCRIPT_MIN_CONFIDENCE", "2")
+	_, err = NewChainFromEnv()
+	assert.Error(t, err)
+
+	t.Setenv("TRANSCRIPT_FILTERS", "spellcheck")
+	_, err = NewChainFromEnv()
+	assert.Error(t, err)
+}
@@ -0,0 +1,238 @@
+package filter
+
+import (
+	"regexp"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// DefaultMaxRepeats is how often a word or phrase may repeat back to back
+	DefaultMaxRepeats = 3
+
+	// DefaultMinConfidence drops segments whisper itself was unsure about
+	DefaultMinConfidence = 0.3
+
+	// maxRepeatedPhraseWords bounds the phrase length checked for repetition
+	maxRepeatedPhraseWords = 8
+)
+
+// DefaultHallucinations are phrases whisper produces from silence and noise,
+// learned from video subtitles. A trailing "*" matches any text starting with the phrase.
+var DefaultHallucinations = []string{
+	"[No speech detected]",
+	"[BLANK_AUDIO]",
+	"Thanks for watching!",
+	"Thank you for watching!",
+	"Thank you so much for watching!",
+	"Thanks for watching and see you next time!",
+	"Please subscribe*",
+	"Subscribe to my channel*",
+	"Like and subscribe*",
+	"Don't forget to like and subscribe*",
+	"Subtitles by the Amara.org community",
+	"Untertitel der Amara.org-Community",
+	"Untertitel im Auftrag des ZDF*",
+	"Untertitelung des ZDF*",
+	"Vielen Dank fürs Zuschauen!",
+	"Sous-titrage ST' 501",
+	"Sous-titres réalisés par la communauté d'Amara.org",
+}
+
+// DefaultProfanity is the word list masked when PROFANITY_WORDS is not set
+var DefaultProfanity = []string{
+	"fuck", "fucking", "fucked", "fucker", "motherfucker",
+	"shit", "shitty", "bullshit",
+	"bitch", "bastard", "asshole", "cunt", "dick",
+}
+
+// HallucinationFilter drops segments that consist only of a known hallucination
+type HallucinationFilter struct {
+	exact    map[string]bool
+	prefixes []string
+}
+
+// NewHallucinationFilter blocks DefaultHallucinations plus the given phrases
+func NewHallucinationFilter(extra []string) *HallucinationFilter {
+	f := &HallucinationFilter{exact: make(map[string]bool)}
+	for _, phrase := range append(append([]string(nil), DefaultHallucinations...), extra...) {
+		if prefix, ok := strings.CutSuffix(phrase, "*"); ok {
+			if prefix = normalize(prefix); prefix != "" {
+				f.prefixes = append(f.prefixes, prefix)
+			}
+			continue
+		}
+		if phrase = normalize(phrase); phrase != "" {
+			f.exact[phrase] = true
+		}
+	}
+	return f
+}
+
+// Name implements Filter
+func (f *HallucinationFilter) Name() string { return "hallucination" }
+
+// Apply implements Filter
+func (f *HallucinationFilter) Apply(segment Segment) string {
+	text := normalize(segment.Text)
+	if f.exact[text] {
+		return ""
+	}
+	for _, prefix := range f.prefixes {
+		if text == prefix || strings.HasPrefix(text, prefix+" ") {
+			return ""
+		}
+	}
+	return segment.Text
+}
+
+// noiseTagPattern matches sound descriptions like [MUSIC], (laughs), *coughs* and ♪
+var noiseTagPattern = regexp.MustCompile(`\[[^\]]*\]|\([^)]*\)|\*[^*]+\*|[♪♫]+`)
+
+// NoiseTagFilter strips bracketed sound descriptions, dropping segments with nothing else
+type NoiseTagFilter struct{}
+
+// NewNoiseTagFilter creates a noise tag filter
+func NewNoiseTagFilter() *NoiseTagFilter {
+	return &NoiseTagFilter{}
+}
+
+// Name implements Filter
+func (f *NoiseTagFilter) Name() string { return "noise" }
+
+// Apply implements Filter
+func (f *NoiseTagFilter) Apply(segment Segment) string {
+	if !noiseTagPattern.MatchString(segment.Text) {
+		return segment.Text
+	}
+	text := strings.Join(strings.Fields(noiseTagPattern.ReplaceAllString(segment.Text, " ")), " ")
+	if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsDigit(r) }) {
+		return ""
+	}
+	return text
+}
+
+// RepetitionFilter collapses words and phrases repeated back to back more than
+// maxRepeats times, which is how whisper gets stuck in a loop
+type RepetitionFilter struct {
+	maxRepeats int
+}
+
+// NewRepetitionFilter creates a repetition filter keeping at most maxRepeats copies
+func NewRepetitionFilter(maxRepeats int) *RepetitionFilter {
+	return &RepetitionFilter{maxRepeats: max(maxRepeats, 1)}
+}
+
+// Name implements Filter
+func (f *RepetitionFilter) Name() string { return "repetition" }
+
+// Apply implements Filter
+func (f *RepetitionFilter) Apply(segment Segment) string {
+	words := strings.Fields(segment.Text)
+	keys := make([]string, len(words))
+	for i, word := range words {
+		keys[i] = normalize(word)
+	}
+
+	kept := make([]string, 0, len(words))
+	collapsed := false
+	for i := 0; i < len(words); {
+		n, count := findRepeat(keys, i, f.maxRepeats)
+		if n == 0 {
+			kept = append(kept, words[i])
+			i++
+			continue
+		}
+		kept = append(kept, words[i:i+n*f.maxRepeats]...)
+		i += n * count
+		collapsed = true
+	}
+
+	if !collapsed {
+		return segment.Text
+	}
+	return strings.Join(kept, " ")
+}
+
+// findRepeat finds the shortest phrase starting at i that repeats more than
+// maxRepeats times in a row, returning its length in words and its repeat count
+func findRepeat(keys []string, i, maxRepeats int) (n, count int) {
+	for n = 1; n <= maxRepeatedPhraseWords && i+n*(maxRepeats+1) <= len(keys); n++ {
+		count = 1
+		for i+(count+1)*n <= len(keys) && equalWords(keys[i:i+n], keys[i+count*n:i+(count+1)*n]) {
+			count++
+		}
+		if count > maxRepeats {
+			return n, count
+		}
+	}
+	return 0, 0
+}
+
+func equalWords(a, b []string) bool {
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// ProfanityFilter masks listed words, keeping their first letter
+type ProfanityFilter struct {
+	pattern *regexp.Regexp
+}
+
+// NewProfanityFilter masks the given words, or DefaultProfanity if none are given
+func NewProfanityFilter(words []string) *ProfanityFilter {
+	if len(words) == 0 {
+		words = DefaultProfanity
+	}
+	quoted := make([]string, len(words))
+	for i, word := range words {
+		quoted[i] = regexp.QuoteMeta(strings.ToLower(word))
+	}
+	return &ProfanityFilter{pattern: regexp.MustCompile(`(?i)\b(?:` + strings.Join(quoted, "|") + `)\b`)}
+}
+
+// Name implements Filter
+func (f *ProfanityFilter) Name() string { return "profanity" }
+
+// Apply implements Filter
+func (f *ProfanityFilter) Apply(segment Segment) string {
+	return f.pattern.ReplaceAllStringFunc(segment.Text, func(word string) string {
+		first, size := utf8.DecodeRuneInString(word)
+		return string(first) + strings.Repeat("*", utf8.RuneCountInString(word[size:]))
+	})
+}
+
+// ConfidenceFilter drops segments below a minimum confidence. Segments without
+// a confidence (from transcribers that don't report one) are kept.
+type ConfidenceFilter struct {
+	min float32
+}
+
+// NewConfidenceFilter creates a filter dropping segments below min
+func NewConfidenceFilter(minConfidence float32) *ConfidenceFilter {
+	return &ConfidenceFilter{min: minConfidence}
+}
+
+// Name implements Filter
+func (f *ConfidenceFilter) Name() string { return "confidence" }
+
+// Apply implements Filter
+func (f *ConfidenceFilter) Apply(segment Segment) string {
+	if segment.Confidence > 0 && segment.Confidence < f.min {
+		return ""
+	}
+	return segment.Text
+}
+
+// normalize lowercases text and reduces it to words, so punctuation and
+// spacing don't matter when comparing
+func normalize(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '\''
+	}), " ")
+}
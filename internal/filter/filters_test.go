@@ -0,0 +1,86 @@
+package filter
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHallucinationFilter(t *testing.T) {
+	f := NewHallucinationFilter([]string{"Ich bin ein Hallo*"})
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"[No speech detected]", ""},
+		{"  Thanks for watching! ", ""},
+		{"thanks for watching", ""},
+		{"Please subscribe to the channel.", ""},
+		{"Untertitel im Auftrag des ZDF für funk, 2017", ""},
+		{"Ich bin ein Hallo Welt", ""},
+		{"Thanks for watching the deploy with me", "Thanks for watching the deploy with me"},
+		{"Please subscribers are people too", "Please subscribers are people too"},
+		{"Thank you.", "Thank you."},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, f.Apply(Segment{Text: tt.input}), tt.input)
+	}
+}
+
+func TestNoiseTagFilter(t *testing.T) {
+	f := NewNoiseTagFilter()
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"Hello there", "Hello there"},
+		{"[MUSIC PLAYING] Hello (laughs) there *coughs*", "Hello there"},
+		{"♪ la la ♪", "la la"},
+		{"[BLANK_AUDIO]", ""},
+		{"(upbeat music) ...", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, f.Apply(Segment{Text: tt.input}), tt.input)
+	}
+}
+
+func TestRepetitionFilter(t *testing.T) {
+	f := NewRepetitionFilter(2)
+
+	tests := []struct {
+		input    string
+		expected string
+	}{
+		{"no no we ship it", "no no we ship it"},
+		{"no, no, no, no, no.", "no, no,"},
+		{"I think I think I think I think we should go", "I think I think we should go"},
+		{"let's go. Let's go. let's go, let's go!", "let's go. Let's go."},
+		{"a b c a b c", "a b c a b c"},
+		{"", ""},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, f.Apply(Segment{Text: tt.input}), tt.input)
+	}
+}
+
+func TestProfanityFilter(t *testing.T) {
+	f := NewProfanityFilter(nil)
+	assert.Equal(t, "Well s*** happens, F******", f.Apply(Segment{Text: "Well shit happens, FUCKING"}))
+	assert.Equal(t, "Shitake mushrooms", f.Apply(Segment{Text: "Shitake mushrooms"}))
+
+	custom := NewProfanityFilter([]string{"Mist"})
+	assert.Equal(t, "So ein M***!", custom.Apply(Segment{Text: "So ein Mist!"}))
+	assert.Equal(t, "shit", custom.Apply(Segment{Text: "shit"}))
+}
+
+func TestConfidenceFilter(t *testing.T) {
+	f := NewConfidenceFilter(0.5)
+	assert.Equal(t, "", f.Apply(Segment{Text: "mumble", Confidence: 0.2}))
+	assert.Equal(t, "clear", f.Apply(Segment{Text: "clear", Confidence: 0.9}))
+	assert.Equal(t, "unknown", f.Apply(Segment{Text: "unknown"}), "no confidence reported")
+}
@@ -0,0 +1,107 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// languagePattern accepts ISO 639 codes with optional BCP-47 subtags (en, yue, de-DE)
+var languagePattern = regexp.MustCompile(`^[a-z]{2,3}(-[a-z0-9]{2,8})*$`)
+
+type SetLanguageInput struct {
+	Language  string `json:"language"`
+	SessionID string `json:"sessionId,omitempty"`
+	UserID    string `json:"userId,omitempty"`
+}
+
+// handleSetLanguage sets the transcription language of a session or one of its speakers
+func (s *Server) handleSetLanguage(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SetLanguageInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"language":   args.Language,
+		"session_id": args.SessionID,
+		"user_id":    args.UserID,
+	}).Debug("MCP: Set language request")
+
+	language := strings.ToLower(strings.TrimSpace(args.Language))
+	if language == "default" {
+		language = ""
+	}
+	if language != "" && language != "auto" && !languagePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid language code %q", args.Language)
+	}
+
+	sessionID := args.SessionID
+	if sessionID == "" {
+		sessionID = s.activeSessionID()
+		if sessionID == "" {
+			return nil, fmt.Errorf("no single active session; pass sessionId")
+		}
+	}
+
+	if err := s.sessions.SetLanguage(sessionID, args.UserID, language); err != nil {
+		return nil, fmt.Errorf("failed to set language: %w", err)
+	}
+
+	target := "session " + sessionID
+	if args.UserID != "" {
+		target = fmt.Sprintf("user %s in session %s", args.UserID, sessionID)
+	}
+	if language == "" {
+		return textResult(fmt.Sprintf("Transcribing %s in the default language", target)), nil
+	}
+	return textResult(fmt.Sprintf("Transcribing %s as %s", target, language)), nil
+}
+
+type SetTranslationInput struct {
+	Language  string `json:"language"`
+	SessionID string `json:"sessionId,omitempty"`
+}
+
+// handleSetTranslation turns translation of new transcripts on or off for a session
+func (s *Server) handleSetTranslation(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SetTranslationInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"language":   args.Language,
+		"session_id": args.SessionID,
+	}).Debug("MCP: Set translation request")
+
+	language := strings.ToLower(strings.TrimSpace(args.Language))
+	if language == "off" {
+		language = ""
+	}
+	if language != "" && !languagePattern.MatchString(language) {
+		return nil, fmt.Errorf("invalid language code %q", args.Language)
+	}
+
+	sessionID := args.SessionID
+	if sessionID == "" {
+		sessionID = s.activeSessionID()
+		if sessionID == "" {
+			return nil, fmt.Errorf("no single active session; pass sessionId")
+		}
+	}
+
+	if err := s.sessions.SetTranslationTarget(sessionID, language); err != nil {
+		return nil, fmt.Errorf("failed to set translation: %w", err)
+	}
+
+	if language == "" {
+		return textResult(fmt.Sprintf("Translation off for session %s", sessionID)), nil
+	}
+	return textResult(fmt.Sprintf("New transcripts in session %s are translated into %s", sessionID, language)), nil
+}
+
+// activeSessionID returns the session the bot is recording if it is in
+// exactly one voice channel, otherwise ""
+func (s *Server) activeSessionID() string {
+	if connections := s.bot.Connections(); len(connections) == 1 {
+		return connections[0].SessionID
+	}
+	return ""
+}
@@ -0,0 +1,430 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// transcriptLogger names the logging notifications carrying live transcripts
+	transcriptLogger = "transcript"
+
+	// notifyTimeout bounds delivery of a single notification to a client
+	notifyTimeout = 5 * time.Second
+)
+
+// transcriptSubscription tracks what one MCP client wants to hear about
+type transcriptSubscription struct {
+	all      bool             // Every voice session, including future ones
+	sessions map[string]bool  // Explicitly subscribed voice sessions
+	excluded map[string]bool  // Voice sessions unsubscribed from while subscribed to all
+	cursors  map[string]int64 // Last sequence delivered per voice session
+
+	// Serializes transcript delivery to the client so entries arrive in order
+	delivery sync.Mutex
+}
+
+// wants reports whether the subscription covers a voice session
+func (sub *transcriptSubscription) wants(sessionID string) bool {
+	return sub.sessions[sessionID] || (sub.all && !sub.excluded[sessionID])
+}
+
+// errNotListening means a client has not set a logging level that lets
+// transcript notifications through, so sending would silently drop them
+var errNotListening = errors.New("client is not listening for info logging notifications")
+
+// transcriptNotifier pushes new transcript entries to subscribed MCP clients
+// as logging notifications. Delivery is driven by EventBus events but reads
+// entries from the session manager, so every client sees each entry exactly
+// once and in sequence order even though handlers run concurrently.
+// Entries are held back until the client sets a logging level of info or lower.
+type transcriptNotifier struct {
+	server   *mcp.Server
+	sessions *session.Manager
+	subs     map[*mcp.ServerSession]*transcriptSubscription
+	levels   map[*mcp.ServerSession]mcp.LoggingLevel // Logging level each client has set
+	mu       sync.Mutex                              // Guards subs, levels and subscription state; never held while sending
+}
+
+func newTranscriptNotifier(server *mcp.Server, sessions *session.Manager) *transcriptNotifier {
+	n := &transcriptNotifier{
+		server:   server,
+		sessions: sessions,
+		subs:     make(map[*mcp.ServerSession]*transcriptSubscription),
+		levels:   make(map[*mcp.ServerSession]mcp.LoggingLevel),
+	}
+	server.AddReceivingMiddleware(n.trackLogLevel)
+	return n
+}
+
+// trackLogLevel records the logging level clients set and delivers entries
+// held back while they weren't listening
+func (n *transcriptNotifier) trackLogLevel(next mcp.MethodHandler[*mcp.ServerSession]) mcp.MethodHandler[*mcp.ServerSession] {
+	return func(ctx context.Context, ss *mcp.ServerSession, method string, params mcp.Params) (mcp.Result, error) {
+		result, err := next(ctx, ss, method, params)
+		if err != nil || method != "logging/setLevel" {
+			return result, err
+		}
+		if p, ok := params.(*mcp.SetLevelParams); ok && p != nil {
+			n.mu.Lock()
+			n.levels[ss] = p.Level
+			n.mu.Unlock()
+			go n.deliverBacklog(ss)
+		}
+		return result, err
+	}
+}
+
+// subscribe starts delivery to ss for one voice session, or all if sessionID is empty.
+// Only entries added after the call are delivered.
+func (n *transcriptNotifier) subscribe(ss *mcp.ServerSession, sessionID string) error {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sub, exists := n.subs[ss]
+	if !exists {
+		sub = &transcriptSubscription{
+			sessions: make(map[string]bool),
+			excluded: make(map[string]bool),
+			cursors:  make(map[string]int64),
+		}
+	}
+
+	if sessionID == "" {
+		sub.all = true
+		clear(sub.excluded)
+		for _, s := range n.sessions.ListSessions() {
+			if _, seen := sub.cursors[s.ID]; !seen {
+				latest, _ := n.sessions.LatestSequence(s.ID)
+				sub.cursors[s.ID] = latest
+			}
+		}
+	} else {
+		latest, err := n.sessions.LatestSequence(sessionID)
+		if err != nil {
+			return err
+		}
+		sub.sessions[sessionID] = true
+		delete(sub.excluded, sessionID)
+		if _, seen := sub.cursors[sessionID]; !seen {
+			sub.cursors[sessionID] = latest
+		}
+	}
+
+	n.subs[ss] = sub
+	return nil
+}
+
+// unsubscribe stops delivery to ss for one voice session, or entirely if sessionID is empty.
+// A session left while subscribed to all stays excluded until subscribed to again.
+func (n *transcriptNotifier) unsubscribe(ss *mcp.ServerSession, sessionID string) {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	sub, exists := n.subs[ss]
+	if !exists {
+		return
+	}
+	if sessionID == "" {
+		delete(n.subs, ss)
+		return
+	}
+
+	delete(sub.sessions, sessionID)
+	delete(sub.cursors, sessionID)
+	if sub.all {
+		sub.excluded[sessionID] = true
+	}
+	if !sub.all && len(sub.sessions) == 0 {
+		delete(n.subs, ss)
+	}
+}
+
+// attach subscribes the notifier to the events it forwards
+func (n *transcriptNotifier) attach(bus *feedback.EventBus) {
+	bus.Subscribe(feedback.EventTranscriptionCompleted, func(event feedback.Event) {
+		n.deliverTranscripts(event.SessionID)
+	})
+	bus.Subscribe(feedback.EventTranscriptTranslated, n.deliverTranslation)
+	bus.Subscribe(feedback.EventSessionEnded, func(event feedback.Event) {
+		n.deliverSessionEnded(event.SessionID)
+	})
+	bus.Subscribe(feedback.EventRetranscriptionProgress, n.deliverRetranscription)
+	bus.Subscribe(feedback.EventRetranscriptionCompleted, n.deliverRetranscription)
+}
+
+// subscribersOf returns the clients subscribed to a voice session
+func (n *transcriptNotifier) subscribersOf(sessionID string) map[*mcp.ServerSession]*transcriptSubscription {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	targets := make(map[*mcp.ServerSession]*transcriptSubscription)
+	for ss, sub := range n.liveSubscriptionsLocked() {
+		if sub.wants(sessionID) {
+			targets[ss] = sub
+		}
+	}
+	return targets
+}
+
+// deliverTranscripts sends every entry a subscriber hasn't seen yet for a voice session
+func (n *transcriptNotifier) deliverTranscripts(sessionID string) {
+	var wg sync.WaitGroup
+	for ss, sub := range n.subscribersOf(sessionID) {
+		wg.Go(func() { n.deliverTranscriptsTo(ss, sub, sessionID) })
+	}
+	wg.Wait()
+}
+
+// deliverBacklog sends a client the entries held back for every voice session it subscribes to
+func (n *transcriptNotifier) deliverBacklog(ss *mcp.ServerSession) {
+	n.mu.Lock()
+	sub, exists := n.liveSubscriptionsLocked()[ss]
+	n.mu.Unlock()
+	if !exists {
+		return
+	}
+
+	for _, s := range n.sessions.ListSessions() {
+		n.deliverTranscriptsTo(ss, sub, s.ID)
+	}
+}
+
+// deliverTranscriptsTo sends one client the entries of a voice session it hasn't seen yet.
+// The cursor only advances past entries the client actually received.
+func (n *transcriptNotifier) deliverTranscriptsTo(ss *mcp.ServerSession, sub *transcriptSubscription, sessionID string) {
+	sub.delivery.Lock()
+	defer sub.delivery.Unlock()
+
+	n.mu.Lock()
+	active := n.subs[ss] == sub && sub.wants(sessionID)
+	cursor := sub.cursors[sessionID]
+	n.mu.Unlock()
+	if !active {
+		return
+	}
+
+	page, err := n.sessions.QueryTranscripts(sessionID, session.TranscriptQuery{AfterSequence: cursor})
+	if err != nil {
+		logrus.WithError(err).WithField("session_id", sessionID).Debug("Skipping transcript notification")
+		return
+	}
+
+	for _, t := range page.Transcripts {
+		data := map[string]any{
+			"event":     "transcript.added",
+			"sessionId": sessionID,
+			"sequence":  t.Sequence,
+			"timestamp": t.Timestamp,
+			"userId":    t.UserID,
+			"username":  t.Username,
+			"text":      t.Text,
+			"resource":  TranscriptResourceURI(sessionID),
+		}
+		if !t.AudioStart.IsZero() {
+			start, end := t.Span()
+			data["audioStart"] = start
+			data["audioEnd"] = end
+		}
+		if t.Translation != "" {
+			data["translation"] = t.Translation
+			data["translationLanguage"] = t.TranslationLanguage
+		}
+		if err := n.send(ss, data); err != nil {
+			logrus.WithError(err).WithField("session_id", sessionID).Debug("Holding back transcript notification")
+			return
+		}
+
+		n.mu.Lock()
+		active = n.subs[ss] == sub && sub.wants(sessionID)
+		if active && t.Sequence > sub.cursors[sessionID] {
+			sub.cursors[sessionID] = t.Sequence
+		}
+		n.mu.Unlock()
+		if !active {
+			return
+		}
+	}
+}
+
+// deliverTranslation sends a translation that completed after its entry was
+// delivered. Clients that haven't received the entry yet get it with the entry.
+func (n *transcriptNotifier) deliverTranslation(event feedback.Event) {
+	translated, ok := event.Data.(feedback.TranscriptTranslatedData)
+	if !ok {
+		return
+	}
+
+	for ss, sub := range n.subscribersOf(event.SessionID) {
+		// Wait for an entry delivery in progress so the cursor is current
+		sub.delivery.Lock()
+		n.mu.Lock()
+		delivered := n.subs[ss] == sub && sub.cursors[event.SessionID] >= translated.Sequence
+		n.mu.Unlock()
+		if delivered {
+			err := n.send(ss, map[string]any{
+				"event":               "transcript.translated",
+				"sessionId":           event.SessionID,
+				"sequence":            translated.Sequence,
+				"translation":         translated.Translation,
+				"translationLanguage": translated.Language,
+				"resource":            TranscriptResourceURI(event.SessionID),
+			})
+			if err != nil {
+				logrus.WithError(err).WithField("session_id", event.SessionID).Debug("Failed to send translation notification")
+			}
+		}
+		sub.delivery.Unlock()
+	}
+}
+
+// deliverSessionEnded tells subscribers a voice session will receive no more entries
+func (n *transcriptNotifier) deliverSessionEnded(sessionID string) {
+	for ss := range n.subscribersOf(sessionID) {
+		err := n.send(ss, map[string]any{
+			"event":     "session.ended",
+			"sessionId": sessionID,
+			"resource":  TranscriptResourceURI(sessionID),
+		})
+		if err != nil {
+			logrus.WithError(err).WithField("session_id", sessionID).Debug("Failed to send session ended notification")
+		}
+	}
+}
+
+// deliverRetranscription tells subscribers how a re-transcription of a voice session is progressing
+func (n *transcriptNotifier) deliverRetranscription(event feedback.Event) {
+	progress, ok := event.Data.(feedback.RetranscriptionData)
+	if !ok {
+		return
+	}
+
+	for ss := range n.subscribersOf(event.SessionID) {
+		data := map[string]any{
+			"event":     string(event.Type),
+			"sessionId": event.SessionID,
+			"jobId":     progress.JobID,
+			"completed": progress.Completed,
+			"failed":    progress.Failed,
+			"total":     progress.Total,
+		}
+		if progress.Error != "" {
+			data["error"] = progress.Error
+		}
+		if err := n.send(ss, data); err != nil {
+			logrus.WithError(err).WithField("session_id", event.SessionID).Debug("Failed to send re-transcription notification")
+		}
+	}
+}
+
+// liveSubscriptionsLocked drops state of disconnected clients and returns the remaining subscriptions.
+// Caller must hold n.mu.
+func (n *transcriptNotifier) liveSubscriptionsLocked() map[*mcp.ServerSession]*transcriptSubscription {
+	live := make(map[*mcp.ServerSession]bool)
+	for ss := range n.server.Sessions() {
+		live[ss] = true
+	}
+	for ss := range n.subs {
+		if !live[ss] {
+			delete(n.subs, ss)
+		}
+	}
+	for ss := range n.levels {
+		if !live[ss] {
+			delete(n.levels, ss)
+		}
+	}
+	return n.subs
+}
+
+// listening reports whether a client's logging level lets info notifications through
+func (n *transcriptNotifier) listening(ss *mcp.ServerSession) bool {
+	n.mu.Lock()
+	defer n.mu.Unlock()
+
+	switch n.levels[ss] {
+	case "debug", "info":
+		return true
+	default:
+		return false
+	}
+}
+
+// send delivers one logging notification, or returns errNotListening if the
+// client would not receive it
+func (n *transcriptNotifier) send(ss *mcp.ServerSession, data map[string]any) error {
+	if !n.listening(ss) {
+		return errNotListening
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), notifyTimeout)
+	defer cancel()
+
+	return ss.Log(ctx, &mcp.LoggingMessageParams{
+		Level:  "info",
+		Logger: transcriptLogger,
+		Data:   data,
+	})
+}
+
+// AttachEventBus forwards live transcription events to subscribed MCP clients
+func (s *Server) AttachEventBus(bus *feedback.EventBus) {
+	s.notifier.attach(bus)
+	logrus.Debug("MCP server attached to transcription event bus")
+}
+
+// SubscribeTranscriptInput represents the input for the transcript subscription tools
+type SubscribeTranscriptInput struct {
+	SessionID string `json:"sessionId,omitempty"`
+}
+
+// handleSubscribeTranscript starts pushing new transcript entries to the calling client
+func (s *Server) handleSubscribeTranscript(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SubscribeTranscriptInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	sessionID := params.Arguments.SessionID
+	logrus.WithField("session_id", sessionID).Debug("MCP: Subscribe transcript request")
+
+	if err := s.notifier.subscribe(ss, sessionID); err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+
+	target := "all sessions"
+	if sessionID != "" {
+		target = "session " + sessionID
+	}
+	message := fmt.Sprintf("Subscribed to new transcript entries for %s. "+
+		"They are delivered as logging notifications from the %q logger; set the logging level to info or lower to receive them.",
+		target, transcriptLogger)
+
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}, nil
+}
+
+// handleUnsubscribeTranscript stops pushing transcript entries to the calling client
+func (s *Server) handleUnsubscribeTranscript(ctx context.Context, ss *mcp.ServerSession, params *mcp.CallToolParamsFor[SubscribeTranscriptInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	sessionID := params.Arguments.SessionID
+	logrus.WithField("session_id", sessionID).Debug("MCP: Unsubscribe transcript request")
+
+	s.notifier.unsubscribe(ss, sessionID)
+
+	message := "Unsubscribed from all transcript notifications"
+	if sessionID != "" {
+		message = fmt.Sprintf("Unsubscribed from transcript notifications for session %s", sessionID)
+	}
+
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}, nil
+}
@@ -0,0 +1,193 @@
+package mcp
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/feedback"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestSubscribeTranscriptPushesNewEntries(t *testing.T) {
+	// Setup
+	sessionManager := session.NewManager()
+	sessionID := sessionManager.CreateSession("guild1", "channel1")
+	require.NoError(t, sessionManager.AddTranscript(sessionID, "user1", "User1", "Said before subscribing"))
+
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	bus := feedback.NewEventBus(10)
+	defer bus.Stop()
+	server.AttachEventBus(bus)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcpServer.Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	defer func() { _ = serverSession.Close() }()
+
+	received := make(chan map[string]any, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			if data, ok := params.Data.(map[string]any); ok && params.Logger == transcriptLogger {
+				received <- data
+			}
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer func() { _ = clientSession.Close() }()
+
+	require.NoError(t, clientSession.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}))
+	_, err = clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "subscribe_transcript",
+		Arguments: map[string]any{"sessionId": sessionID},
+	})
+	require.NoError(t, err)
+
+	// Two entries arrive; only the first publishes an event, both are delivered in order
+	require.NoError(t, sessionManager.AddTranscript(sessionID, "user2", "User2", "First live entry"))
+	require.NoError(t, sessionManager.AddTranscript(sessionID, "user2", "User2", "Second live entry"))
+	bus.PublishTranscriptionCompleted(sessionID, feedback.TranscriptionCompletedData{UserID: "user2"})
+	bus.PublishTranscriptionCompleted(sessionID, feedback.TranscriptionCompletedData{UserID: "user2"})
+
+	for _, expected := range []string{"First live entry", "Second live entry"} {
+		select {
+		case data := <-received:
+			assert.Equal(t, "transcript.added", data["event"])
+			assert.Equal(t, sessionID, data["sessionId"])
+			assert.Equal(t, expected, data["text"])
+		case <-time.After(2 * time.Second):
+			t.Fatalf("timed out waiting for %q", expected)
+		}
+	}
+
+	// Translations completing after delivery follow as their own update
+	latest, err := sessionManager.LatestSequence(sessionID)
+	require.NoError(t, err)
+	require.NoError(t, sessionManager.SetTranslation(sessionID, latest, "Zweiter Eintrag", "de"))
+	bus.Publish(feedback.Event{
+		Type:      feedback.EventTranscriptTranslated,
+		SessionID: sessionID,
+		Data:      feedback.TranscriptTranslatedData{Sequence: latest, Translation: "Zweiter Eintrag", Language: "de"},
+	})
+	select {
+	case data := <-received:
+		assert.Equal(t, "transcript.translated", data["event"])
+		assert.EqualValues(t, latest, data["sequence"])
+		assert.Equal(t, "Zweiter Eintrag", data["translation"])
+		assert.Equal(t, "de", data["translationLanguage"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the translation")
+	}
+
+	// Unsubscribed clients hear nothing more
+	_, err = clientSession.CallTool(ctx, &mcp.CallToolParams{Name: "unsubscribe_transcript", Arguments: map[string]any{}})
+	require.NoError(t, err)
+	require.NoError(t, sessionManager.AddTranscript(sessionID, "user2", "User2", "After unsubscribing"))
+	bus.PublishTranscriptionCompleted(sessionID, feedback.TranscriptionCompletedData{UserID: "user2"})
+
+	select {
+	case data := <-received:
+		t.Fatalf("unexpected notification after unsubscribe: %v", data)
+	case <-time.After(200 * time.Millisecond):
+	}
+}
+
+func TestSubscribeTranscriptHoldsEntriesUntilLevelSet(t *testing.T) {
+	// Setup
+	sessionManager := session.NewManager()
+	sessionID := sessionManager.CreateSession("guild1", "channel1")
+
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	bus := feedback.NewEventBus(10)
+	defer bus.Stop()
+	server.AttachEventBus(bus)
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+	serverSession, err := server.mcpServer.Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	defer func() { _ = serverSession.Close() }()
+
+	received := make(chan map[string]any, 10)
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, &mcp.ClientOptions{
+		LoggingMessageHandler: func(ctx context.Context, cs *mcp.ClientSession, params *mcp.LoggingMessageParams) {
+			if data, ok := params.Data.(map[string]any); ok && params.Logger == transcriptLogger {
+				received <- data
+			}
+		},
+	})
+	clientSession, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	defer func() { _ = clientSession.Close() }()
+
+	_, err = clientSession.CallTool(ctx, &mcp.CallToolParams{
+		Name:      "subscribe_transcript",
+		Arguments: map[string]any{"sessionId": sessionID},
+	})
+	require.NoError(t, err)
+
+	// Entries added before the client listens are held back, not skipped
+	require.NoError(t, sessionManager.AddTranscript(sessionID, "user1", "User1", "Before setting a level"))
+	bus.PublishTranscriptionCompleted(sessionID, feedback.TranscriptionCompletedData{UserID: "user1"})
+
+	select {
+	case data := <-received:
+		t.Fatalf("unexpected notification before setting a level: %v", data)
+	case <-time.After(200 * time.Millisecond):
+	}
+
+	require.NoError(t, clientSession.SetLevel(ctx, &mcp.SetLevelParams{Level: "info"}))
+
+	select {
+	case data := <-received:
+		assert.Equal(t, "Before setting a level", data["text"])
+	case <-time.After(2 * time.Second):
+		t.Fatal("timed out waiting for the held back entry")
+	}
+}
+
+func TestUnsubscribeSessionFromAllSubscription(t *testing.T) {
+	sessionManager := session.NewManager()
+	first := sessionManager.CreateSession("guild1", "channel1")
+	second := sessionManager.CreateSession("guild2", "channel2")
+	notifier := newTranscriptNotifier(mcp.NewServer(&mcp.Implementation{Name: "test"}, nil), sessionManager)
+	ss := &mcp.ServerSession{}
+
+	require.NoError(t, notifier.subscribe(ss, ""))
+	notifier.unsubscribe(ss, first)
+
+	sub := notifier.subs[ss]
+	require.NotNil(t, sub)
+	assert.False(t, sub.wants(first), "left session stays excluded")
+	assert.True(t, sub.wants(second))
+	assert.True(t, sub.wants("future-session"))
+
+	require.NoError(t, notifier.subscribe(ss, first))
+	assert.True(t, sub.wants(first), "subscribing again lifts the exclusion")
+
+	notifier.unsubscribe(ss, "")
+	assert.NotContains(t, notifier.subs, ss)
+}
+
+func TestSubscribeTranscriptUnknownSession(t *testing.T) {
+	sessionManager := session.NewManager()
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	_, err := server.handleSubscribeTranscript(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[SubscribeTranscriptInput]{
+		Arguments: SubscribeTranscriptInput{SessionID: "missing"},
+	})
+	assert.Error(t, err)
+}
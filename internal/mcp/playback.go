@@ -0,0 +1,148 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// maxSpeakLength limits how much text one speak call may read out
+const maxSpeakLength = 2000
+
+// playbackGuildDescription documents the guildId argument of the playback tools
+const playbackGuildDescription = "Guild whose voice channel to use (default: the only one the bot is in)"
+
+type SpeakInput struct {
+	Text    string `json:"text"`
+	GuildID string `json:"guildId,omitempty"`
+}
+
+// handleSpeak plays synthesized speech in a voice channel
+func (s *Server) handleSpeak(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[SpeakInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	text := strings.TrimSpace(params.Arguments.Text)
+	logrus.WithField("chars", len(text)).Debug("MCP: Speak request")
+
+	if text == "" {
+		return nil, fmt.Errorf("text is required")
+	}
+	if len(text) > maxSpeakLength {
+		return nil, fmt.Errorf("text is too long (%d characters, maximum %d)", len(text), maxSpeakLength)
+	}
+
+	duration, err := s.bot.Speak(ctx, params.Arguments.GuildID, text)
+	if err != nil {
+		return nil, fmt.Errorf("failed to speak: %w", err)
+	}
+
+	return textResult(fmt.Sprintf("Spoke for %s", duration.Round(100*time.Millisecond))), nil
+}
+
+type PlayAudioInput struct {
+	Source  string `json:"source"`
+	Title   string `json:"title,omitempty"`
+	GuildID string `json:"guildId,omitempty"`
+}
+
+// handlePlayAudio queues an audio file or URL in a voice channel
+func (s *Server) handlePlayAudio(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[PlayAudioInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.WithField("source", params.Arguments.Source).Debug("MCP: Play audio request")
+
+	track, position, err := s.bot.PlayAudio(params.Arguments.GuildID, strings.TrimSpace(params.Arguments.Source), params.Arguments.Title)
+	if err != nil {
+		return nil, fmt.Errorf("failed to play audio: %w", err)
+	}
+
+	message := fmt.Sprintf("Queued %s to play next (track %s)", track.Title, track.ID)
+	if position > 0 {
+		message = fmt.Sprintf("Queued %s behind %d other track(s) (track %s)", track.Title, position, track.ID)
+	}
+	return textResult(message), nil
+}
+
+// handlePausePlayback pauses the current track
+func (s *Server) handlePausePlayback(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.Debug("MCP: Pause playback request")
+
+	if err := s.bot.PausePlayback(params.Arguments.GuildID); err != nil {
+		return nil, fmt.Errorf("failed to pause playback: %w", err)
+	}
+	return textResult("Playback paused"), nil
+}
+
+// handleResumePlayback resumes a paused track
+func (s *Server) handleResumePlayback(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.Debug("MCP: Resume playback request")
+
+	if err := s.bot.ResumePlayback(params.Arguments.GuildID); err != nil {
+		return nil, fmt.Errorf("failed to resume playback: %w", err)
+	}
+	return textResult("Playback resumed"), nil
+}
+
+// handleSkipTrack skips to the next queued track
+func (s *Server) handleSkipTrack(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.Debug("MCP: Skip track request")
+
+	skipped, err := s.bot.SkipTrack(params.Arguments.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to skip track: %w", err)
+	}
+	if !skipped {
+		return textResult("Nothing is playing"), nil
+	}
+	return textResult("Skipped current track"), nil
+}
+
+// handleStopPlayback stops playback and clears the queue
+func (s *Server) handleStopPlayback(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.Debug("MCP: Stop playback request")
+
+	if err := s.bot.StopPlayback(params.Arguments.GuildID); err != nil {
+		return nil, fmt.Errorf("failed to stop playback: %w", err)
+	}
+	return textResult("Playback stopped and queue cleared"), nil
+}
+
+// handleGetPlaybackStatus reports the current track and queue
+func (s *Server) handleGetPlaybackStatus(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.Debug("MCP: Get playback status request")
+
+	status, err := s.bot.PlaybackStatus(params.Arguments.GuildID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get playback status: %w", err)
+	}
+	return textResult(formatPlaybackStatus(status)), nil
+}
+
+// formatPlaybackStatus renders a playback status for display
+func formatPlaybackStatus(status audio.PlaybackStatus) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Playback: %s\n", status.State)
+	if status.Current != nil {
+		fmt.Fprintf(&b, "  Current: %s (%s played, track %s)\n",
+			status.Current.Title, status.Current.Position.Round(time.Second), status.Current.ID)
+	}
+	if len(status.Queue) == 0 {
+		b.WriteString("  Queue: empty\n")
+		return b.String()
+	}
+	b.WriteString("  Queue:\n")
+	for i, track := range status.Queue {
+		fmt.Fprintf(&b, "    %d. %s (track %s)\n", i+1, track.Title, track.ID)
+	}
+	return b.String()
+}
+
+// textResult wraps a message in a tool result
+func textResult(message string) *mcp.CallToolResultFor[struct{}] {
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}
+}
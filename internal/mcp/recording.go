@@ -0,0 +1,105 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// defaultClipPadding is added around a transcript's audio so clipped words aren't cut off
+	defaultClipPadding = 250 * time.Millisecond
+	maxClipPadding     = 5 * time.Second
+)
+
+// SetRecorder lets get_audio_clip read sessions that are still being recorded
+func (s *Server) SetRecorder(recorder *recording.Recorder) {
+	s.recorder = recorder
+}
+
+type GetAudioClipInput struct {
+	SessionID string `json:"sessionId"`
+	Sequence  int64  `json:"sequence"`
+	Mix       bool   `json:"mix,omitempty"`
+	PaddingMs int    `json:"paddingMs,omitempty"`
+}
+
+// handleGetAudioClip returns the recorded audio behind one transcript entry
+func (s *Server) handleGetAudioClip(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GetAudioClipInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"session_id": args.SessionID,
+		"sequence":   args.Sequence,
+		"mix":        args.Mix,
+	}).Debug("MCP: Get audio clip request")
+
+	sessionData, err := s.sessions.Snapshot(args.SessionID)
+	if err != nil {
+		return nil, fmt.Errorf("session not found: %w", err)
+	}
+	if sessionData.Recording == nil {
+		return nil, fmt.Errorf("session %s was not recorded", args.SessionID)
+	}
+
+	var found bool
+	var start, end time.Time
+	var userID, username string
+	for _, t := range sessionData.Transcripts {
+		if t.Sequence == args.Sequence {
+			start, end = t.Span()
+			userID, username, found = t.UserID, t.Username, true
+			break
+		}
+	}
+	if !found {
+		return nil, fmt.Errorf("transcript #%d not found in session %s", args.Sequence, args.SessionID)
+	}
+
+	files := []string{sessionData.Recording.Mix}
+	if !args.Mix {
+		files = sessionData.Recording.TracksFor(userID)
+		if len(files) == 0 {
+			return nil, fmt.Errorf("no recorded track for %s; pass mix to clip the mixed-down track", username)
+		}
+	}
+	paths := make([]string, len(files))
+	for i, file := range files {
+		paths[i] = filepath.Join(sessionData.Recording.Dir, file)
+	}
+
+	// A live recording buffers the latest audio in memory
+	if s.recorder != nil {
+		if err := s.recorder.Flush(args.SessionID); err != nil {
+			return nil, fmt.Errorf("failed to flush recording: %w", err)
+		}
+	}
+
+	padding := defaultClipPadding
+	if args.PaddingMs > 0 {
+		padding = min(time.Duration(args.PaddingMs)*time.Millisecond, maxClipPadding)
+	}
+	from := start.Sub(sessionData.Recording.Start) - padding
+	to := end.Sub(sessionData.Recording.Start) + padding
+
+	data, err := recording.ClipTracks(paths, max(from, 0), to)
+	if err != nil {
+		return nil, fmt.Errorf("failed to clip recording: %w", err)
+	}
+
+	source := "the speaker's track"
+	if args.Mix {
+		source = "the mixed-down track"
+	}
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: fmt.Sprintf("Audio of transcript #%d by %s (%.1fs from %s, Ogg Opus)",
+				args.Sequence, username, (to - max(from, 0)).Seconds(), source)},
+			&mcp.AudioContent{Data: data, MIMEType: "audio/ogg"},
+		},
+	}, nil
+}
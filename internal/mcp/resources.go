@@ -0,0 +1,131 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// resourceScheme is the URI scheme for all resources served by this server
+	resourceScheme = "discord-voice"
+
+	transcriptJSONTemplate     = resourceScheme + "://sessions/{sessionId}/transcript"
+	transcriptMarkdownTemplate = resourceScheme + "://sessions/{sessionId}/transcript.md"
+
+	mimeTypeJSON     = "application/json"
+	mimeTypeMarkdown = "text/markdown"
+)
+
+// TranscriptResourceURI returns the URI of a session's JSON transcript resource
+func TranscriptResourceURI(sessionID string) string {
+	return fmt.Sprintf("%s://sessions/%s/transcript", resourceScheme, sessionID)
+}
+
+// TranscriptMarkdownResourceURI returns the URI of a session's Markdown transcript resource
+func TranscriptMarkdownResourceURI(sessionID string) string {
+	return TranscriptResourceURI(sessionID) + ".md"
+}
+
+// registerResources exposes session transcripts as MCP resources so clients
+// without access to the server's filesystem can read them
+func (s *Server) registerResources() {
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "session-transcript",
+		Title:       "Session transcript (JSON)",
+		Description: "Full session including metadata and every transcript entry",
+		MIMEType:    mimeTypeJSON,
+		URITemplate: transcriptJSONTemplate,
+	}, s.handleReadTranscriptResource)
+
+	s.mcpServer.AddResourceTemplate(&mcp.ResourceTemplate{
+		Name:        "session-transcript-markdown",
+		Title:       "Session transcript (Markdown)",
+		Description: "Session transcript formatted as Markdown meeting minutes",
+		MIMEType:    mimeTypeMarkdown,
+		URITemplate: transcriptMarkdownTemplate,
+	}, s.handleReadTranscriptResource)
+
+	// Sessions restored from the store, then every new one
+	for _, sess := range s.sessions.ListSessions() {
+		s.addSessionResources(sess)
+	}
+	s.sessions.OnSessionCreated(s.addSessionResources)
+}
+
+// addSessionResources lists a session's transcripts in resources/list
+func (s *Server) addSessionResources(sess session.Session) {
+	title := fmt.Sprintf("Voice session %s (channel %s)", sess.StartTime.Format("2006-01-02 15:04"), sess.ChannelID)
+
+	s.mcpServer.AddResource(&mcp.Resource{
+		Name:        "session-" + sess.ID,
+		Title:       title,
+		Description: "Transcript of voice session " + sess.ID,
+		MIMEType:    mimeTypeJSON,
+		URI:         TranscriptResourceURI(sess.ID),
+	}, s.handleReadTranscriptResource)
+
+	s.mcpServer.AddResource(&mcp.Resource{
+		Name:        "session-" + sess.ID + "-markdown",
+		Title:       title + " - Markdown",
+		Description: "Markdown minutes of voice session " + sess.ID,
+		MIMEType:    mimeTypeMarkdown,
+		URI:         TranscriptMarkdownResourceURI(sess.ID),
+	}, s.handleReadTranscriptResource)
+}
+
+// handleReadTranscriptResource serves both transcript variants
+func (s *Server) handleReadTranscriptResource(ctx context.Context, ss *mcp.ServerSession, params *mcp.ReadResourceParams) (*mcp.ReadResourceResult, error) {
+	logrus.WithField("uri", params.URI).Debug("MCP: Read transcript resource")
+
+	sessionID, format, err := parseTranscriptURI(params.URI)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+
+	data, err := s.sessions.Render(sessionID, format)
+	if err != nil {
+		return nil, mcp.ResourceNotFoundError(params.URI)
+	}
+
+	mimeType := mimeTypeJSON
+	if format == session.FormatMarkdown {
+		mimeType = mimeTypeMarkdown
+	}
+
+	return &mcp.ReadResourceResult{
+		Contents: []*mcp.ResourceContents{
+			{URI: params.URI, MIMEType: mimeType, Text: string(data)},
+		},
+	}, nil
+}
+
+// parseTranscriptURI extracts the session ID and format from a transcript resource URI
+func parseTranscriptURI(uri string) (string, session.ExportFormat, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return "", "", err
+	}
+	if u.Scheme != resourceScheme || u.Host != "sessions" {
+		return "", "", fmt.Errorf("not a session resource: %s", uri)
+	}
+
+	parts := strings.Split(strings.TrimPrefix(u.Path, "/"), "/")
+	if len(parts) != 2 || parts[0] == "" {
+		return "", "", fmt.Errorf("malformed session resource: %s", uri)
+	}
+
+	switch parts[1] {
+	case "transcript":
+		return parts[0], session.FormatJSON, nil
+	case "transcript.md":
+		return parts[0], session.FormatMarkdown, nil
+	default:
+		return "", "", fmt.Errorf("unknown session resource: %s", uri)
+	}
+}
@@ -0,0 +1,114 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// connectTestClient connects an in-memory MCP client to the server
+func connectTestClient(t *testing.T, server *Server) *mcp.ClientSession {
+	t.Helper()
+
+	ctx := context.Background()
+	serverTransport, clientTransport := mcp.NewInMemoryTransports()
+
+	serverSession, err := server.mcpServer.Connect(ctx, serverTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = serverSession.Close() })
+
+	client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+	clientSession, err := client.Connect(ctx, clientTransport)
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = clientSession.Close() })
+
+	return clientSession
+}
+
+func TestParseTranscriptURI(t *testing.T) {
+	id, format, err := parseTranscriptURI("discord-voice://sessions/abc-123/transcript")
+	require.NoError(t, err)
+	assert.Equal(t, "abc-123", id)
+	assert.Equal(t, session.FormatJSON, format)
+
+	id, format, err = parseTranscriptURI("discord-voice://sessions/abc-123/transcript.md")
+	require.NoError(t, err)
+	assert.Equal(t, "abc-123", id)
+	assert.Equal(t, session.FormatMarkdown, format)
+
+	for _, uri := range []string{
+		"file:///sessions/abc/transcript",
+		"discord-voice://other/abc/transcript",
+		"discord-voice://sessions//transcript",
+		"discord-voice://sessions/abc/audio",
+		"discord-voice://sessions/abc/transcript/extra",
+	} {
+		_, _, err := parseTranscriptURI(uri)
+		assert.Error(t, err, uri)
+	}
+}
+
+func TestTranscriptResources(t *testing.T) {
+	// Setup - one session restored before the server starts, one created after
+	sessionManager := session.NewManager()
+	existing := sessionManager.CreateSession("guild1", "channel1")
+	require.NoError(t, sessionManager.AddTranscript(existing, "user1", "User1", "Hello from before"))
+
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	created := sessionManager.CreateSession("guild1", "channel2")
+	require.NoError(t, sessionManager.AddTranscript(created, "user2", "User2", "Hello from after"))
+
+	ctx := context.Background()
+	client := connectTestClient(t, server)
+
+	// Both sessions are listed in both variants
+	list, err := client.ListResources(ctx, &mcp.ListResourcesParams{})
+	require.NoError(t, err)
+	uris := make([]string, 0, len(list.Resources))
+	for _, r := range list.Resources {
+		uris = append(uris, r.URI)
+	}
+	assert.Contains(t, uris, TranscriptResourceURI(existing))
+	assert.Contains(t, uris, TranscriptMarkdownResourceURI(existing))
+	assert.Contains(t, uris, TranscriptResourceURI(created))
+	assert.Contains(t, uris, TranscriptMarkdownResourceURI(created))
+
+	templates, err := client.ListResourceTemplates(ctx, &mcp.ListResourceTemplatesParams{})
+	require.NoError(t, err)
+	assert.Len(t, templates.ResourceTemplates, 2)
+
+	// JSON variant reflects transcripts added after registration
+	require.NoError(t, sessionManager.AddTranscript(created, "user2", "User2", "Late message"))
+	result, err := client.ReadResource(ctx, &mcp.ReadResourceParams{URI: TranscriptResourceURI(created)})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, "application/json", result.Contents[0].MIMEType)
+
+	var sessionData session.Session
+	require.NoError(t, json.Unmarshal([]byte(result.Contents[0].Text), &sessionData))
+	assert.Equal(t, created, sessionData.ID)
+	assert.Len(t, sessionData.Transcripts, 2)
+
+	// Markdown variant
+	result, err = client.ReadResource(ctx, &mcp.ReadResourceParams{URI: TranscriptMarkdownResourceURI(existing)})
+	require.NoError(t, err)
+	require.Len(t, result.Contents, 1)
+	assert.Equal(t, "text/markdown", result.Contents[0].MIMEType)
+	assert.Contains(t, result.Contents[0].Text, "Hello from before")
+
+	// Unknown session is reported as not found
+	_, err = client.ReadResource(ctx, &mcp.ReadResourceParams{URI: TranscriptResourceURI("missing")})
+	assert.Error(t, err)
+}
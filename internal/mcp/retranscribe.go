@@ -0,0 +1,78 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// SetRetranscriber enables the retranscribe_session tool
+func (s *Server) SetRetranscriber(retranscriber *audio.Retranscriber) {
+	s.retranscriber = retranscriber
+}
+
+type RetranscribeSessionInput struct {
+	SessionID   string `json:"sessionId"`
+	Transcriber string `json:"transcriber,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Language    string `json:"language,omitempty"`
+}
+
+// handleRetranscribeSession starts transcribing a session's retained audio again
+func (s *Server) handleRetranscribeSession(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[RetranscribeSessionInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"session_id":  args.SessionID,
+		"transcriber": args.Transcriber,
+		"model":       args.Model,
+	}).Debug("MCP: Retranscribe session request")
+
+	if s.retranscriber == nil {
+		return nil, errors.New("re-transcription is not available")
+	}
+
+	status, err := s.retranscriber.Start(audio.RetranscribeRequest{
+		SessionID:   args.SessionID,
+		Transcriber: args.Transcriber,
+		Model:       args.Model,
+		Language:    args.Language,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start re-transcription: %w", err)
+	}
+
+	message := fmt.Sprintf("Re-transcribing %d segments of session %s with %s.\n"+
+		"Read the result with get_transcript and alternate %s once it completes; "+
+		"transcript subscribers are notified of the progress.",
+		status.Total, status.SessionID, status.Transcriber, status.ID)
+
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}, nil
+}
+
+// retranscriptionPending describes a re-transcription that has not stored its
+// alternate transcript yet, or returns "" if there is none
+func (s *Server) retranscriptionPending(alternateID string) (string, error) {
+	if s.retranscriber == nil {
+		return "", nil
+	}
+	status, exists := s.retranscriber.Status(alternateID)
+	if !exists {
+		return "", nil
+	}
+	if status.Done && status.Error != "" {
+		return "", fmt.Errorf("re-transcription %s failed: %s", alternateID, status.Error)
+	}
+	if status.Done {
+		return "", nil
+	}
+	return fmt.Sprintf("Re-transcription %s with %s is in progress: %d of %d segments done\n",
+		status.ID, status.Transcriber, status.Completed, status.Total), nil
+}
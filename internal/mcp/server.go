@@ -3,9 +3,13 @@ package mcp
 import (
 	"context"
 	"fmt"
+	"slices"
+	"sort"
 	"time"
 
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
 	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 	"github.com/modelcontextprotocol/go-sdk/jsonschema"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
@@ -18,6 +22,16 @@ type Server struct {
 	bot       *bot.VoiceBot
 	sessions  *session.Manager
 	userID    string // Configured user ID for "my channel" commands
+	notifier  *transcriptNotifier
+
+	// Replays retained audio through another transcriber; nil disables retranscribe_session
+	retranscriber *audio.Retranscriber
+
+	// Transcribes recorded audio files into new sessions; nil disables transcribe_file
+	fileTranscriber *audio.FileTranscriber
+
+	// Flushes sessions still being recorded before clipping; nil if recording is off
+	recorder *recording.Recorder
 }
 
 // NewServer creates a new MCP server for Discord voice
@@ -39,9 +53,11 @@ func NewServer(voiceBot *bot.VoiceBot, sessionManager *session.Manager, userID s
 		sessions:  sessionManager,
 		userID:    userID,
 	}
+	s.notifier = newTranscriptNotifier(mcpServer, sessionManager)
 
-	// Register all tools
+	// Register all tools and resources
 	s.registerTools()
+	s.registerResources()
 
 	return s
 }
@@ -95,19 +111,15 @@ func (s *Server) registerTools() {
 
 	mcp.AddTool[JoinChannelInput, struct{}](s.mcpServer, &mcp.Tool{
 		Name:        "join_specific_channel",
-		Description: "Join a specific Discord voice channel by ID",
+		Description: "Join a specific Discord voice channel by ID. Channels in other guilds stay connected; the bot's channel in the same guild is left",
 		InputSchema: joinSchema,
 	}, s.handleJoinVoiceChannel)
 
 	// Leave voice channel tool
-	leaveSchema := &jsonschema.Schema{
-		Type: "object",
-	}
-
-	mcp.AddTool[EmptyInput, struct{}](s.mcpServer, &mcp.Tool{
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
 		Name:        "leave_voice_channel",
-		Description: "Leave the current voice channel",
-		InputSchema: leaveSchema,
+		Description: "Leave the voice channel in a guild, or every voice channel",
+		InputSchema: guildSchema("Guild whose voice channel to leave (default: every voice channel)"),
 	}, s.handleLeaveVoiceChannel)
 
 	// Get transcript tool
@@ -118,13 +130,42 @@ func (s *Server) registerTools() {
 				Type:        "string",
 				Description: "Session ID to retrieve transcript for",
 			},
+			"since": {
+				Type:        "integer",
+				Description: "Cursor from a previous call; only entries after it are returned",
+			},
+			"sinceTime": {
+				Type:        "string",
+				Description: "Only return entries at or after this RFC 3339 timestamp",
+			},
+			"limit": {
+				Type:        "integer",
+				Description: "Maximum number of entries to return",
+			},
+			"userId": {
+				Type:        "string",
+				Description: "Only return entries spoken by this Discord user ID",
+			},
+			"view": {
+				Type:        "string",
+				Description: "Text to show for translated sessions (default: original)",
+				Enum:        []any{viewOriginal, viewTranslated, viewBoth},
+			},
+			"raw": {
+				Type:        "boolean",
+				Description: "Show the raw transcriber segments instead of merged utterances (for debugging)",
+			},
+			"alternate": {
+				Type:        "string",
+				Description: "ID of an alternate transcript from retranscribe_session to read instead of the live one (shown as raw segments)",
+			},
 		},
 		Required: []string{"sessionId"},
 	}
 
 	mcp.AddTool[GetTranscriptInput, struct{}](s.mcpServer, &mcp.Tool{
 		Name:        "get_transcript",
-		Description: "Get transcript for a session",
+		Description: "Get transcript for a session as utterances merged per speaker. Pass the returned cursor as since to poll for new entries only; an utterance that grew is returned again in full",
 		InputSchema: transcriptSchema,
 	}, s.handleGetTranscript)
 
@@ -147,26 +188,334 @@ func (s *Server) registerTools() {
 				Type:        "string",
 				Description: "Session ID to export",
 			},
+			"format": {
+				Type:        "string",
+				Description: "Export format (default: json)",
+				Enum:        exportFormatEnum(),
+			},
 		},
 		Required: []string{"sessionId"},
 	}
 
 	mcp.AddTool[ExportSessionInput, struct{}](s.mcpServer, &mcp.Tool{
 		Name:        "export_session",
-		Description: "Export a session to JSON file",
+		Description: "Export a session to a file as JSON, Markdown minutes, SRT/WebVTT subtitles, CSV or plain text",
 		InputSchema: exportSchema,
 	}, s.handleExportSession)
 
-	// Get bot status tool
-	statusSchema := &jsonschema.Schema{
+	// Audio clip tool
+	clipSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID",
+			},
+			"sequence": {
+				Type:        "integer",
+				Description: "Sequence number of the transcript entry to fetch the audio of",
+			},
+			"mix": {
+				Type:        "boolean",
+				Description: "Clip the mix of all speakers instead of the speaker's own track",
+			},
+			"paddingMs": {
+				Type:        "integer",
+				Description: "Extra audio before and after the entry in milliseconds (default: 250, max: 5000)",
+			},
+		},
+		Required: []string{"sessionId", "sequence"},
+	}
+
+	mcp.AddTool[GetAudioClipInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "get_audio_clip",
+		Description: "Get the recorded audio of a transcript entry as Ogg Opus, to check what was actually said",
+		InputSchema: clipSchema,
+	}, s.handleGetAudioClip)
+
+	// Re-transcription tool
+	retranscribeSchema := &jsonschema.Schema{
 		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID to transcribe again",
+			},
+			"transcriber": {
+				Type:        "string",
+				Description: "Transcriber to use (default: the live transcriber)",
+				Enum:        []any{"whisper", "whisper-server", "openai", "google", "mock"},
+			},
+			"model": {
+				Type:        "string",
+				Description: "Model for the transcriber, e.g. the path of a larger whisper model (default: the configured one)",
+			},
+			"language": {
+				Type:        "string",
+				Description: "Language code to transcribe in (default: the language each segment was transcribed in)",
+			},
+		},
+		Required: []string{"sessionId"},
 	}
 
-	mcp.AddTool[EmptyInput, struct{}](s.mcpServer, &mcp.Tool{
+	mcp.AddTool[RetranscribeSessionInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "retranscribe_session",
+		Description: "Transcribe a recorded session's audio again with a different transcriber, model or language. Runs in the background and stores the result as an alternate transcript; the live transcript is left untouched",
+		InputSchema: retranscribeSchema,
+	}, s.handleRetranscribeSession)
+
+	// Audio file transcription tool
+	transcribeFileSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"path": {
+				Type:        "string",
+				Description: "Path of a WAV (16-bit PCM) or Ogg Opus file on the server",
+			},
+			"transcriber": {
+				Type:        "string",
+				Description: "Transcriber to use (default: the live transcriber)",
+				Enum:        []any{"whisper", "whisper-server", "openai", "google", "mock"},
+			},
+			"model": {
+				Type:        "string",
+				Description: "Model for the transcriber (default: the configured one)",
+			},
+			"language": {
+				Type:        "string",
+				Description: "Language code to transcribe in (default: the transcriber's default)",
+			},
+			"speaker": {
+				Type:        "string",
+				Description: "Name to attribute the transcripts to (default: Speaker)",
+			},
+			"format": {
+				Type:        "string",
+				Description: "Format of the export written when done (default: json)",
+				Enum:        exportFormatEnum(),
+			},
+		},
+		Required: []string{"path"},
+	}
+
+	mcp.AddTool[TranscribeFileInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "transcribe_file",
+		Description: "Transcribe a recorded audio file with the live segmentation and transcriber into a new session. Runs in the background; the session is ended and exported when done",
+		InputSchema: transcribeFileSchema,
+	}, s.handleTranscribeFile)
+
+	// Set language tool
+	languageSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"language": {
+				Type:        "string",
+				Description: "Language code such as en or de, auto to detect, or default to reset",
+			},
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID (default: the active session)",
+			},
+			"userId": {
+				Type:        "string",
+				Description: "Only set the language for this Discord user ID",
+			},
+		},
+		Required: []string{"language"},
+	}
+
+	mcp.AddTool[SetLanguageInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "set_language",
+		Description: "Set the transcription language for a session, or for one speaker in it",
+		InputSchema: languageSchema,
+	}, s.handleSetLanguage)
+
+	// Set translation tool
+	translationSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"language": {
+				Type:        "string",
+				Description: "Target language code such as en or de, or off to stop translating",
+			},
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID (default: the active session)",
+			},
+		},
+		Required: []string{"language"},
+	}
+
+	mcp.AddTool[SetTranslationInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "set_translation",
+		Description: "Translate new transcripts of a session into a target language, kept alongside the original text",
+		InputSchema: translationSchema,
+	}, s.handleSetTranslation)
+
+	// Vocabulary tools; each tool needs its own schema instance
+	vocabularySchema := func(termsDescription string) *jsonschema.Schema {
+		return &jsonschema.Schema{
+			Type: "object",
+			Properties: map[string]*jsonschema.Schema{
+				"terms": {
+					Type:        "array",
+					Description: termsDescription,
+					Items:       &jsonschema.Schema{Type: "string"},
+				},
+				"guildId": {
+					Type:        "string",
+					Description: "Discord guild ID (default: the guild the bot is in)",
+				},
+			},
+			Required: []string{"terms"},
+		}
+	}
+
+	mcp.AddTool[VocabularyInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "add_vocabulary",
+		Description: "Add terms to a guild's vocabulary, used to prompt the transcriber",
+		InputSchema: vocabularySchema("Game names, nicknames or jargon the transcriber should spell correctly"),
+	}, s.handleAddVocabulary)
+
+	mcp.AddTool[VocabularyInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "remove_vocabulary",
+		Description: "Remove terms from a guild's vocabulary",
+		InputSchema: vocabularySchema("Terms to remove (case-insensitive)"),
+	}, s.handleRemoveVocabulary)
+
+	listVocabularySchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"guildId": {
+				Type:        "string",
+				Description: "Discord guild ID (default: the guild the bot is in)",
+			},
+		},
+	}
+
+	mcp.AddTool[ListVocabularyInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "list_vocabulary",
+		Description: "List a guild's vocabulary and the voice channel member names included automatically",
+		InputSchema: listVocabularySchema,
+	}, s.handleListVocabulary)
+
+	// Get bot status tool
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
 		Name:        "get_bot_status",
-		Description: "Get current bot connection status",
-		InputSchema: statusSchema,
+		Description: "Get current bot connection status and every active voice connection",
+		InputSchema: guildSchema("Only show the voice connection in this guild (default: all)"),
 	}, s.handleGetBotStatus)
+
+	// Speak tool
+	speakSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"text": {
+				Type:        "string",
+				Description: "Text to say in the voice channel",
+				MaxLength:   jsonschema.Ptr(maxSpeakLength),
+			},
+			"guildId": {
+				Type:        "string",
+				Description: playbackGuildDescription,
+			},
+		},
+		Required: []string{"text"},
+	}
+
+	mcp.AddTool[SpeakInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "speak",
+		Description: "Say text out loud in the current voice channel using text-to-speech",
+		InputSchema: speakSchema,
+	}, s.handleSpeak)
+
+	// Audio playback tools
+	playSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"source": {
+				Type:        "string",
+				Description: "Local audio file path (WAV, OGG, MP3, ...) or http(s) URL",
+			},
+			"title": {
+				Type:        "string",
+				Description: "Display name for the queue (default: file name)",
+			},
+			"guildId": {
+				Type:        "string",
+				Description: playbackGuildDescription,
+			},
+		},
+		Required: []string{"source"},
+	}
+
+	mcp.AddTool[PlayAudioInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "play_audio",
+		Description: "Queue an audio file or URL for playback in the current voice channel",
+		InputSchema: playSchema,
+	}, s.handlePlayAudio)
+
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "pause_playback",
+		Description: "Pause the audio that is currently playing",
+		InputSchema: guildSchema(playbackGuildDescription),
+	}, s.handlePausePlayback)
+
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "resume_playback",
+		Description: "Resume paused audio",
+		InputSchema: guildSchema(playbackGuildDescription),
+	}, s.handleResumePlayback)
+
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "skip_track",
+		Description: "Skip the current track and play the next one in the queue",
+		InputSchema: guildSchema(playbackGuildDescription),
+	}, s.handleSkipTrack)
+
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "stop_playback",
+		Description: "Stop playback and clear the queue",
+		InputSchema: guildSchema(playbackGuildDescription),
+	}, s.handleStopPlayback)
+
+	mcp.AddTool[GuildInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "get_playback_status",
+		Description: "Show the current track, its position and the playback queue",
+		InputSchema: guildSchema(playbackGuildDescription),
+	}, s.handleGetPlaybackStatus)
+
+	// Live transcript subscription tools
+	subscribeSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID to follow (default: all sessions)",
+			},
+		},
+	}
+	unsubscribeSchema := &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"sessionId": {
+				Type:        "string",
+				Description: "Session ID to stop following (default: all sessions)",
+			},
+		},
+	}
+
+	mcp.AddTool[SubscribeTranscriptInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "subscribe_transcript",
+		Description: "Receive new transcript entries as logging notifications while people speak",
+		InputSchema: subscribeSchema,
+	}, s.handleSubscribeTranscript)
+
+	mcp.AddTool[SubscribeTranscriptInput, struct{}](s.mcpServer, &mcp.Tool{
+		Name:        "unsubscribe_transcript",
+		Description: "Stop receiving transcript notifications for a session, or for all sessions",
+		InputSchema: unsubscribeSchema,
+	}, s.handleUnsubscribeTranscript)
 }
 
 // Tool handlers - updated to match MCP SDK signature
@@ -200,33 +549,157 @@ func (s *Server) handleJoinVoiceChannel(ctx context.Context, session *mcp.Server
 
 type EmptyInput struct{}
 
-func (s *Server) handleLeaveVoiceChannel(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[EmptyInput]) (*mcp.CallToolResultFor[struct{}], error) {
-	logrus.Debug("MCP: Leave voice channel request")
+// GuildInput selects one of the bot's voice connections by guild
+type GuildInput struct {
+	GuildID string `json:"guildId,omitempty"`
+}
 
-	s.bot.LeaveChannel()
+// guildSchema is the input schema of tools that only take an optional guild
+func guildSchema(description string) *jsonschema.Schema {
+	return &jsonschema.Schema{
+		Type: "object",
+		Properties: map[string]*jsonschema.Schema{
+			"guildId": {
+				Type:        "string",
+				Description: description,
+			},
+		},
+	}
+}
+
+func (s *Server) handleLeaveVoiceChannel(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.WithField("guild_id", params.Arguments.GuildID).Debug("MCP: Leave voice channel request")
+
+	message := "Not in a voice channel"
+	switch left := s.bot.LeaveChannel(params.Arguments.GuildID); {
+	case left == 1:
+		message = "Left voice channel"
+	case left > 1:
+		message = fmt.Sprintf("Left %d voice channels", left)
+	}
 
 	return &mcp.CallToolResultFor[struct{}]{
 		Content: []mcp.Content{
-			&mcp.TextContent{Text: "Left voice channel"},
+			&mcp.TextContent{Text: message},
 		},
 	}, nil
 }
 
 type GetTranscriptInput struct {
 	SessionID string `json:"sessionId"`
+	Since     int64  `json:"since,omitempty"`
+	SinceTime string `json:"sinceTime,omitempty"`
+	Limit     int    `json:"limit,omitempty"`
+	UserID    string `json:"userId,omitempty"`
+	View      string `json:"view,omitempty"`
+	Raw       bool   `json:"raw,omitempty"`
+	Alternate string `json:"alternate,omitempty"`
+}
+
+// transcriptLine is one entry of get_transcript output, either an utterance or a raw segment
+type transcriptLine struct {
+	sequence            int64
+	time                time.Time
+	username            string
+	text                string
+	translation         string
+	translationLanguage string
 }
 
+// Transcript views for translated sessions
+const (
+	viewOriginal   = "original"
+	viewTranslated = "translated"
+	viewBoth       = "both"
+)
+
 func (s *Server) handleGetTranscript(ctx context.Context, sess *mcp.ServerSession, params *mcp.CallToolParamsFor[GetTranscriptInput]) (*mcp.CallToolResultFor[struct{}], error) {
-	logrus.WithField("session_id", params.Arguments.SessionID).Debug("MCP: Get transcript request")
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"session_id": args.SessionID,
+		"since":      args.Since,
+		"limit":      args.Limit,
+	}).Debug("MCP: Get transcript request")
+
+	view := args.View
+	switch view {
+	case "":
+		view = viewOriginal
+	case viewOriginal, viewTranslated, viewBoth:
+	default:
+		return nil, fmt.Errorf("invalid view %q: use original, translated or both", args.View)
+	}
 
-	sessionData, err := s.sessions.GetSession(params.Arguments.SessionID)
+	sessionData, err := s.sessions.Snapshot(args.SessionID)
 	if err != nil {
 		return nil, fmt.Errorf("session not found: %w", err)
 	}
 
+	if args.Alternate != "" {
+		pending, err := s.retranscriptionPending(args.Alternate)
+		if err != nil {
+			return nil, err
+		}
+		if pending != "" {
+			return &mcp.CallToolResultFor[struct{}]{
+				Content: []mcp.Content{
+					&mcp.TextContent{Text: pending},
+				},
+			}, nil
+		}
+	}
+
+	query := session.TranscriptQuery{
+		AfterSequence: args.Since,
+		UserID:        args.UserID,
+		Limit:         args.Limit,
+		Alternate:     args.Alternate,
+	}
+	if args.SinceTime != "" {
+		query.Since, err = time.Parse(time.RFC3339, args.SinceTime)
+		if err != nil {
+			return nil, fmt.Errorf("invalid sinceTime: %w", err)
+		}
+	}
+
+	var lines []transcriptLine
+	var nextCursor int64
+	var hasMore bool
+	// Alternate transcripts are not merged into utterances
+	if args.Raw || args.Alternate != "" {
+		page, err := s.sessions.QueryTranscripts(args.SessionID, query)
+		if err != nil {
+			return nil, err
+		}
+		for _, t := range page.Transcripts {
+			start, _ := t.Span()
+			lines = append(lines, transcriptLine{t.Sequence, start, t.Username, t.Text, t.Translation, t.TranslationLanguage})
+		}
+		nextCursor, hasMore = page.NextCursor, page.HasMore
+	} else {
+		page, err := s.sessions.QueryUtterances(args.SessionID, query)
+		if err != nil {
+			return nil, fmt.Errorf("session not found: %w", err)
+		}
+		for _, u := range page.Utterances {
+			lines = append(lines, transcriptLine{u.Sequence, u.Start, u.Username, u.Text, u.Translation, u.TranslationLanguage})
+		}
+		nextCursor, hasMore = page.NextCursor, page.HasMore
+	}
+	// Pages follow the order entries were transcribed in; show them in the order they were spoken
+	sort.SliceStable(lines, func(i, j int) bool {
+		return lines[i].time.Before(lines[j].time)
+	})
+
 	// Format session data as text
 	transcript := fmt.Sprintf("Session %s\nStarted: %s\n",
 		sessionData.ID, sessionData.StartTime.Format("2006-01-02 15:04:05"))
+	for _, alternate := range sessionData.Alternates {
+		if alternate.ID == args.Alternate {
+			transcript += fmt.Sprintf("Alternate transcript by %s (%s)\n",
+				alternate.Transcriber, alternate.Created.Format("2006-01-02 15:04:05"))
+		}
+	}
 
 	// Show pending transcriptions if any
 	if len(sessionData.PendingTranscriptions) > 0 {
@@ -240,10 +713,33 @@ func (s *Server) handleGetTranscript(ctx context.Context, sess *mcp.ServerSessio
 
 	// Show completed transcripts
 	transcript += "\nTranscripts:\n"
-	for _, t := range sessionData.Transcripts {
-		transcript += fmt.Sprintf("[%s] %s: %s\n",
-			t.Timestamp.Format("15:04:05"), t.Username, t.Text)
+	for _, line := range lines {
+		text := line.text
+		if view == viewTranslated && line.translation != "" {
+			text = line.translation
+		}
+		transcript += fmt.Sprintf("#%d [%s] %s: %s\n",
+			line.sequence, line.time.Format("15:04:05"), line.username, text)
+		if view == viewBoth && line.translation != "" {
+			transcript += fmt.Sprintf("    (%s) %s\n", line.translationLanguage, line.translation)
+		}
+	}
+	if len(lines) == 0 && args.Since > 0 {
+		transcript += "  (no new entries)\n"
+	}
+
+	if args.Alternate == "" && len(sessionData.Alternates) > 0 {
+		transcript += "\nAlternate transcripts:\n"
+		for _, alternate := range sessionData.Alternates {
+			transcript += fmt.Sprintf("  %s by %s (%d entries)\n", alternate.ID, alternate.Transcriber, len(alternate.Transcripts))
+		}
+	}
+
+	transcript += fmt.Sprintf("\nNext cursor: %d", nextCursor)
+	if hasMore {
+		transcript += " (more entries available)"
 	}
+	transcript += "\n"
 
 	return &mcp.CallToolResultFor[struct{}]{
 		Content: []mcp.Content{
@@ -268,8 +764,9 @@ func (s *Server) handleListSessions(ctx context.Context, sess *mcp.ServerSession
 			if len(s.PendingTranscriptions) > 0 {
 				pendingIndicator = fmt.Sprintf(" (⏳ %d pending)", len(s.PendingTranscriptions))
 			}
-			output += fmt.Sprintf("Session %s\n  Started: %s\n  Transcripts: %d%s\n\n",
-				s.ID, s.StartTime.Format("2006-01-02 15:04:05"), len(s.Transcripts), pendingIndicator)
+			output += fmt.Sprintf("Session %s\n  Status: %s\n  Started: %s\n  Duration: %s\n  Transcripts: %d%s\n\n",
+				s.ID, s.Status(), s.StartTime.Format("2006-01-02 15:04:05"), s.Duration().Round(time.Second),
+				len(s.Transcripts), pendingIndicator)
 		}
 	}
 
@@ -282,17 +779,36 @@ func (s *Server) handleListSessions(ctx context.Context, sess *mcp.ServerSession
 
 type ExportSessionInput struct {
 	SessionID string `json:"sessionId"`
+	Format    string `json:"format,omitempty"`
+}
+
+// exportFormatEnum lists the accepted export format names for the tool schema
+func exportFormatEnum() []any {
+	formats := make([]any, 0, len(session.ExportFormats))
+	for _, format := range session.ExportFormats {
+		formats = append(formats, string(format))
+	}
+	return formats
 }
 
 func (s *Server) handleExportSession(ctx context.Context, sess *mcp.ServerSession, params *mcp.CallToolParamsFor[ExportSessionInput]) (*mcp.CallToolResultFor[struct{}], error) {
-	logrus.WithField("session_id", params.Arguments.SessionID).Debug("MCP: Export session request")
+	logrus.WithFields(logrus.Fields{
+		"session_id": params.Arguments.SessionID,
+		"format":     params.Arguments.Format,
+	}).Debug("MCP: Export session request")
+
+	format, err := session.ParseExportFormat(params.Arguments.Format)
+	if err != nil {
+		return nil, err
+	}
 
-	filepath, err := s.sessions.ExportSession(params.Arguments.SessionID)
+	filepath, err := s.sessions.ExportSessionAs(params.Arguments.SessionID, format)
 	if err != nil {
 		return nil, fmt.Errorf("failed to export session: %w", err)
 	}
 
-	message := fmt.Sprintf("Session exported to: %s", filepath)
+	message := fmt.Sprintf("Session exported to: %s\nAlso readable as resource: %s",
+		filepath, TranscriptResourceURI(params.Arguments.SessionID))
 
 	return &mcp.CallToolResultFor[struct{}]{
 		Content: []mcp.Content{
@@ -301,8 +817,8 @@ func (s *Server) handleExportSession(ctx context.Context, sess *mcp.ServerSessio
 	}, nil
 }
 
-func (s *Server) handleGetBotStatus(ctx context.Context, sess *mcp.ServerSession, params *mcp.CallToolParamsFor[EmptyInput]) (*mcp.CallToolResultFor[struct{}], error) {
-	logrus.Debug("MCP: Get bot status request")
+func (s *Server) handleGetBotStatus(ctx context.Context, sess *mcp.ServerSession, params *mcp.CallToolParamsFor[GuildInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.WithField("guild_id", params.Arguments.GuildID).Debug("MCP: Get bot status request")
 
 	status := s.bot.GetStatus()
 	followUser, autoFollow := s.bot.GetFollowStatus()
@@ -323,11 +839,21 @@ func (s *Server) handleGetBotStatus(ctx context.Context, sess *mcp.ServerSession
 		statusText += "  In Voice: unknown\n"
 	}
 
-	if guildID, ok := status["guildID"].(string); ok {
-		statusText += fmt.Sprintf("  Guild ID: %s\n", guildID)
+	connections, _ := status["connections"].([]bot.ConnectionStatus)
+	if guildID := params.Arguments.GuildID; guildID != "" {
+		connections = slices.DeleteFunc(connections, func(conn bot.ConnectionStatus) bool {
+			return conn.GuildID != guildID
+		})
+		if len(connections) == 0 {
+			statusText += fmt.Sprintf("  Not in a voice channel in guild %s\n", guildID)
+		}
 	}
-	if channelID, ok := status["channelID"].(string); ok {
-		statusText += fmt.Sprintf("  Channel ID: %s\n", channelID)
+	for _, conn := range connections {
+		statusText += fmt.Sprintf("  Guild %s, channel %s\n", conn.GuildID, conn.ChannelID)
+		if sessionData, err := s.sessions.Snapshot(conn.SessionID); err == nil {
+			statusText += fmt.Sprintf("    Session: %s (%s, %s)\n",
+				conn.SessionID, sessionData.Status(), sessionData.Duration().Round(time.Second))
+		}
 	}
 
 	if followUser != "" {
@@ -3,12 +3,17 @@ package mcp
 import (
 	"context"
 	"os"
+	"path/filepath"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/fankserver/discord-voice-mcp/internal/audio"
 	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/recording"
 	"github.com/fankserver/discord-voice-mcp/internal/session"
 	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/fankserver/discord-voice-mcp/pkg/tts"
 	"github.com/modelcontextprotocol/go-sdk/mcp"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
@@ -122,6 +127,35 @@ func TestHandleExportSessionNonExistent(t *testing.T) {
 	_ = os.RemoveAll("exports")
 }
 
+func TestHandleExportSessionFormats(t *testing.T) {
+	// Setup
+	sessionManager := session.NewManager()
+	sessionManager.SetExportDir(t.TempDir())
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild1", "channel1")
+	_ = sessionManager.AddTranscript(sessionID, "user1", "User1", "Message 1")
+
+	ctx := context.Background()
+	sess := &mcp.ServerSession{}
+	result, err := server.handleExportSession(ctx, sess, &mcp.CallToolParamsFor[ExportSessionInput]{
+		Arguments: ExportSessionInput{SessionID: sessionID, Format: "srt"},
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, ".srt")
+
+	// Unknown formats are rejected
+	_, err = server.handleExportSession(ctx, sess, &mcp.CallToolParamsFor[ExportSessionInput]{
+		Arguments: ExportSessionInput{SessionID: sessionID, Format: "docx"},
+	})
+	assert.Error(t, err)
+}
+
 func TestHandleJoinMyVoiceChannelNoUserConfigured(t *testing.T) {
 	// Setup with empty user ID
 	sessionManager := session.NewManager()
@@ -237,6 +271,26 @@ func TestHandleListSessionsWithData(t *testing.T) {
 	assert.Contains(t, textContent.Text, session1)
 	assert.Contains(t, textContent.Text, session2)
 	assert.Contains(t, textContent.Text, "1 pending") // Session 2 has pending
+	assert.Contains(t, textContent.Text, "Status: active")
+}
+
+func TestHandleListSessionsShowsEndedStatus(t *testing.T) {
+	// Setup
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild1", "channel1")
+	require.NoError(t, sessionManager.EndSession(sessionID))
+
+	result, err := server.handleListSessions(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[EmptyInput]{})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Status: ended")
+	assert.Contains(t, textContent.Text, "Duration:")
 }
 
 func TestHandleGetBotStatus(t *testing.T) {
@@ -253,8 +307,8 @@ func TestHandleGetBotStatus(t *testing.T) {
 	// Test getting bot status
 	ctx := context.Background()
 	sess := &mcp.ServerSession{}
-	params := &mcp.CallToolParamsFor[EmptyInput]{
-		Arguments: EmptyInput{},
+	params := &mcp.CallToolParamsFor[GuildInput]{
+		Arguments: GuildInput{},
 	}
 
 	result, err := server.handleGetBotStatus(ctx, sess, params)
@@ -272,6 +326,29 @@ func TestHandleGetBotStatus(t *testing.T) {
 	assert.Contains(t, textContent.Text, "Auto-Follow: true")
 }
 
+func TestHandleLeaveAndStatusByGuild(t *testing.T) {
+	sessionManager := session.NewManager()
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	result, err := server.handleLeaveVoiceChannel(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GuildInput]{
+		Arguments: GuildInput{GuildID: "guild1"},
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Equal(t, "Not in a voice channel", textContent.Text)
+
+	result, err = server.handleGetBotStatus(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GuildInput]{
+		Arguments: GuildInput{GuildID: "guild1"},
+	})
+	require.NoError(t, err)
+	textContent, ok = result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "In Voice: false")
+	assert.Contains(t, textContent.Text, "Not in a voice channel in guild guild1")
+}
+
 func TestHandleGetTranscriptWithPendingTranscriptions(t *testing.T) {
 	// Setup
 	sessionManager := session.NewManager()
@@ -307,3 +384,438 @@ func TestHandleGetTranscriptWithPendingTranscriptions(t *testing.T) {
 	assert.Contains(t, textContent.Text, "User2")
 	assert.Contains(t, textContent.Text, "3.5s")
 }
+
+func TestHandleGetTranscriptIncremental(t *testing.T) {
+	// Setup
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	_ = sessionManager.AddTranscript(sessionID, "user1", "User1", "First message")
+	_ = sessionManager.AddTranscript(sessionID, "user2", "User2", "Second message")
+	_ = sessionManager.AddTranscript(sessionID, "user1", "User1", "Third message")
+
+	ctx := context.Background()
+	sess := &mcp.ServerSession{}
+	result, err := server.handleGetTranscript(ctx, sess, &mcp.CallToolParamsFor[GetTranscriptInput]{
+		Arguments: GetTranscriptInput{SessionID: sessionID, Since: 1, Limit: 1},
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.NotContains(t, textContent.Text, "First message")
+	assert.Contains(t, textContent.Text, "#2")
+	assert.Contains(t, textContent.Text, "Second message")
+	assert.NotContains(t, textContent.Text, "Third message")
+	assert.Contains(t, textContent.Text, "Next cursor: 2 (more entries available)")
+
+	// Filter by speaker
+	result, err = server.handleGetTranscript(ctx, sess, &mcp.CallToolParamsFor[GetTranscriptInput]{
+		Arguments: GetTranscriptInput{SessionID: sessionID, UserID: "user1"},
+	})
+	require.NoError(t, err)
+	textContent, ok = result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Third message")
+	assert.NotContains(t, textContent.Text, "Second message")
+	assert.Contains(t, textContent.Text, "Next cursor: 3")
+
+	// Invalid timestamp
+	_, err = server.handleGetTranscript(ctx, sess, &mcp.CallToolParamsFor[GetTranscriptInput]{
+		Arguments: GetTranscriptInput{SessionID: sessionID, SinceTime: "yesterday"},
+	})
+	assert.Error(t, err)
+}
+
+func TestHandleSpeakValidation(t *testing.T) {
+	sessionManager := session.NewManager()
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+	ctx := context.Background()
+
+	speak := func(text string) error {
+		_, err := server.handleSpeak(ctx, &mcp.ServerSession{}, &mcp.CallToolParamsFor[SpeakInput]{
+			Arguments: SpeakInput{Text: text},
+		})
+		return err
+	}
+
+	assert.ErrorContains(t, speak("   "), "text is required")
+	assert.ErrorContains(t, speak(strings.Repeat("a", maxSpeakLength+1)), "too long")
+
+	// Valid text still needs a synthesizer and a voice connection
+	assert.ErrorContains(t, speak("hello"), "not configured")
+	voiceBot.SetSynthesizer(&tts.ToneSynthesizer{})
+	assert.ErrorContains(t, speak("hello"), "not connected")
+}
+
+func TestFormatPlaybackStatus(t *testing.T) {
+	assert.Equal(t, "Playback: idle\n  Queue: empty\n", formatPlaybackStatus(audio.PlaybackStatus{State: audio.PlaybackIdle}))
+
+	text := formatPlaybackStatus(audio.PlaybackStatus{
+		State:   audio.PlaybackPaused,
+		Current: &audio.TrackInfo{ID: "t1", Title: "intro.mp3", Position: 65 * time.Second},
+		Queue:   []audio.TrackInfo{{ID: "t2", Title: "outro.mp3"}},
+	})
+	assert.Contains(t, text, "Playback: paused")
+	assert.Contains(t, text, "Current: intro.mp3 (1m5s played, track t1)")
+	assert.Contains(t, text, "1. outro.mp3 (track t2)")
+}
+
+func TestHandlePlayAudioWithoutVoice(t *testing.T) {
+	sessionManager := session.NewManager()
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	_, err := server.handlePlayAudio(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[PlayAudioInput]{
+		Arguments: PlayAudioInput{Source: "https://example.com/clip.mp3"},
+	})
+	assert.ErrorContains(t, err, "not connected")
+
+	_, err = server.handleGetPlaybackStatus(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GuildInput]{})
+	assert.Error(t, err)
+}
+
+func TestHandleSetLanguage(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	ctx := context.Background()
+	sess := &mcp.ServerSession{}
+
+	result, err := server.handleSetLanguage(ctx, sess, &mcp.CallToolParamsFor[SetLanguageInput]{
+		Arguments: SetLanguageInput{Language: "DE", SessionID: sessionID, UserID: "user-1"},
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "user user-1")
+	assert.Equal(t, "de", sessionManager.Language(sessionID, "user-1"))
+	assert.Equal(t, "", sessionManager.Language(sessionID, "user-2"))
+
+	// "default" clears the override
+	_, err = server.handleSetLanguage(ctx, sess, &mcp.CallToolParamsFor[SetLanguageInput]{
+		Arguments: SetLanguageInput{Language: "default", SessionID: sessionID, UserID: "user-1"},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", sessionManager.Language(sessionID, "user-1"))
+
+	_, err = server.handleSetLanguage(ctx, sess, &mcp.CallToolParamsFor[SetLanguageInput]{
+		Arguments: SetLanguageInput{Language: "not a language", SessionID: sessionID},
+	})
+	assert.Error(t, err)
+
+	// Without a sessionId the bot must be recording
+	_, err = server.handleSetLanguage(ctx, sess, &mcp.CallToolParamsFor[SetLanguageInput]{
+		Arguments: SetLanguageInput{Language: "en"},
+	})
+	assert.Error(t, err)
+}
+
+func TestHandleSetTranslationAndTranscriptViews(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	ctx := context.Background()
+	sess := &mcp.ServerSession{}
+
+	_, err := server.handleSetTranslation(ctx, sess, &mcp.CallToolParamsFor[SetTranslationInput]{
+		Arguments: SetTranslationInput{Language: "en", SessionID: sessionID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "en", sessionManager.TranslationTarget(sessionID))
+
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "Hallo zusammen",
+		Translation: "Hello everyone", TranslationLanguage: "en",
+	}))
+
+	read := func(view string) string {
+		result, err := server.handleGetTranscript(ctx, sess, &mcp.CallToolParamsFor[GetTranscriptInput]{
+			Arguments: GetTranscriptInput{SessionID: sessionID, View: view},
+		})
+		require.NoError(t, err)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		return textContent.Text
+	}
+
+	original := read("")
+	assert.Contains(t, original, "User1: Hallo zusammen")
+	assert.NotContains(t, original, "Hello everyone")
+
+	translated := read("translated")
+	assert.Contains(t, translated, "User1: Hello everyone")
+	assert.NotContains(t, translated, "Hallo zusammen")
+
+	both := read("both")
+	assert.Contains(t, both, "User1: Hallo zusammen")
+	assert.Contains(t, both, "(en) Hello everyone")
+
+	_, err = server.handleGetTranscript(ctx, sess, &mcp.CallToolParamsFor[GetTranscriptInput]{
+		Arguments: GetTranscriptInput{SessionID: sessionID, View: "summary"},
+	})
+	assert.Error(t, err)
+
+	_, err = server.handleSetTranslation(ctx, sess, &mcp.CallToolParamsFor[SetTranslationInput]{
+		Arguments: SetTranslationInput{Language: "off", SessionID: sessionID},
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "", sessionManager.TranslationTarget(sessionID))
+}
+
+func TestHandleVocabulary(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	ctx := context.Background()
+	sess := &mcp.ServerSession{}
+
+	result, err := server.handleAddVocabulary(ctx, sess, &mcp.CallToolParamsFor[VocabularyInput]{
+		Arguments: VocabularyInput{Terms: []string{"Valheim", "Grafana", "valheim"}, GuildID: "guild"},
+	})
+	require.NoError(t, err)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "Added 2 term(s)")
+
+	_, err = server.handleRemoveVocabulary(ctx, sess, &mcp.CallToolParamsFor[VocabularyInput]{
+		Arguments: VocabularyInput{Terms: []string{"GRAFANA"}, GuildID: "guild"},
+	})
+	require.NoError(t, err)
+
+	result, err = server.handleListVocabulary(ctx, sess, &mcp.CallToolParamsFor[ListVocabularyInput]{
+		Arguments: ListVocabularyInput{GuildID: "guild"},
+	})
+	require.NoError(t, err)
+	textContent, ok = result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "- Valheim")
+	assert.NotContains(t, textContent.Text, "Grafana")
+
+	// Without a guildId the bot must be in a voice channel
+	_, err = server.handleAddVocabulary(ctx, sess, &mcp.CallToolParamsFor[VocabularyInput]{
+		Arguments: VocabularyInput{Terms: []string{"Loki"}},
+	})
+	assert.Error(t, err)
+}
+
+func TestHandleGetTranscriptMergesUtterances(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	start := time.Now().Add(-time.Minute)
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "We should ship", AudioStart: start, Duration: 3,
+	}))
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "ship it today.", AudioStart: start.Add(3100 * time.Millisecond), Duration: 1,
+	}))
+
+	read := func(raw bool) string {
+		result, err := server.handleGetTranscript(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GetTranscriptInput]{
+			Arguments: GetTranscriptInput{SessionID: sessionID, Raw: raw},
+		})
+		require.NoError(t, err)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		return textContent.Text
+	}
+
+	merged := read(false)
+	assert.Contains(t, merged, "User1: We should ship it today.")
+	assert.Contains(t, merged, "Next cursor: 2")
+
+	raw := read(true)
+	assert.Contains(t, raw, "#1 ")
+	assert.Contains(t, raw, "User1: We should ship\n")
+	assert.Contains(t, raw, "#2 ")
+	assert.Contains(t, raw, "User1: ship it today.")
+}
+
+func TestHandleGetTranscriptOrdersBySpeechStart(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	start := time.Now().Add(-time.Minute)
+	// The reply finished transcribing before the question it answers
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user2", Username: "User2", Text: "Yes.", AudioStart: start.Add(5 * time.Second), Duration: 1,
+	}))
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "Are we shipping today?", AudioStart: start, Duration: 4,
+	}))
+
+	for _, raw := range []bool{false, true} {
+		result, err := server.handleGetTranscript(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GetTranscriptInput]{
+			Arguments: GetTranscriptInput{SessionID: sessionID, Raw: raw},
+		})
+		require.NoError(t, err)
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+
+		text := textContent.Text
+		assert.Less(t, strings.Index(text, "User1: Are we shipping today?"), strings.Index(text, "User2: Yes."), "raw=%v", raw)
+		assert.Contains(t, text, "Next cursor: 2")
+	}
+}
+
+func TestHandleGetAudioClip(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	start := time.Now().Add(-time.Minute)
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "Hello", AudioStart: start.Add(time.Second), Duration: 0.5,
+	}))
+
+	clip := func(args GetAudioClipInput) (*mcp.CallToolResultFor[struct{}], error) {
+		args.SessionID = sessionID
+		return server.handleGetAudioClip(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GetAudioClipInput]{
+			Arguments: args,
+		})
+	}
+
+	_, err := clip(GetAudioClipInput{Sequence: 1})
+	assert.ErrorContains(t, err, "not recorded")
+
+	// Two seconds of the speaker's track
+	dir := t.TempDir()
+	file, err := os.Create(filepath.Join(dir, "ssrc-1.opus"))
+	require.NoError(t, err)
+	writer, err := recording.NewOpusWriter(file, 2, 0)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSilence(2*recording.SampleRate))
+	require.NoError(t, writer.Close())
+	require.NoError(t, file.Close())
+
+	require.NoError(t, sessionManager.SetRecording(sessionID, session.Recording{
+		Dir:    dir,
+		Start:  start,
+		Mix:    recording.MixFile,
+		Tracks: []session.RecordingTrack{{SSRC: 1, UserID: "user1", Username: "User1", File: "ssrc-1.opus"}},
+	}))
+
+	result, err := clip(GetAudioClipInput{Sequence: 1})
+	require.NoError(t, err)
+	require.Len(t, result.Content, 2)
+	textContent, ok := result.Content[0].(*mcp.TextContent)
+	require.True(t, ok)
+	assert.Contains(t, textContent.Text, "transcript #1 by User1 (1.0s")
+	audioContent, ok := result.Content[1].(*mcp.AudioContent)
+	require.True(t, ok)
+	assert.Equal(t, "audio/ogg", audioContent.MIMEType)
+	assert.True(t, strings.HasPrefix(string(audioContent.Data), "OggS"))
+
+	_, err = clip(GetAudioClipInput{Sequence: 2})
+	assert.ErrorContains(t, err, "not found")
+
+	// The mix was never written
+	_, err = clip(GetAudioClipInput{Sequence: 1, Mix: true})
+	assert.ErrorContains(t, err, "failed to clip")
+}
+
+func TestHandleGetTranscriptAlternate(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	sessionID := sessionManager.CreateSession("guild", "channel")
+	start := time.Now().Add(-time.Minute)
+	require.NoError(t, sessionManager.AddTranscriptEntry(sessionID, session.Transcript{
+		UserID: "user1", Username: "User1", Text: "Ship at ten", AudioStart: start, Duration: 1,
+	}))
+
+	_, err := server.handleRetranscribeSession(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[RetranscribeSessionInput]{
+		Arguments: RetranscribeSessionInput{SessionID: sessionID},
+	})
+	assert.ErrorContains(t, err, "not available")
+
+	require.NoError(t, sessionManager.AddAlternate(sessionID, session.Alternate{
+		ID:          "alt-1",
+		Transcriber: "whisper large",
+		Created:     time.Now(),
+		Transcripts: []session.Transcript{
+			{Sequence: 1, UserID: "user1", Username: "User1", Text: "Ship at two", AudioStart: start, Duration: 1},
+		},
+	}))
+
+	read := func(alternate string) (string, error) {
+		result, err := server.handleGetTranscript(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[GetTranscriptInput]{
+			Arguments: GetTranscriptInput{SessionID: sessionID, Alternate: alternate},
+		})
+		if err != nil {
+			return "", err
+		}
+		textContent, ok := result.Content[0].(*mcp.TextContent)
+		require.True(t, ok)
+		return textContent.Text, nil
+	}
+
+	live, err := read("")
+	require.NoError(t, err)
+	assert.Contains(t, live, "User1: Ship at ten")
+	assert.Contains(t, live, "alt-1 by whisper large (1 entries)")
+
+	alternate, err := read("alt-1")
+	require.NoError(t, err)
+	assert.Contains(t, alternate, "Alternate transcript by whisper large")
+	assert.Contains(t, alternate, "User1: Ship at two")
+	assert.NotContains(t, alternate, "Ship at ten")
+
+	_, err = read("alt-2")
+	assert.ErrorContains(t, err, "not found")
+}
+
+func TestHandleTranscribeFile(t *testing.T) {
+	sessionManager := session.NewManager()
+	trans := &transcriber.MockTranscriber{}
+	audioProcessor := audio.NewProcessor(trans)
+	voiceBot, _ := bot.New("test-token", sessionManager, audioProcessor)
+	server := NewServer(voiceBot, sessionManager, "test-user-id")
+
+	transcribe := func(args TranscribeFileInput) error {
+		_, err := server.handleTranscribeFile(context.Background(), &mcp.ServerSession{}, &mcp.CallToolParamsFor[TranscribeFileInput]{
+			Arguments: args,
+		})
+		return err
+	}
+
+	assert.ErrorContains(t, transcribe(TranscribeFileInput{Path: "meeting.wav"}), "not available")
+
+	asyncProcessor := audio.NewAsyncProcessor(trans, audio.DefaultProcessorConfig())
+	defer asyncProcessor.Stop()
+	server.SetFileTranscriber(audio.NewFileTranscriber(asyncProcessor, sessionManager, nil))
+
+	assert.Error(t, transcribe(TranscribeFileInput{Path: "meeting.wav", Format: "docx"}))
+	assert.ErrorContains(t, transcribe(TranscribeFileInput{Path: filepath.Join(t.TempDir(), "missing.wav")}), "failed to start file transcription")
+	assert.Empty(t, sessionManager.ListSessions(), "no session is created for a file that cannot be read")
+}
@@ -0,0 +1,68 @@
+package mcp
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// SetFileTranscriber enables the transcribe_file tool
+func (s *Server) SetFileTranscriber(fileTranscriber *audio.FileTranscriber) {
+	s.fileTranscriber = fileTranscriber
+}
+
+type TranscribeFileInput struct {
+	Path        string `json:"path"`
+	Transcriber string `json:"transcriber,omitempty"`
+	Model       string `json:"model,omitempty"`
+	Language    string `json:"language,omitempty"`
+	Speaker     string `json:"speaker,omitempty"`
+	Format      string `json:"format,omitempty"`
+}
+
+// handleTranscribeFile starts transcribing an audio file into a new session
+func (s *Server) handleTranscribeFile(ctx context.Context, sess *mcp.ServerSession, params *mcp.CallToolParamsFor[TranscribeFileInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"path":        args.Path,
+		"transcriber": args.Transcriber,
+		"model":       args.Model,
+	}).Debug("MCP: Transcribe file request")
+
+	if s.fileTranscriber == nil {
+		return nil, errors.New("file transcription is not available")
+	}
+
+	format, err := session.ParseExportFormat(args.Format)
+	if err != nil {
+		return nil, err
+	}
+
+	sessionID, err := s.fileTranscriber.Start(audio.FileRequest{
+		Path:        args.Path,
+		Transcriber: args.Transcriber,
+		Model:       args.Model,
+		Language:    args.Language,
+		Speaker:     args.Speaker,
+		Format:      format,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to start file transcription: %w", err)
+	}
+
+	message := fmt.Sprintf("Transcribing %s into session %s.\n"+
+		"The session is ended and exported as %s when the file is done; "+
+		"read it with get_transcript, or subscribe_transcript to follow it as it is transcribed.",
+		args.Path, sessionID, format)
+
+	return &mcp.CallToolResultFor[struct{}]{
+		Content: []mcp.Content{
+			&mcp.TextContent{Text: message},
+		},
+	}, nil
+}
@@ -0,0 +1,183 @@
+package mcp
+
+import (
+	"context"
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"net"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+// Transport selects how MCP clients connect to the server
+type Transport string
+
+const (
+	TransportStdio Transport = "stdio"
+	TransportHTTP  Transport = "http" // Streamable HTTP
+	TransportSSE   Transport = "sse"  // Legacy HTTP+SSE
+
+	// DefaultHTTPAddr is the listen address used for HTTP transports when none is configured.
+	// It is loopback only; listening on other interfaces requires an auth token.
+	DefaultHTTPAddr = "127.0.0.1:8080"
+
+	// MCPPath is the HTTP path MCP clients connect to
+	MCPPath = "/mcp"
+
+	// httpShutdownTimeout bounds how long in-flight requests may take once shutdown begins
+	httpShutdownTimeout = 10 * time.Second
+	readHeaderTimeout   = 10 * time.Second
+)
+
+// ParseTransport resolves a user-supplied transport name. An empty name selects stdio.
+func ParseTransport(name string) (Transport, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "stdio":
+		return TransportStdio, nil
+	case "http", "streamable-http", "streamable":
+		return TransportHTTP, nil
+	case "sse":
+		return TransportSSE, nil
+	default:
+		return "", fmt.Errorf("unsupported transport %q", name)
+	}
+}
+
+// HTTPConfig configures the HTTP transports
+type HTTPConfig struct {
+	Addr      string // Listen address, e.g. "127.0.0.1:8080"
+	AuthToken string // Bearer token clients must present; authentication is disabled if empty
+}
+
+// Handler returns the HTTP handler serving MCP over the given HTTP transport.
+// All clients share this server, so they see the same bot and sessions.
+func (s *Server) Handler(transport Transport, authToken string) (http.Handler, error) {
+	getServer := func(*http.Request) *mcp.Server { return s.mcpServer }
+
+	var handler http.Handler
+	switch transport {
+	case TransportHTTP:
+		handler = mcp.NewStreamableHTTPHandler(getServer, nil)
+	case TransportSSE:
+		handler = mcp.NewSSEHandler(getServer)
+	default:
+		return nil, fmt.Errorf("transport %q is not served over HTTP", transport)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(MCPPath, requireBearerToken(authToken, handler))
+	mux.HandleFunc("/healthz", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		_, _ = w.Write([]byte("ok\n"))
+	})
+	return mux, nil
+}
+
+// StartHTTP serves MCP over HTTP until ctx is cancelled, then shuts down gracefully
+func (s *Server) StartHTTP(ctx context.Context, transport Transport, cfg HTTPConfig) error {
+	handler, err := s.Handler(transport, cfg.AuthToken)
+	if err != nil {
+		return err
+	}
+
+	addr := cfg.Addr
+	if addr == "" {
+		addr = DefaultHTTPAddr
+	}
+	if cfg.AuthToken == "" && !isLoopbackAddr(addr) {
+		return fmt.Errorf("refusing to serve MCP on %s without an auth token; set MCP_AUTH_TOKEN or listen on a loopback address", addr)
+	}
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return fmt.Errorf("error listening on %s: %w", addr, err)
+	}
+
+	return s.serveHTTP(ctx, listener, handler, transport, cfg.AuthToken != "")
+}
+
+// serveHTTP runs the HTTP server on an existing listener
+func (s *Server) serveHTTP(ctx context.Context, listener net.Listener, handler http.Handler, transport Transport, authenticated bool) error {
+	httpServer := &http.Server{
+		Handler:           handler,
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"transport":     transport,
+		"addr":          listener.Addr().String(),
+		"path":          MCPPath,
+		"authenticated": authenticated,
+	}).Info("Starting MCP server on HTTP")
+	if !authenticated {
+		logrus.Warn("MCP HTTP transport has no auth token; any local process can control the bot")
+	}
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- httpServer.Serve(listener)
+	}()
+
+	select {
+	case err := <-errCh:
+		return fmt.Errorf("error serving MCP over HTTP: %w", err)
+	case <-ctx.Done():
+	}
+
+	logrus.Info("Shutting down MCP HTTP server")
+
+	// Streaming responses never go idle on their own, so end client sessions first
+	for ss := range s.mcpServer.Sessions() {
+		_ = ss.Close()
+	}
+
+	shutdownCtx, cancel := context.WithTimeout(context.Background(), httpShutdownTimeout)
+	defer cancel()
+	if err := httpServer.Shutdown(shutdownCtx); err != nil {
+		logrus.WithError(err).Warn("MCP HTTP server did not shut down cleanly, closing connections")
+		_ = httpServer.Close()
+	}
+
+	if err := <-errCh; err != nil && !errors.Is(err, http.ErrServerClosed) {
+		return fmt.Errorf("error serving MCP over HTTP: %w", err)
+	}
+	return nil
+}
+
+// isLoopbackAddr reports whether a listen address only accepts local connections.
+// An empty host listens on every interface.
+func isLoopbackAddr(addr string) bool {
+	host, _, err := net.SplitHostPort(addr)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
+
+// requireBearerToken rejects requests that don't carry the configured bearer token.
+// An empty token disables authentication.
+func requireBearerToken(token string, next http.Handler) http.Handler {
+	if token == "" {
+		return next
+	}
+	expected := []byte(token)
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		provided, ok := strings.CutPrefix(r.Header.Get("Authorization"), "Bearer ")
+		if !ok || subtle.ConstantTimeCompare([]byte(provided), expected) != 1 {
+			logrus.WithField("remote_addr", r.RemoteAddr).Debug("Rejected unauthenticated MCP request")
+			w.Header().Set("WWW-Authenticate", `Bearer realm="discord-voice-mcp"`)
+			http.Error(w, "unauthorized", http.StatusUnauthorized)
+			return
+		}
+		next.ServeHTTP(w, r)
+	})
+}
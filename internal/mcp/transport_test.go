@@ -0,0 +1,155 @@
+package mcp
+
+import (
+	"context"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio"
+	"github.com/fankserver/discord-voice-mcp/internal/bot"
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/fankserver/discord-voice-mcp/pkg/transcriber"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// bearerTransport adds an Authorization header to every request
+type bearerTransport struct {
+	token string
+}
+
+func (b bearerTransport) RoundTrip(r *http.Request) (*http.Response, error) {
+	r = r.Clone(r.Context())
+	r.Header.Set("Authorization", "Bearer "+b.token)
+	return http.DefaultTransport.RoundTrip(r)
+}
+
+func newTransportTestServer(t *testing.T) *Server {
+	t.Helper()
+	sessionManager := session.NewManager()
+	voiceBot, _ := bot.New("test-token", sessionManager, audio.NewProcessor(&transcriber.MockTranscriber{}))
+	return NewServer(voiceBot, sessionManager, "test-user-id")
+}
+
+func TestParseTransport(t *testing.T) {
+	tests := map[string]Transport{
+		"":                TransportStdio,
+		"stdio":           TransportStdio,
+		"HTTP":            TransportHTTP,
+		"streamable-http": TransportHTTP,
+		"sse":             TransportSSE,
+	}
+	for input, expected := range tests {
+		transport, err := ParseTransport(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, transport, input)
+	}
+
+	_, err := ParseTransport("websocket")
+	assert.Error(t, err)
+}
+
+func TestRequireBearerToken(t *testing.T) {
+	next := http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	})
+	handler := requireBearerToken("secret", next)
+
+	for header, expected := range map[string]int{
+		"":              http.StatusUnauthorized,
+		"Bearer wrong":  http.StatusUnauthorized,
+		"Basic secret":  http.StatusUnauthorized,
+		"Bearer secret": http.StatusNoContent,
+	} {
+		req := httptest.NewRequest(http.MethodPost, MCPPath, nil)
+		if header != "" {
+			req.Header.Set("Authorization", header)
+		}
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		assert.Equal(t, expected, rec.Code, header)
+	}
+
+	// No token configured means no authentication
+	rec := httptest.NewRecorder()
+	requireBearerToken("", next).ServeHTTP(rec, httptest.NewRequest(http.MethodPost, MCPPath, nil))
+	assert.Equal(t, http.StatusNoContent, rec.Code)
+}
+
+func TestStartHTTPRequiresTokenOffLoopback(t *testing.T) {
+	server := newTransportTestServer(t)
+
+	for _, addr := range []string{":0", "0.0.0.0:0", "[::]:0", "192.0.2.1:0"} {
+		err := server.StartHTTP(context.Background(), TransportHTTP, HTTPConfig{Addr: addr})
+		assert.ErrorContains(t, err, "without an auth token", addr)
+	}
+
+	assert.True(t, isLoopbackAddr(DefaultHTTPAddr))
+	assert.True(t, isLoopbackAddr("localhost:8080"))
+	assert.True(t, isLoopbackAddr("[::1]:8080"))
+	assert.False(t, isLoopbackAddr("example.com:8080"))
+
+	// Loopback addresses and authenticated servers start
+	for _, cfg := range []HTTPConfig{{Addr: "127.0.0.1:0"}, {Addr: ":0", AuthToken: "secret"}} {
+		ctx, cancel := context.WithCancel(context.Background())
+		done := make(chan error, 1)
+		go func() { done <- server.StartHTTP(ctx, TransportHTTP, cfg) }()
+		time.Sleep(50 * time.Millisecond)
+		cancel()
+		assert.NoError(t, <-done, cfg.Addr)
+	}
+}
+
+func TestHandlerRejectsStdio(t *testing.T) {
+	_, err := newTransportTestServer(t).Handler(TransportStdio, "")
+	assert.Error(t, err)
+}
+
+func TestStreamableHTTPClientsShareServer(t *testing.T) {
+	server := newTransportTestServer(t)
+	handler, err := server.Handler(TransportHTTP, "secret")
+	require.NoError(t, err)
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan error, 1)
+	go func() {
+		done <- server.serveHTTP(ctx, listener, handler, TransportHTTP, true)
+	}()
+
+	url := "http://" + listener.Addr().String() + MCPPath
+
+	// Unauthenticated clients are turned away
+	resp, err := http.Post(url, "application/json", nil)
+	require.NoError(t, err)
+	_ = resp.Body.Close()
+	assert.Equal(t, http.StatusUnauthorized, resp.StatusCode)
+
+	// Two authenticated clients can use the tools at the same time
+	httpClient := &http.Client{Transport: bearerTransport{token: "secret"}}
+	for i := 0; i < 2; i++ {
+		client := mcp.NewClient(&mcp.Implementation{Name: "test-client", Version: "0.0.1"}, nil)
+		cs, err := client.Connect(ctx, mcp.NewStreamableClientTransport(url, &mcp.StreamableClientTransportOptions{HTTPClient: httpClient}))
+		require.NoError(t, err)
+		defer func() { _ = cs.Close() }()
+
+		result, err := cs.CallTool(ctx, &mcp.CallToolParams{Name: "list_sessions", Arguments: map[string]any{}})
+		require.NoError(t, err)
+		require.NotEmpty(t, result.Content)
+	}
+
+	// Cancelling the context shuts the server down cleanly
+	cancel()
+	select {
+	case err := <-done:
+		assert.NoError(t, err)
+	case <-time.After(httpShutdownTimeout + time.Second):
+		t.Fatal("HTTP server did not shut down")
+	}
+}
@@ -0,0 +1,111 @@
+package mcp
+
+import (
+	"context"
+	"fmt"
+	"strings"
+
+	"github.com/fankserver/discord-voice-mcp/internal/vocabulary"
+	"github.com/modelcontextprotocol/go-sdk/mcp"
+	"github.com/sirupsen/logrus"
+)
+
+type VocabularyInput struct {
+	Terms   []string `json:"terms"`
+	GuildID string   `json:"guildId,omitempty"`
+}
+
+type ListVocabularyInput struct {
+	GuildID string `json:"guildId,omitempty"`
+}
+
+// handleAddVocabulary adds terms to a guild's vocabulary
+func (s *Server) handleAddVocabulary(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[VocabularyInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"terms":    len(args.Terms),
+		"guild_id": args.GuildID,
+	}).Debug("MCP: Add vocabulary request")
+
+	guildID, err := s.vocabularyGuild(args.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	added, err := s.bot.Vocabulary().Add(guildID, args.Terms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to add vocabulary: %w", err)
+	}
+	if len(added) == 0 {
+		return textResult("No new terms; they are already in the vocabulary"), nil
+	}
+	return textResult(fmt.Sprintf("Added %d term(s) to guild %s: %s", len(added), guildID, strings.Join(added, ", "))), nil
+}
+
+// handleRemoveVocabulary removes terms from a guild's vocabulary
+func (s *Server) handleRemoveVocabulary(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[VocabularyInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	args := params.Arguments
+	logrus.WithFields(logrus.Fields{
+		"terms":    len(args.Terms),
+		"guild_id": args.GuildID,
+	}).Debug("MCP: Remove vocabulary request")
+
+	guildID, err := s.vocabularyGuild(args.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	removed, err := s.bot.Vocabulary().Remove(guildID, args.Terms)
+	if err != nil {
+		return nil, fmt.Errorf("failed to remove vocabulary: %w", err)
+	}
+	if len(removed) == 0 {
+		return textResult("None of those terms are in the vocabulary"), nil
+	}
+	return textResult(fmt.Sprintf("Removed %d term(s) from guild %s: %s", len(removed), guildID, strings.Join(removed, ", "))), nil
+}
+
+// handleListVocabulary lists a guild's vocabulary and, when recording there,
+// the channel member names that are added automatically
+func (s *Server) handleListVocabulary(ctx context.Context, session *mcp.ServerSession, params *mcp.CallToolParamsFor[ListVocabularyInput]) (*mcp.CallToolResultFor[struct{}], error) {
+	logrus.WithField("guild_id", params.Arguments.GuildID).Debug("MCP: List vocabulary request")
+
+	guildID, err := s.vocabularyGuild(params.Arguments.GuildID)
+	if err != nil {
+		return nil, err
+	}
+
+	var sb strings.Builder
+	terms := s.bot.Vocabulary().List(guildID)
+	if len(terms) == 0 {
+		fmt.Fprintf(&sb, "No vocabulary for guild %s\n", guildID)
+	} else {
+		fmt.Fprintf(&sb, "Vocabulary for guild %s (%d terms):\n", guildID, len(terms))
+		for _, term := range vocabulary.Sorted(terms) {
+			fmt.Fprintf(&sb, "- %s\n", term)
+		}
+	}
+
+	if conn, exists := s.bot.Connection(guildID); exists {
+		if names := s.bot.ChannelMemberNames(guildID, conn.ChannelID); len(names) > 0 {
+			fmt.Fprintf(&sb, "\nAlso included from the voice channel: %s\n", strings.Join(names, ", "))
+		}
+	}
+
+	return textResult(sb.String()), nil
+}
+
+// vocabularyGuild returns the given guild, or the only one the bot is in
+func (s *Server) vocabularyGuild(guildID string) (string, error) {
+	if guildID != "" {
+		return guildID, nil
+	}
+	switch connections := s.bot.Connections(); len(connections) {
+	case 0:
+		return "", fmt.Errorf("not in a voice channel; pass guildId")
+	case 1:
+		return connections[0].GuildID, nil
+	default:
+		return "", fmt.Errorf("in voice channels in %d guilds; pass guildId", len(connections))
+	}
+}
@@ -20,6 +20,10 @@ type AudioSegment struct {
 	Audio       []byte
 	Duration    time.Duration
 	Context     string
+	Language    string    // Empty uses the transcriber's default
+	Vocabulary  []string  // Terms to prompt the transcriber with
+	AudioStart  time.Time // When the first packet of the segment was captured
+	AudioEnd    time.Time // When the last packet of the segment was captured
 	Priority    int
 	Reason      string
 	SubmittedAt time.Time
@@ -27,7 +31,7 @@ type AudioSegment struct {
 	// Callbacks for progress tracking
 	OnStart    func()
 	OnProgress func(partial string)
-	OnComplete func(final string)
+	OnComplete func(result *transcriber.TranscriptResult)
 	OnError    func(error)
 }
 
@@ -416,7 +416,10 @@ func (w *SpeakerWorker) processSegment(segment *AudioSegment) {
 
 	// Transcribe with context
 	options := transcriber.TranscriptionOptions{
-		PreviousContext: segment.Context,
+		PreviousContext:  segment.Context,
+		Language:         segment.Language,
+		CustomVocabulary: segment.Vocabulary,
+		EnableTimestamps: true,
 	}
 
 	result, err := w.transcriber.TranscribeWithContext(segment.Audio, options)
@@ -443,7 +446,7 @@ func (w *SpeakerWorker) processSegment(segment *AudioSegment) {
 
 	// Notify completion
 	if segment.OnComplete != nil {
-		segment.OnComplete(result.Text)
+		segment.OnComplete(result)
 	}
 }
 
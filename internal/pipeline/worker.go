@@ -151,7 +151,7 @@ func (w *Worker) processSegment(segment *AudioSegment) {
 
 			// Notify completion
 			if segment.OnComplete != nil {
-				segment.OnComplete(result.Text)
+				segment.OnComplete(result)
 			}
 			return
 		}
@@ -188,8 +188,10 @@ func (w *Worker) transcribeWithTimeout(ctx context.Context, segment *AudioSegmen
 	// Run transcription in goroutine
 	go func() {
 		opts := transcriber.TranscriptionOptions{
-			PreviousContext: segment.Context,
-			Language:        "auto",
+			PreviousContext:  segment.Context,
+			Language:         segment.Language,
+			CustomVocabulary: segment.Vocabulary,
+			EnableTimestamps: true,
 		}
 
 		result, err := w.transcriber.TranscribeWithContext(segment.Audio, opts)
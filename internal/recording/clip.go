@@ -0,0 +1,201 @@
+package recording
+
+import (
+	"bytes"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"layeh.com/gopus"
+)
+
+// errNoAudio is returned when a track has no packets in the clipped time range
+var errNoAudio = errors.New("no audio recorded in that time range")
+
+// Clip extracts the audio between from and to, measured from the start of the
+// recording, out of an Ogg Opus track into a standalone Ogg Opus stream. The
+// packets are copied as recorded, so the clip is rounded out to whole packets.
+func Clip(path string, from, to time.Duration) ([]byte, error) {
+	if to <= from {
+		return nil, errors.New("clip must end after it starts")
+	}
+
+	clip, err := clipPackets(path, from, to)
+	if err != nil {
+		return nil, err
+	}
+
+	var buf bytes.Buffer
+	writer, err := NewOpusWriter(&buf, clip.channels, clip.preSkip)
+	if err != nil {
+		return nil, err
+	}
+	for _, packet := range clip.packets {
+		if err := writer.WritePacket(packet); err != nil {
+			return nil, err
+		}
+	}
+	if err := writer.Close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// ClipTracks clips the same time range out of several tracks, such as those of
+// a speaker whose SSRC changed during the session, and mixes them into one
+// stream. A single track is clipped without re-encoding.
+func ClipTracks(paths []string, from, to time.Duration) ([]byte, error) {
+	if len(paths) == 1 {
+		return Clip(paths[0], from, to)
+	}
+	if to <= from {
+		return nil, errors.New("clip must end after it starts")
+	}
+
+	var clips []*trackClip
+	for _, path := range paths {
+		clip, err := clipPackets(path, from, to)
+		if errors.Is(err, errNoAudio) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		clips = append(clips, clip)
+	}
+	if len(clips) == 0 {
+		return nil, errNoAudio
+	}
+
+	// The mix starts with the earliest packet
+	start := clips[0].start
+	for _, clip := range clips {
+		start = min(start, clip.start)
+	}
+
+	var buf bytes.Buffer
+	mix, err := newStreamMixer(&buf)
+	if err != nil {
+		return nil, err
+	}
+	var end int64
+	for _, clip := range clips {
+		decoder, err := gopus.NewDecoder(SampleRate, channels)
+		if err != nil {
+			return nil, fmt.Errorf("error creating opus decoder: %w", err)
+		}
+		position := clip.start - start
+		for _, packet := range clip.packets {
+			samples := PacketSamples(packet)
+			pcm, err := decoder.Decode(packet, samples, false)
+			if err != nil {
+				return nil, fmt.Errorf("error decoding opus packet: %w", err)
+			}
+			mix.add(position, pcm)
+			position += int64(samples)
+		}
+		end = max(end, position)
+	}
+
+	if err := mix.flush(end); err != nil {
+		return nil, err
+	}
+	if err := mix.close(); err != nil {
+		return nil, err
+	}
+	return buf.Bytes(), nil
+}
+
+// trackClip is the packets of a track overlapping a time range
+type trackClip struct {
+	channels int
+	preSkip  uint16
+	start    int64 // Position of the first packet in samples since the recording started
+	packets  [][]byte
+}
+
+// clipPackets reads the packets overlapping a time range out of an Ogg Opus
+// track. Pages ending before the range are skipped without reading them, and
+// reading stops at the end of the range.
+func clipPackets(path string, from, to time.Duration) (*trackClip, error) {
+	file, err := os.Open(path) // #nosec G304 -- path comes from the session's recording
+	if err != nil {
+		return nil, fmt.Errorf("error opening recording: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	start := durationToSamples(from)
+	end := durationToSamples(to)
+
+	stream := &opusStream{}
+	clip := &trackClip{}
+	var partial []byte
+	var position int64 // Where the next packet starts, after removing the pre-skip
+
+	for position < end {
+		page, err := readOggPage(file)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, fmt.Errorf("error reading recording: %w", err)
+		}
+
+		// Decoded audio is delayed by the pre-skip, so positions are counted after removing it
+		if stream.headers >= 2 && page.endsOnPacket() && page.granule() >= 0 {
+			if pageEnd := page.granule() - int64(stream.preSkip); pageEnd <= start {
+				if _, err := file.Seek(int64(page.bodySize()), io.SeekCurrent); err != nil {
+					return nil, fmt.Errorf("error seeking recording: %w", err)
+				}
+				position = pageEnd
+				partial = nil
+				continue
+			}
+		}
+
+		if err := page.readBody(file); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, fmt.Errorf("error reading recording: %w", err)
+		}
+		partial, err = page.packets(partial, func(packet []byte) error {
+			if stream.headers < 2 {
+				if err := stream.readHeader(packet); err != nil {
+					return err
+				}
+				position = -int64(stream.preSkip)
+				return nil
+			}
+
+			samples := int64(PacketSamples(packet))
+			if position+samples > start && position < end {
+				if len(clip.packets) == 0 {
+					clip.start = position
+				}
+				clip.packets = append(clip.packets, packet)
+			}
+			position += samples
+			return nil
+		})
+		if err != nil {
+			return nil, fmt.Errorf("error reading recording: %w", err)
+		}
+	}
+
+	if stream.headers == 0 {
+		return nil, errors.New("error reading recording: missing OpusHead")
+	}
+	if len(clip.packets) == 0 {
+		return nil, errNoAudio
+	}
+	clip.channels = stream.channels
+	clip.preSkip = stream.preSkip
+	return clip, nil
+}
+
+// durationToSamples converts a duration into 48kHz samples
+func durationToSamples(d time.Duration) int64 {
+	return int64(d) * SampleRate / int64(time.Second)
+}
@@ -0,0 +1,88 @@
+package recording
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestClip(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "track.opus")
+	file, err := os.Create(path)
+	require.NoError(t, err)
+
+	// One second of silence, then 500ms of numbered packets
+	writer, err := NewOpusWriter(file, 2, 0)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSilence(SampleRate))
+	for i := range 25 {
+		require.NoError(t, writer.WritePacket([]byte{0xFC, byte(i)}))
+	}
+	// Left unclosed, as while the session is still recording
+	require.NoError(t, writer.Flush())
+	require.NoError(t, file.Close())
+
+	data, err := Clip(path, time.Second+100*time.Millisecond, time.Second+210*time.Millisecond)
+	require.NoError(t, err)
+
+	stream, err := readOpusStream(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, 2, stream.channels)
+	require.Len(t, stream.packets, 6)
+	assert.Equal(t, []byte{0xFC, 5}, stream.packets[0])
+	assert.Equal(t, []byte{0xFC, 10}, stream.packets[5])
+
+	_, err = Clip(path, 5*time.Second, 6*time.Second)
+	assert.Error(t, err)
+	_, err = Clip(path, time.Second, time.Second)
+	assert.Error(t, err)
+	_, err = Clip(filepath.Join(t.TempDir(), "missing.opus"), 0, time.Second)
+	assert.Error(t, err)
+}
+
+func TestClipTracksMergesSpeakerTracks(t *testing.T) {
+	tone := tonePacket(t)
+	writeTrack := func(name string, silence int64, packets int) string {
+		path := filepath.Join(t.TempDir(), name)
+		file, err := os.Create(path)
+		require.NoError(t, err)
+		writer, err := NewOpusWriter(file, 2, 0)
+		require.NoError(t, err)
+		require.NoError(t, writer.WriteSilence(silence))
+		for range packets {
+			require.NoError(t, writer.WritePacket(tone))
+		}
+		require.NoError(t, writer.Close())
+		require.NoError(t, file.Close())
+		return path
+	}
+
+	// The speaker's SSRC changed after 200ms of speech
+	first := writeTrack("ssrc-1.opus", SampleRate, 10)
+	second := writeTrack("ssrc-2.opus", SampleRate+10*silenceSamples, 10)
+
+	data, err := ClipTracks([]string{first, second}, time.Second, time.Second+400*time.Millisecond)
+	require.NoError(t, err)
+	stream, err := readOpusStream(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Equal(t, uint16(encoderLookahead), stream.preSkip)
+	require.Len(t, stream.packets, 20, "both tracks are in the clip")
+	assert.NotEqual(t, silencePacket, stream.packets[5])
+	assert.NotEqual(t, silencePacket, stream.packets[15])
+
+	// A single track is copied as recorded
+	data, err = ClipTracks([]string{first}, time.Second, time.Second+400*time.Millisecond)
+	require.NoError(t, err)
+	stream, err = readOpusStream(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, stream.packets, 10)
+	assert.Equal(t, tone, stream.packets[0])
+
+	_, err = ClipTracks([]string{first, second}, 3*time.Second, 4*time.Second)
+	assert.Error(t, err)
+}
@@ -0,0 +1,35 @@
+package recording
+
+import (
+	"fmt"
+	"io"
+
+	"layeh.com/gopus"
+)
+
+// DecodeOpus decodes an Ogg Opus stream into interleaved 48kHz samples with
+// the stream's channel count. The encoder delay given by the pre-skip is removed.
+func DecodeOpus(r io.Reader) (samples []int16, channels int, err error) {
+	stream, err := readOpusStream(r)
+	if err != nil {
+		return nil, 0, err
+	}
+	if stream.channels < 1 || stream.channels > 2 {
+		return nil, 0, fmt.Errorf("unsupported opus channel count %d", stream.channels)
+	}
+
+	decoder, err := gopus.NewDecoder(SampleRate, stream.channels)
+	if err != nil {
+		return nil, 0, fmt.Errorf("error creating opus decoder: %w", err)
+	}
+	for _, packet := range stream.packets {
+		pcm, err := decoder.Decode(packet, PacketSamples(packet), false)
+		if err != nil {
+			return nil, 0, fmt.Errorf("error decoding opus packet: %w", err)
+		}
+		samples = append(samples, pcm...)
+	}
+
+	skip := min(int(stream.preSkip)*stream.channels, len(samples))
+	return samples[skip:], stream.channels, nil
+}
@@ -0,0 +1,114 @@
+package recording
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"os"
+
+	"layeh.com/gopus"
+)
+
+// mixer sums the decoded audio of every speaker and encodes it into one track.
+// Frames are held back for mixLatency so speakers' packets can arrive out of step.
+type mixer struct {
+	file    *os.File // nil if the mix isn't written to a file
+	writer  *OpusWriter
+	encoder *gopus.Encoder
+	pending []int32 // Interleaved samples starting where writer ends
+}
+
+func newMixer(path string) (*mixer, error) {
+	file, err := os.Create(path) // #nosec G304 -- path is inside the recording directory
+	if err != nil {
+		return nil, fmt.Errorf("error creating mix file: %w", err)
+	}
+	m, err := newStreamMixer(file)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	m.file = file
+	return m, nil
+}
+
+// newStreamMixer creates a mixer encoding into w
+func newStreamMixer(w io.Writer) (*mixer, error) {
+	writer, err := NewOpusWriter(w, channels, encoderLookahead)
+	if err != nil {
+		return nil, err
+	}
+	encoder, err := gopus.NewEncoder(SampleRate, channels, gopus.Audio)
+	if err != nil {
+		return nil, fmt.Errorf("error creating opus encoder: %w", err)
+	}
+	return &mixer{writer: writer, encoder: encoder}, nil
+}
+
+// add mixes interleaved PCM in at a position in samples. Audio arriving after
+// its frame was already encoded is dropped.
+func (m *mixer) add(position int64, pcm []int16) {
+	offset := int(position-m.writer.Samples()) * channels
+	if offset < 0 {
+		if -offset >= len(pcm) {
+			return
+		}
+		pcm = pcm[-offset:]
+		offset = 0
+	}
+
+	if need := offset + len(pcm); need > len(m.pending) {
+		m.pending = append(m.pending, make([]int32, need-len(m.pending))...)
+	}
+	for i, sample := range pcm {
+		m.pending[offset+i] += int32(sample)
+	}
+}
+
+// flush encodes every complete frame before position. Frames nobody spoke in
+// are written as silence packets.
+func (m *mixer) flush(position int64) error {
+	frame := make([]int16, silenceSamples*channels)
+	for m.writer.Samples()+silenceSamples <= position {
+		silent := true
+		for i := range frame {
+			var sample int32
+			if i < len(m.pending) {
+				sample = m.pending[i]
+			}
+			frame[i] = int16(max(min(sample, math.MaxInt16), math.MinInt16)) // #nosec G115 -- clamped to int16 range
+			if frame[i] != 0 {
+				silent = false
+			}
+		}
+		m.pending = m.pending[min(len(frame), len(m.pending)):]
+
+		if silent {
+			if err := m.writer.WritePacket(silencePacket); err != nil {
+				return err
+			}
+			continue
+		}
+
+		packet, err := m.encoder.Encode(frame, silenceSamples, maxEncodedFrameSize)
+		if err != nil {
+			return fmt.Errorf("error encoding mix: %w", err)
+		}
+		if err := m.writer.WritePacket(packet); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// close ends the mix stream and its file
+func (m *mixer) close() error {
+	err := m.writer.Close()
+	if m.file == nil {
+		return err
+	}
+	if closeErr := m.file.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
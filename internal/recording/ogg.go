@@ -0,0 +1,393 @@
+package recording
+
+import (
+	"bytes"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+)
+
+const (
+	// SampleRate is the rate Ogg Opus granule positions count in
+	SampleRate = 48000
+
+	// Ogg page header layout (RFC 3533)
+	pageHeaderSize  = 27
+	maxPageSegments = 255
+	packetsPerPage  = 50 // About a second of 20ms packets
+	headerContinued = 0x01
+	headerFirstPage = 0x02
+	headerLastPage  = 0x04
+	crcOffset       = 22
+	segmentsOffset  = 26
+	opusHeadSize    = 19
+	opusHeadVersion = 1
+	defaultStreamID = 0x4f505553 // "OPUS"
+)
+
+// silencePacket is a 20ms Opus frame that decodes to silence, the same one Discord sends
+var silencePacket = []byte{0xF8, 0xFF, 0xFE}
+
+// silenceSamples is the length of silencePacket at 48kHz
+const silenceSamples = 960
+
+var oggCRCTable = func() [256]uint32 {
+	var table [256]uint32
+	for i := range table {
+		r := uint32(i) << 24
+		for range 8 {
+			if r&0x80000000 != 0 {
+				r = r<<1 ^ 0x04c11db7
+			} else {
+				r <<= 1
+			}
+		}
+		table[i] = r
+	}
+	return table
+}()
+
+// oggCRC computes the checksum of an Ogg page
+func oggCRC(data []byte) uint32 {
+	var crc uint32
+	for _, b := range data {
+		crc = crc<<8 ^ oggCRCTable[byte(crc>>24)^b]
+	}
+	return crc
+}
+
+// OpusWriter writes Opus packets into an Ogg Opus stream (RFC 7845) without re-encoding
+type OpusWriter struct {
+	w        io.Writer
+	preSkip  uint16
+	sequence uint32
+	samples  int64 // Audio written so far, excluding pre-skip
+	packets  [][]byte
+	closed   bool
+}
+
+// NewOpusWriter writes the Ogg Opus headers for a stream with the given channel
+// count and pre-skip, and returns a writer for its audio packets
+func NewOpusWriter(w io.Writer, channels int, preSkip uint16) (*OpusWriter, error) {
+	if channels < 1 || channels > 2 {
+		return nil, fmt.Errorf("unsupported channel count %d", channels)
+	}
+
+	ow := &OpusWriter{w: w, preSkip: preSkip}
+
+	head := make([]byte, opusHeadSize)
+	copy(head, "OpusHead")
+	head[8] = opusHeadVersion
+	head[9] = byte(channels)
+	binary.LittleEndian.PutUint16(head[10:], preSkip)
+	binary.LittleEndian.PutUint32(head[12:], SampleRate)
+	if err := ow.writePage([][]byte{head}, 0, headerFirstPage); err != nil {
+		return nil, err
+	}
+
+	vendor := "discord-voice-mcp"
+	tags := make([]byte, 8+4+len(vendor)+4)
+	copy(tags, "OpusTags")
+	binary.LittleEndian.PutUint32(tags[8:], uint32(len(vendor))) // #nosec G115 -- constant length
+	copy(tags[12:], vendor)
+	if err := ow.writePage([][]byte{tags}, 0, 0); err != nil {
+		return nil, err
+	}
+
+	return ow, nil
+}
+
+// WritePacket appends one Opus packet. Packets are buffered into pages of about a second.
+func (ow *OpusWriter) WritePacket(packet []byte) error {
+	if ow.closed {
+		return errors.New("opus writer is closed")
+	}
+	samples := PacketSamples(packet)
+	if samples == 0 {
+		return fmt.Errorf("invalid opus packet of %d bytes", len(packet))
+	}
+
+	if ow.bufferedSegments()+len(packet)/255+1 > maxPageSegments {
+		if err := ow.Flush(); err != nil {
+			return err
+		}
+	}
+
+	ow.packets = append(ow.packets, append([]byte(nil), packet...))
+	ow.samples += int64(samples)
+
+	if len(ow.packets) >= packetsPerPage {
+		return ow.Flush()
+	}
+	return nil
+}
+
+// WriteSilence appends silence packets until at least samples more have been written
+func (ow *OpusWriter) WriteSilence(samples int64) error {
+	for ; samples > 0; samples -= silenceSamples {
+		if err := ow.WritePacket(silencePacket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Samples returns how many samples of audio have been written
+func (ow *OpusWriter) Samples() int64 {
+	return ow.samples
+}
+
+// Flush writes the buffered packets out as a page
+func (ow *OpusWriter) Flush() error {
+	if len(ow.packets) == 0 {
+		return nil
+	}
+	err := ow.writePage(ow.packets, ow.samples+int64(ow.preSkip), 0)
+	ow.packets = ow.packets[:0]
+	return err
+}
+
+// Close writes the remaining packets and marks the end of the stream
+func (ow *OpusWriter) Close() error {
+	if ow.closed {
+		return nil
+	}
+	ow.closed = true
+	err := ow.writePage(ow.packets, ow.samples+int64(ow.preSkip), headerLastPage)
+	ow.packets = nil
+	return err
+}
+
+func (ow *OpusWriter) bufferedSegments() int {
+	segments := 0
+	for _, packet := range ow.packets {
+		segments += len(packet)/255 + 1
+	}
+	return segments
+}
+
+// writePage writes whole packets as a single Ogg page
+func (ow *OpusWriter) writePage(packets [][]byte, granule int64, flags byte) error {
+	var lacing []byte
+	size := 0
+	for _, packet := range packets {
+		for n := len(packet); ; n -= 255 {
+			if n < 255 {
+				lacing = append(lacing, byte(n))
+				break
+			}
+			lacing = append(lacing, 255)
+		}
+		size += len(packet)
+	}
+
+	page := make([]byte, pageHeaderSize, pageHeaderSize+len(lacing)+size)
+	copy(page, "OggS")
+	page[5] = flags
+	binary.LittleEndian.PutUint64(page[6:], uint64(granule)) // #nosec G115 -- granule positions are never negative
+	binary.LittleEndian.PutUint32(page[14:], defaultStreamID)
+	binary.LittleEndian.PutUint32(page[18:], ow.sequence)
+	page[segmentsOffset] = byte(len(lacing))
+	page = append(page, lacing...)
+	for _, packet := range packets {
+		page = append(page, packet...)
+	}
+	binary.LittleEndian.PutUint32(page[crcOffset:], oggCRC(page))
+
+	ow.sequence++
+	if _, err := ow.w.Write(page); err != nil {
+		return fmt.Errorf("error writing ogg page: %w", err)
+	}
+	return nil
+}
+
+// PacketSamples returns the number of 48kHz samples an Opus packet decodes to,
+// or 0 if the packet is malformed (RFC 6716 section 3.1)
+func PacketSamples(packet []byte) int {
+	if len(packet) == 0 {
+		return 0
+	}
+	toc := packet[0]
+	config := int(toc >> 3)
+
+	var frameSamples int
+	switch {
+	case config < 12: // SILK: 10, 20, 40, 60 ms
+		frameSamples = []int{480, 960, 1920, 2880}[config%4]
+	case config < 16: // Hybrid: 10, 20 ms
+		frameSamples = []int{480, 960}[config%2]
+	default: // CELT: 2.5, 5, 10, 20 ms
+		frameSamples = []int{120, 240, 480, 960}[config%4]
+	}
+
+	var frames int
+	switch toc & 0x03 {
+	case 0:
+		frames = 1
+	case 1, 2:
+		frames = 2
+	default:
+		if len(packet) < 2 {
+			return 0
+		}
+		frames = int(packet[1] & 0x3f)
+	}
+	return frames * frameSamples
+}
+
+// opusStream is a parsed Ogg Opus stream
+type opusStream struct {
+	channels int
+	preSkip  uint16
+	headers  int // Header packets read so far
+	packets  [][]byte
+}
+
+// readOpusStream parses an Ogg Opus stream. A truncated final page, as left by a
+// recording still being written, ends the stream rather than failing it.
+func readOpusStream(r io.Reader) (*opusStream, error) {
+	stream := &opusStream{}
+	var partial []byte
+
+	for {
+		page, err := readOggPage(r)
+		if errors.Is(err, io.EOF) {
+			break
+		}
+		if err != nil {
+			return nil, err
+		}
+		if err := page.readBody(r); errors.Is(err, io.EOF) {
+			break
+		} else if err != nil {
+			return nil, err
+		}
+
+		partial, err = page.packets(partial, func(packet []byte) error {
+			if stream.headers < 2 {
+				return stream.readHeader(packet)
+			}
+			stream.packets = append(stream.packets, packet)
+			return nil
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if stream.headers == 0 {
+		return nil, errors.New("missing OpusHead")
+	}
+	return stream, nil
+}
+
+// readHeader parses one of the two header packets that start the stream
+func (s *opusStream) readHeader(packet []byte) error {
+	if s.headers == 0 {
+		if len(packet) < opusHeadSize || !bytes.Equal(packet[:8], []byte("OpusHead")) {
+			return errors.New("missing OpusHead")
+		}
+		s.channels = int(packet[9])
+		s.preSkip = binary.LittleEndian.Uint16(packet[10:])
+	}
+	s.headers++ // The second is OpusTags
+	return nil
+}
+
+// oggPage is one page of an Ogg stream. The body is only read on request, so
+// pages can be skipped without loading them.
+type oggPage struct {
+	header []byte // Fixed header followed by the segment table
+	body   []byte
+}
+
+// readOggPage reads the header and segment table of the next page. io.EOF
+// means the stream ended, possibly in the middle of the page.
+func readOggPage(r io.Reader) (*oggPage, error) {
+	header := make([]byte, pageHeaderSize)
+	if _, err := io.ReadFull(r, header); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return nil, io.EOF
+		}
+		return nil, fmt.Errorf("error reading ogg page: %w", err)
+	}
+	if !bytes.Equal(header[:4], []byte("OggS")) {
+		return nil, errors.New("not an ogg stream")
+	}
+
+	lacing := make([]byte, header[segmentsOffset])
+	if _, err := io.ReadFull(r, lacing); err != nil {
+		return nil, io.EOF
+	}
+	return &oggPage{header: append(header, lacing...)}, nil
+}
+
+// lacing returns the page's segment table
+func (p *oggPage) lacing() []byte {
+	return p.header[pageHeaderSize:]
+}
+
+// bodySize returns the length of the page's body
+func (p *oggPage) bodySize() int {
+	size := 0
+	for _, l := range p.lacing() {
+		size += int(l)
+	}
+	return size
+}
+
+// granule returns the granule position at the end of the last packet completed on the page
+func (p *oggPage) granule() int64 {
+	return int64(binary.LittleEndian.Uint64(p.header[6:])) // #nosec G115 -- -1 marks pages without a completed packet
+}
+
+// endsOnPacket reports whether the page's last packet is complete rather than
+// continued on the next page
+func (p *oggPage) endsOnPacket() bool {
+	lacing := p.lacing()
+	return len(lacing) > 0 && lacing[len(lacing)-1] < 255
+}
+
+// readBody reads the page's body and verifies its checksum. io.EOF means the
+// stream ended in the middle of the page.
+func (p *oggPage) readBody(r io.Reader) error {
+	body := make([]byte, p.bodySize())
+	if _, err := io.ReadFull(r, body); err != nil {
+		if errors.Is(err, io.EOF) || errors.Is(err, io.ErrUnexpectedEOF) {
+			return io.EOF
+		}
+		return fmt.Errorf("error reading ogg page: %w", err)
+	}
+
+	page := append(append([]byte(nil), p.header...), body...)
+	want := binary.LittleEndian.Uint32(page[crcOffset:])
+	binary.LittleEndian.PutUint32(page[crcOffset:], 0)
+	if oggCRC(page) != want {
+		return errors.New("ogg page checksum mismatch")
+	}
+	p.body = body
+	return nil
+}
+
+// packets calls fn with every packet completed on the page. partial is the
+// start of a packet continued from the previous page; the start of one
+// continued on the next page is returned.
+func (p *oggPage) packets(partial []byte, fn func(packet []byte) error) ([]byte, error) {
+	if p.header[5]&headerContinued == 0 {
+		partial = nil
+	}
+	offset := 0
+	for _, l := range p.lacing() {
+		partial = append(partial, p.body[offset:offset+int(l)]...)
+		offset += int(l)
+		if l == 255 {
+			continue
+		}
+		if err := fn(partial); err != nil {
+			return nil, err
+		}
+		partial = nil
+	}
+	return partial, nil
+}
@@ -0,0 +1,83 @@
+package recording
+
+import (
+	"bytes"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestPacketSamples(t *testing.T) {
+	tests := map[string]struct {
+		packet   []byte
+		expected int
+	}{
+		"celt 20ms":          {silencePacket, 960},
+		"silk 60ms":          {[]byte{0x18}, 2880},
+		"hybrid 10ms":        {[]byte{0x60}, 480},
+		"two frames":         {[]byte{0xF9, 0x00}, 1920},
+		"arbitrary frames":   {[]byte{0xFB, 0x03}, 2880},
+		"missing frame byte": {[]byte{0xFB}, 0},
+		"empty":              {nil, 0},
+	}
+	for name, tt := range tests {
+		assert.Equal(t, tt.expected, PacketSamples(tt.packet), name)
+	}
+}
+
+func TestOpusWriterRoundTrip(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewOpusWriter(&buf, 2, 312)
+	require.NoError(t, err)
+
+	large := append([]byte{0xFC}, bytes.Repeat([]byte{0xAB}, 600)...)
+	for range 120 {
+		require.NoError(t, writer.WritePacket(silencePacket))
+	}
+	require.NoError(t, writer.WritePacket(large))
+	require.NoError(t, writer.WriteSilence(2000))
+	assert.Equal(t, int64(124*960), writer.Samples())
+	require.NoError(t, writer.Close())
+
+	stream, err := readOpusStream(&buf)
+	require.NoError(t, err)
+	assert.Equal(t, 2, stream.channels)
+	assert.Equal(t, uint16(312), stream.preSkip)
+	require.Len(t, stream.packets, 124)
+	assert.Equal(t, large, stream.packets[120])
+	assert.Equal(t, silencePacket, stream.packets[123])
+}
+
+func TestOpusWriterRejectsInvalidPackets(t *testing.T) {
+	writer, err := NewOpusWriter(&bytes.Buffer{}, 1, 0)
+	require.NoError(t, err)
+	assert.Error(t, writer.WritePacket(nil))
+
+	_, err = NewOpusWriter(&bytes.Buffer{}, 3, 0)
+	assert.Error(t, err)
+}
+
+func TestReadOpusStreamTruncated(t *testing.T) {
+	var buf bytes.Buffer
+	writer, err := NewOpusWriter(&buf, 2, 0)
+	require.NoError(t, err)
+	require.NoError(t, writer.WriteSilence(int64(packetsPerPage*silenceSamples)))
+	complete := buf.Len()
+	require.NoError(t, writer.WriteSilence(10*silenceSamples))
+	require.NoError(t, writer.Close())
+
+	// A recording still being written may end mid-page
+	stream, err := readOpusStream(bytes.NewReader(buf.Bytes()[:complete+10]))
+	require.NoError(t, err)
+	assert.Len(t, stream.packets, packetsPerPage)
+
+	// Corrupted pages are rejected
+	corrupted := append([]byte(nil), buf.Bytes()...)
+	corrupted[complete-1] ^= 0xFF
+	_, err = readOpusStream(bytes.NewReader(corrupted))
+	assert.Error(t, err)
+
+	_, err = readOpusStream(bytes.NewReader([]byte("not ogg at all, just text")))
+	assert.Error(t, err)
+}
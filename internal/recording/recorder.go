@@ -0,0 +1,446 @@
+package recording
+
+import (
+	"fmt"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/sirupsen/logrus"
+	"layeh.com/gopus"
+)
+
+const (
+	// DefaultMaxAge is how long finished recordings are kept unless configured otherwise
+	DefaultMaxAge = 7 * 24 * time.Hour
+
+	// MixFile is the name of the mixed-down track in a session's recording directory
+	MixFile = "mix.opus"
+
+	// Discord sends stereo Opus
+	channels = 2
+
+	// jitterSamples is how far a track may fall behind the wall clock before the
+	// gap is filled with silence, so network jitter doesn't shift the timeline
+	jitterSamples = 3 * silenceSamples
+
+	// mixLatency is how long the mix waits for other speakers' packets before a frame is encoded
+	mixLatency = 10 * silenceSamples
+
+	// encoderLookahead is the libopus encoder delay at 48kHz, skipped by decoders of the mix
+	encoderLookahead = 312
+
+	maxEncodedFrameSize = 4000
+)
+
+// Config controls where recordings are kept and for how long
+type Config struct {
+	Dir      string        // Each session is recorded into a subdirectory named after it
+	MaxAge   time.Duration // Finished recordings older than this are deleted, 0 keeps them
+	MaxBytes int64         // Oldest finished recordings are deleted beyond this total, 0 for no limit
+}
+
+// ConfigFromEnv returns a configuration for recording into dir with retention
+// limits from RECORDING_RETENTION (a duration, 0 to keep everything) and
+// RECORDING_MAX_SIZE_MB (0 for no limit)
+func ConfigFromEnv(dir string) (Config, error) {
+	config := Config{Dir: dir, MaxAge: DefaultMaxAge}
+
+	if value := os.Getenv("RECORDING_RETENTION"); value != "" {
+		maxAge, err := time.ParseDuration(value)
+		if err != nil || maxAge < 0 {
+			return Config{}, fmt.Errorf("invalid RECORDING_RETENTION %q: use a duration such as 72h, or 0 to keep recordings", value)
+		}
+		config.MaxAge = maxAge
+	}
+	if value := os.Getenv("RECORDING_MAX_SIZE_MB"); value != "" {
+		megabytes, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || megabytes < 0 {
+			return Config{}, fmt.Errorf("invalid RECORDING_MAX_SIZE_MB %q: must be a positive number", value)
+		}
+		config.MaxBytes = megabytes << 20
+	}
+
+	return config, nil
+}
+
+// Recorder writes the audio of voice sessions to disk and enforces retention limits
+type Recorder struct {
+	config   Config
+	active   map[string]*Recording // Sessions currently being recorded, never pruned
+	onPruned func(sessionID string)
+	mu       sync.Mutex
+}
+
+// NewRecorder creates a recorder, creating its directory if needed
+func NewRecorder(config Config) (*Recorder, error) {
+	if err := os.MkdirAll(config.Dir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating recording directory: %w", err)
+	}
+	return &Recorder{
+		config: config,
+		active: make(map[string]*Recording),
+	}, nil
+}
+
+// OnPruned registers a function called with the session of every recording
+// deleted by the retention limits, so references to it can be dropped
+func (r *Recorder) OnPruned(fn func(sessionID string)) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.onPruned = fn
+}
+
+// Flush writes out the audio a session's recording is still buffering, so its
+// files hold everything received so far. Does nothing if the session isn't being recorded.
+func (r *Recorder) Flush(sessionID string) error {
+	r.mu.Lock()
+	rec := r.active[sessionID]
+	r.mu.Unlock()
+	if rec == nil {
+		return nil
+	}
+	return rec.Flush()
+}
+
+// Start begins recording a session. update is called with the recording's
+// location whenever a track is added or its speaker is identified.
+func (r *Recorder) Start(sessionID string, update func(session.Recording)) (*Recording, error) {
+	rec, err := r.start(sessionID, update)
+	if err != nil {
+		return nil, err
+	}
+	r.prune()
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"dir":        rec.dir,
+	}).Info("Recording session audio")
+
+	return rec, nil
+}
+
+// start creates the recording of a session and marks it active
+func (r *Recorder) start(sessionID string, update func(session.Recording)) (*Recording, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if r.active[sessionID] != nil {
+		return nil, fmt.Errorf("session %s is already being recorded", sessionID)
+	}
+
+	dir := filepath.Join(r.config.Dir, sessionID)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return nil, fmt.Errorf("error creating session recording directory: %w", err)
+	}
+
+	mix, err := newMixer(filepath.Join(dir, MixFile))
+	if err != nil {
+		return nil, err
+	}
+
+	rec := &Recording{
+		recorder:  r,
+		sessionID: sessionID,
+		dir:       dir,
+		start:     time.Now(),
+		update:    update,
+		tracks:    make(map[uint32]*track),
+		mix:       mix,
+	}
+	r.active[sessionID] = rec
+	rec.notifyLocked()
+	return rec, nil
+}
+
+// finish marks a session's recording as complete and applies the retention limits
+func (r *Recorder) finish(sessionID string) {
+	r.mu.Lock()
+	delete(r.active, sessionID)
+	r.mu.Unlock()
+
+	r.prune()
+}
+
+// prune applies the retention limits and reports the deleted recordings
+func (r *Recorder) prune() {
+	r.mu.Lock()
+	pruned := r.pruneLocked()
+	onPruned := r.onPruned
+	r.mu.Unlock()
+
+	if onPruned == nil {
+		return
+	}
+	for _, sessionID := range pruned {
+		onPruned(sessionID)
+	}
+}
+
+// pruneLocked deletes finished recordings beyond the age and size limits,
+// oldest first, and returns their sessions. Caller must hold r.mu.
+func (r *Recorder) pruneLocked() []string {
+	if r.config.MaxAge <= 0 && r.config.MaxBytes <= 0 {
+		return nil
+	}
+
+	entries, err := os.ReadDir(r.config.Dir)
+	if err != nil {
+		logrus.WithError(err).Warn("Failed to list recordings for retention")
+		return nil
+	}
+
+	type stored struct {
+		sessionID string
+		size      int64
+		modified  time.Time
+	}
+	var finished []stored
+	var total int64
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+		size, modified := dirUsage(filepath.Join(r.config.Dir, entry.Name()))
+		total += size
+		if r.active[entry.Name()] == nil {
+			finished = append(finished, stored{entry.Name(), size, modified})
+		}
+	}
+	sort.Slice(finished, func(i, j int) bool {
+		return finished[i].modified.Before(finished[j].modified)
+	})
+
+	var pruned []string
+	for _, rec := range finished {
+		expired := r.config.MaxAge > 0 && time.Since(rec.modified) > r.config.MaxAge
+		oversized := r.config.MaxBytes > 0 && total > r.config.MaxBytes
+		if !expired && !oversized {
+			continue
+		}
+		if err := os.RemoveAll(filepath.Join(r.config.Dir, rec.sessionID)); err != nil {
+			logrus.WithError(err).WithField("session_id", rec.sessionID).Warn("Failed to delete expired recording")
+			continue
+		}
+		total -= rec.size
+		pruned = append(pruned, rec.sessionID)
+		logrus.WithFields(logrus.Fields{
+			"session_id": rec.sessionID,
+			"expired":    expired,
+			"bytes":      rec.size,
+		}).Info("Deleted recording beyond retention limits")
+	}
+	return pruned
+}
+
+// dirUsage returns the total size and newest modification time of the files in a directory
+func dirUsage(dir string) (size int64, modified time.Time) {
+	_ = filepath.WalkDir(dir, func(_ string, d fs.DirEntry, err error) error {
+		if err != nil || d.IsDir() {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		size += info.Size()
+		if info.ModTime().After(modified) {
+			modified = info.ModTime()
+		}
+		return nil
+	})
+	return size, modified
+}
+
+// Recording captures one session: an Ogg Opus track per speaker holding the
+// packets as received, and a mixed-down track of everyone. All tracks start
+// when the recording started and gaps are filled with silence.
+type Recording struct {
+	recorder  *Recorder
+	sessionID string
+	dir       string
+	start     time.Time
+	update    func(session.Recording)
+	tracks    map[uint32]*track
+	mix       *mixer
+	closed    bool
+	mu        sync.Mutex
+}
+
+// track is the audio received from one SSRC
+type track struct {
+	info    session.RecordingTrack
+	file    *os.File
+	writer  *OpusWriter
+	decoder *gopus.Decoder
+}
+
+// WritePacket records an Opus packet received from a speaker at the given time
+func (rec *Recording) WritePacket(ssrc uint32, userID, username string, packet []byte, received time.Time) error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed {
+		return fmt.Errorf("recording of session %s is closed", rec.sessionID)
+	}
+	if PacketSamples(packet) == 0 {
+		return fmt.Errorf("invalid opus packet of %d bytes", len(packet))
+	}
+
+	t, err := rec.trackLocked(ssrc, userID, username)
+	if err != nil {
+		return err
+	}
+
+	now := rec.position(received)
+	if gap := now - t.writer.Samples(); gap > jitterSamples {
+		if err := t.writer.WriteSilence(gap); err != nil {
+			return err
+		}
+	}
+
+	position := t.writer.Samples()
+	if err := t.writer.WritePacket(packet); err != nil {
+		return err
+	}
+
+	pcm, err := t.decoder.Decode(packet, PacketSamples(packet), false)
+	if err != nil {
+		logrus.WithError(err).WithField("ssrc", ssrc).Debug("Skipping undecodable packet in mix")
+	} else {
+		rec.mix.add(position, pcm)
+	}
+	return rec.mix.flush(now - mixLatency)
+}
+
+// trackLocked returns the track of an SSRC, creating it on first use and
+// updating its speaker once identified. Caller must hold rec.mu.
+func (rec *Recording) trackLocked(ssrc uint32, userID, username string) (*track, error) {
+	t, exists := rec.tracks[ssrc]
+	if exists {
+		if t.info.UserID != userID || t.info.Username != username {
+			t.info.UserID, t.info.Username = userID, username
+			rec.notifyLocked()
+		}
+		return t, nil
+	}
+
+	name := fmt.Sprintf("ssrc-%d.opus", ssrc)
+	file, err := os.Create(filepath.Join(rec.dir, name)) // #nosec G304 -- path is built from the recording directory and a numeric SSRC
+	if err != nil {
+		return nil, fmt.Errorf("error creating track file: %w", err)
+	}
+	writer, err := NewOpusWriter(file, channels, 0)
+	if err != nil {
+		_ = file.Close()
+		return nil, err
+	}
+	decoder, err := gopus.NewDecoder(SampleRate, channels)
+	if err != nil {
+		_ = file.Close()
+		return nil, fmt.Errorf("error creating opus decoder: %w", err)
+	}
+
+	t = &track{
+		info: session.RecordingTrack{
+			SSRC:     ssrc,
+			UserID:   userID,
+			Username: username,
+			File:     name,
+		},
+		file:    file,
+		writer:  writer,
+		decoder: decoder,
+	}
+	rec.tracks[ssrc] = t
+	rec.notifyLocked()
+	return t, nil
+}
+
+// position converts a time into samples since the recording started, rounded down to a 20ms frame
+func (rec *Recording) position(at time.Time) int64 {
+	elapsed := max(at.Sub(rec.start), 0)
+	samples := durationToSamples(elapsed)
+	return samples - samples%silenceSamples
+}
+
+// notifyLocked reports the recording's current layout. Caller must hold rec.mu.
+func (rec *Recording) notifyLocked() {
+	if rec.update == nil {
+		return
+	}
+
+	info := session.Recording{
+		Dir:   rec.dir,
+		Start: rec.start,
+		Mix:   MixFile,
+	}
+	for _, t := range rec.tracks {
+		info.Tracks = append(info.Tracks, t.info)
+	}
+	sort.Slice(info.Tracks, func(i, j int) bool {
+		return info.Tracks[i].File < info.Tracks[j].File
+	})
+	rec.update(info)
+}
+
+// Flush writes the packets every track and the mix are buffering out as pages,
+// so the files can be read while recording continues
+func (rec *Recording) Flush() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed {
+		return nil
+	}
+	for _, t := range rec.tracks {
+		if err := t.writer.Flush(); err != nil {
+			return err
+		}
+	}
+	return rec.mix.writer.Flush()
+}
+
+// Close writes out the remaining audio, closes every track and applies the
+// recorder's retention limits
+func (rec *Recording) Close() error {
+	rec.mu.Lock()
+	defer rec.mu.Unlock()
+
+	if rec.closed {
+		return nil
+	}
+	rec.closed = true
+
+	var end int64
+	for _, t := range rec.tracks {
+		end = max(end, t.writer.Samples())
+	}
+
+	var firstErr error
+	keep := func(err error) {
+		if err != nil && firstErr == nil {
+			firstErr = err
+		}
+	}
+	keep(rec.mix.flush(end))
+	keep(rec.mix.close())
+	for _, t := range rec.tracks {
+		keep(t.writer.Close())
+		keep(t.file.Close())
+	}
+
+	rec.recorder.finish(rec.sessionID)
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": rec.sessionID,
+		"tracks":     len(rec.tracks),
+	}).Info("Finished recording session audio")
+
+	return firstErr
+}
@@ -0,0 +1,183 @@
+package recording
+
+import (
+	"bytes"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/session"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"layeh.com/gopus"
+)
+
+// tonePacket encodes a 20ms stereo sine tone
+func tonePacket(t *testing.T) []byte {
+	t.Helper()
+	encoder, err := gopus.NewEncoder(SampleRate, channels, gopus.Audio)
+	require.NoError(t, err)
+
+	pcm := make([]int16, silenceSamples*channels)
+	for i := 0; i < silenceSamples; i++ {
+		sample := int16(8000 * math.Sin(2*math.Pi*440*float64(i)/SampleRate))
+		pcm[2*i], pcm[2*i+1] = sample, sample
+	}
+	packet, err := encoder.Encode(pcm, silenceSamples, maxEncodedFrameSize)
+	require.NoError(t, err)
+	return packet
+}
+
+func readTrack(t *testing.T, path string) *opusStream {
+	t.Helper()
+	file, err := os.Open(path)
+	require.NoError(t, err)
+	defer func() { _ = file.Close() }()
+
+	stream, err := readOpusStream(file)
+	require.NoError(t, err)
+	return stream
+}
+
+func TestRecordingTracksAndMix(t *testing.T) {
+	recorder, err := NewRecorder(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	var layouts []session.Recording
+	rec, err := recorder.Start("session-1", func(r session.Recording) {
+		layouts = append(layouts, r)
+	})
+	require.NoError(t, err)
+
+	tone := tonePacket(t)
+	at := func(offset time.Duration) time.Time { return rec.start.Add(offset) }
+
+	// Alice speaks a second in, Bob joins before being identified
+	require.NoError(t, rec.WritePacket(1, "alice", "Alice", tone, at(time.Second)))
+	require.NoError(t, rec.WritePacket(1, "alice", "Alice", tone, at(time.Second+20*time.Millisecond)))
+	require.NoError(t, rec.WritePacket(2, "2", "Unknown-2", tone, at(time.Second)))
+	require.NoError(t, rec.WritePacket(2, "bob", "Bob", tone, at(2*time.Second)))
+
+	// Jitter doesn't insert silence
+	require.NoError(t, rec.WritePacket(1, "alice", "Alice", tone, at(time.Second+80*time.Millisecond)))
+
+	require.NoError(t, rec.Close())
+	assert.Error(t, rec.WritePacket(1, "alice", "Alice", tone, at(3*time.Second)))
+
+	last := layouts[len(layouts)-1]
+	assert.Equal(t, filepath.Join(recorder.config.Dir, "session-1"), last.Dir)
+	assert.Equal(t, MixFile, last.Mix)
+	assert.Equal(t, []string{"ssrc-1.opus"}, last.TracksFor("alice"))
+	assert.Equal(t, []string{"ssrc-2.opus"}, last.TracksFor("bob"))
+	assert.Equal(t, "Bob", last.Tracks[1].Username)
+
+	// Tracks are aligned to the start of the recording with silence
+	alice := readTrack(t, filepath.Join(last.Dir, "ssrc-1.opus"))
+	require.Len(t, alice.packets, 50+3)
+	assert.Equal(t, silencePacket, alice.packets[49])
+	assert.Equal(t, tone, alice.packets[50])
+	assert.Equal(t, tone, alice.packets[52])
+
+	bob := readTrack(t, filepath.Join(last.Dir, "ssrc-2.opus"))
+	require.Len(t, bob.packets, 50+1+49+1)
+	assert.Equal(t, tone, bob.packets[100])
+
+	// The mix covers the longest track and carries audio where anyone spoke
+	mix := readTrack(t, filepath.Join(last.Dir, MixFile))
+	assert.Equal(t, uint16(encoderLookahead), mix.preSkip)
+	require.Len(t, mix.packets, 101)
+	assert.Equal(t, silencePacket, mix.packets[0])
+	assert.NotEqual(t, silencePacket, mix.packets[50])
+	assert.Equal(t, silencePacket, mix.packets[60])
+	assert.NotEqual(t, silencePacket, mix.packets[100])
+}
+
+func TestRecorderRejectsDuplicateSessions(t *testing.T) {
+	recorder, err := NewRecorder(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	rec, err := recorder.Start("session-1", nil)
+	require.NoError(t, err)
+	_, err = recorder.Start("session-1", nil)
+	assert.Error(t, err)
+
+	require.NoError(t, rec.Close())
+	rec, err = recorder.Start("session-1", nil)
+	require.NoError(t, err)
+	require.NoError(t, rec.Close())
+}
+
+func TestRecorderRetention(t *testing.T) {
+	dir := t.TempDir()
+	old := time.Now().Add(-48 * time.Hour)
+	for _, id := range []string{"expired", "older", "newer"} {
+		require.NoError(t, os.MkdirAll(filepath.Join(dir, id), 0750))
+		path := filepath.Join(dir, id, MixFile)
+		require.NoError(t, os.WriteFile(path, make([]byte, 1000), 0600))
+		if id == "expired" {
+			require.NoError(t, os.Chtimes(path, old, old))
+		}
+	}
+	older := time.Now().Add(-time.Hour)
+	require.NoError(t, os.Chtimes(filepath.Join(dir, "older", MixFile), older, older))
+
+	recorder, err := NewRecorder(Config{Dir: dir, MaxAge: 24 * time.Hour, MaxBytes: 1500})
+	require.NoError(t, err)
+	var pruned []string
+	recorder.OnPruned(func(sessionID string) { pruned = append(pruned, sessionID) })
+	rec, err := recorder.Start("active", nil)
+	require.NoError(t, err)
+
+	// The expired recording goes by age, the older one to fit the size limit
+	assert.NoDirExists(t, filepath.Join(dir, "expired"))
+	assert.NoDirExists(t, filepath.Join(dir, "older"))
+	assert.DirExists(t, filepath.Join(dir, "active"))
+	assert.ElementsMatch(t, []string{"expired", "older"}, pruned)
+	require.NoError(t, rec.Close())
+}
+
+func TestRecorderFlushesLiveRecording(t *testing.T) {
+	recorder, err := NewRecorder(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+	rec, err := recorder.Start("session-1", nil)
+	require.NoError(t, err)
+	defer func() { _ = rec.Close() }()
+
+	tone := tonePacket(t)
+	for i := range 5 {
+		require.NoError(t, rec.WritePacket(1, "alice", "Alice", tone, rec.start.Add(time.Duration(i)*20*time.Millisecond)))
+	}
+
+	// Less than a page is buffered until flushed
+	path := filepath.Join(rec.dir, "ssrc-1.opus")
+	_, err = Clip(path, 0, time.Second)
+	assert.Error(t, err)
+
+	require.NoError(t, recorder.Flush("session-1"))
+	data, err := Clip(path, 0, time.Second)
+	require.NoError(t, err)
+	stream, err := readOpusStream(bytes.NewReader(data))
+	require.NoError(t, err)
+	assert.Len(t, stream.packets, 5)
+
+	assert.NoError(t, recorder.Flush("not-recording"))
+}
+
+func TestConfigFromEnv(t *testing.T) {
+	config, err := ConfigFromEnv("recordings")
+	require.NoError(t, err)
+	assert.Equal(t, Config{Dir: "recordings", MaxAge: DefaultMaxAge}, config)
+
+	t.Setenv("RECORDING_RETENTION", "72h")
+	t.Setenv("RECORDING_MAX_SIZE_MB", "10")
+	config, err = ConfigFromEnv("recordings")
+	require.NoError(t, err)
+	assert.Equal(t, 72*time.Hour, config.MaxAge)
+	assert.Equal(t, int64(10<<20), config.MaxBytes)
+
+	t.Setenv("RECORDING_RETENTION", "a week")
+	_, err = ConfigFromEnv("recordings")
+	assert.Error(t, err)
+}
@@ -0,0 +1,183 @@
+package recording
+
+import (
+	"bufio"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"layeh.com/gopus"
+)
+
+const (
+	// SegmentsDir is the subdirectory of a session's recording holding the
+	// audio of every segment handed to the transcriber
+	SegmentsDir = "segments"
+
+	segmentIndex   = "index.jsonl"
+	bytesPerSample = 2
+)
+
+// Segment describes one stretch of a speaker's audio as it was transcribed live
+type Segment struct {
+	ID         string    `json:"id"`
+	UserID     string    `json:"userId"`
+	Username   string    `json:"username"`
+	Language   string    `json:"language,omitempty"`
+	AudioStart time.Time `json:"audioStart,omitzero"`
+	AudioEnd   time.Time `json:"audioEnd,omitzero"`
+	Samples    int       `json:"samples"` // Per channel, to trim the padding of the last frame
+}
+
+// Duration returns the length of the segment's audio
+func (s Segment) Duration() time.Duration {
+	return time.Duration(s.Samples) * time.Second / SampleRate
+}
+
+// SaveSegment keeps the 48kHz stereo PCM of a segment so the session can be
+// transcribed again later. The audio is stored Opus-encoded next to the recording.
+func (r *Recorder) SaveSegment(sessionID string, segment Segment, pcm []byte) error {
+	if segment.ID == "" || strings.ContainsAny(segment.ID, `/\`) || strings.Contains(segment.ID, "..") {
+		return fmt.Errorf("invalid segment id %q", segment.ID)
+	}
+
+	samples := make([]int16, len(pcm)/bytesPerSample)
+	for i := range samples {
+		// #nosec G115 -- reinterpreting little-endian PCM bytes as signed samples
+		samples[i] = int16(binary.LittleEndian.Uint16(pcm[i*bytesPerSample:]))
+	}
+	segment.Samples = len(samples) / channels
+	if segment.Samples == 0 {
+		return fmt.Errorf("segment %s has no audio", segment.ID)
+	}
+
+	dir := filepath.Join(r.config.Dir, sessionID, SegmentsDir)
+	if err := os.MkdirAll(dir, 0750); err != nil {
+		return fmt.Errorf("error creating segment directory: %w", err)
+	}
+	if err := encodeSegment(filepath.Join(dir, segment.ID+".opus"), samples); err != nil {
+		return err
+	}
+
+	line, err := json.Marshal(segment)
+	if err != nil {
+		return fmt.Errorf("error marshaling segment: %w", err)
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	index, err := os.OpenFile(filepath.Join(dir, segmentIndex), os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0600) // #nosec G304 -- path is inside the recording directory
+	if err != nil {
+		return fmt.Errorf("error opening segment index: %w", err)
+	}
+	if _, err := index.Write(append(line, '\n')); err != nil {
+		_ = index.Close()
+		return fmt.Errorf("error writing segment index: %w", err)
+	}
+	return index.Close()
+}
+
+// Segments lists the retained segments of a session in the order they were spoken
+func (r *Recorder) Segments(sessionID string) ([]Segment, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	file, err := os.Open(filepath.Join(r.config.Dir, sessionID, SegmentsDir, segmentIndex)) // #nosec G304 -- path is inside the recording directory
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("no audio retained for session %s", sessionID)
+		}
+		return nil, fmt.Errorf("error opening segment index: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	var segments []Segment
+	scanner := bufio.NewScanner(file)
+	for scanner.Scan() {
+		var segment Segment
+		if err := json.Unmarshal(scanner.Bytes(), &segment); err != nil {
+			// A crash can leave a partial last line
+			continue
+		}
+		segments = append(segments, segment)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("error reading segment index: %w", err)
+	}
+
+	sort.SliceStable(segments, func(i, j int) bool {
+		return segments[i].AudioStart.Before(segments[j].AudioStart)
+	})
+	return segments, nil
+}
+
+// SegmentAudio decodes a retained segment back into 48kHz stereo PCM
+func (r *Recorder) SegmentAudio(sessionID string, segment Segment) ([]byte, error) {
+	file, err := os.Open(filepath.Join(r.config.Dir, sessionID, SegmentsDir, filepath.Base(segment.ID)+".opus")) // #nosec G304 -- path is inside the recording directory
+	if err != nil {
+		return nil, fmt.Errorf("error opening segment audio: %w", err)
+	}
+	defer func() { _ = file.Close() }()
+
+	samples, _, err := DecodeOpus(file)
+	if err != nil {
+		return nil, fmt.Errorf("error reading segment audio: %w", err)
+	}
+	samples = samples[:min(segment.Samples*channels, len(samples))]
+
+	pcm := make([]byte, len(samples)*bytesPerSample)
+	for i, sample := range samples {
+		// #nosec G115 -- int16 to uint16 conversion is safe for audio samples
+		binary.LittleEndian.PutUint16(pcm[i*bytesPerSample:], uint16(sample))
+	}
+	return pcm, nil
+}
+
+// encodeSegment writes interleaved stereo samples to an Ogg Opus file. The
+// last frame is padded, including the encoder delay the pre-skip removes again.
+func encodeSegment(path string, samples []int16) error {
+	encoder, err := gopus.NewEncoder(SampleRate, channels, gopus.Audio)
+	if err != nil {
+		return fmt.Errorf("error creating opus encoder: %w", err)
+	}
+
+	file, err := os.Create(path) // #nosec G304 -- path is inside the recording directory
+	if err != nil {
+		return fmt.Errorf("error creating segment audio: %w", err)
+	}
+	writer, err := NewOpusWriter(file, channels, encoderLookahead)
+	if err != nil {
+		_ = file.Close()
+		return err
+	}
+
+	frameLen := silenceSamples * channels
+	padded := len(samples) + encoderLookahead*channels
+	for offset := 0; offset < padded; offset += frameLen {
+		frame := make([]int16, frameLen)
+		if offset < len(samples) {
+			copy(frame, samples[offset:])
+		}
+		packet, err := encoder.Encode(frame, silenceSamples, maxEncodedFrameSize)
+		if err != nil {
+			_ = file.Close()
+			return fmt.Errorf("error encoding segment audio: %w", err)
+		}
+		if err := writer.WritePacket(packet); err != nil {
+			_ = file.Close()
+			return err
+		}
+	}
+
+	if err := writer.Close(); err != nil {
+		_ = file.Close()
+		return err
+	}
+	return file.Close()
+}
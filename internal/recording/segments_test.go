@@ -0,0 +1,78 @@
+package recording
+
+import (
+	"encoding/binary"
+	"math"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// tonePCM returns stereo 16-bit little-endian PCM of a 440Hz sine tone
+func tonePCM(samples int) []byte {
+	pcm := make([]byte, samples*channels*bytesPerSample)
+	for i := 0; i < samples; i++ {
+		sample := uint16(int16(8000 * math.Sin(2*math.Pi*440*float64(i)/SampleRate)))
+		binary.LittleEndian.PutUint16(pcm[4*i:], sample)
+		binary.LittleEndian.PutUint16(pcm[4*i+2:], sample)
+	}
+	return pcm
+}
+
+func TestSaveSegmentRoundTrip(t *testing.T) {
+	dir := t.TempDir()
+	recorder, err := NewRecorder(Config{Dir: dir})
+	require.NoError(t, err)
+
+	start := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+	// Saved out of order; Segments returns them in the order they were spoken
+	require.NoError(t, recorder.SaveSegment("session-1", Segment{
+		ID: "second", UserID: "bob", Username: "Bob",
+		AudioStart: start.Add(2 * time.Second), AudioEnd: start.Add(3 * time.Second),
+	}, tonePCM(SampleRate/2)))
+	require.NoError(t, recorder.SaveSegment("session-1", Segment{
+		ID: "first", UserID: "alice", Username: "Alice", Language: "de",
+		AudioStart: start, AudioEnd: start.Add(time.Second),
+	}, tonePCM(SampleRate+100)))
+
+	segments, err := recorder.Segments("session-1")
+	require.NoError(t, err)
+	require.Len(t, segments, 2)
+	assert.Equal(t, "first", segments[0].ID)
+	assert.Equal(t, "de", segments[0].Language)
+	assert.Equal(t, SampleRate+100, segments[0].Samples)
+	assert.Equal(t, "second", segments[1].ID)
+	assert.Equal(t, 500*time.Millisecond, segments[1].Duration())
+
+	pcm, err := recorder.SegmentAudio("session-1", segments[0])
+	require.NoError(t, err)
+	assert.Len(t, pcm, (SampleRate+100)*channels*bytesPerSample, "padding and pre-skip are trimmed")
+
+	// Lossy, but the tone's energy survives
+	var energy float64
+	for i := 0; i+1 < len(pcm); i += bytesPerSample {
+		sample := float64(int16(binary.LittleEndian.Uint16(pcm[i:])))
+		energy += sample * sample
+	}
+	rms := math.Sqrt(energy / float64(len(pcm)/bytesPerSample))
+	assert.InDelta(t, 8000/math.Sqrt2, rms, 1500)
+
+	_, err = os.Stat(filepath.Join(dir, "session-1", SegmentsDir, "first.opus"))
+	assert.NoError(t, err)
+}
+
+func TestSaveSegmentRejectsBadInput(t *testing.T) {
+	recorder, err := NewRecorder(Config{Dir: t.TempDir()})
+	require.NoError(t, err)
+
+	assert.Error(t, recorder.SaveSegment("session-1", Segment{ID: "../escape"}, tonePCM(960)))
+	assert.Error(t, recorder.SaveSegment("session-1", Segment{ID: ""}, tonePCM(960)))
+	assert.Error(t, recorder.SaveSegment("session-1", Segment{ID: "empty"}, nil))
+
+	_, err = recorder.Segments("session-1")
+	assert.Error(t, err, "nothing was retained")
+}
@@ -0,0 +1,325 @@
+package session
+
+import (
+	"bytes"
+	"encoding/csv"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	// defaultExportDir is used until SetExportDir is called
+	defaultExportDir = "exports"
+
+	// defaultCueDuration is used for transcripts recorded without an audio duration
+	defaultCueDuration = 2 * time.Second
+)
+
+// ExportFormat selects how a session is rendered on export
+type ExportFormat string
+
+const (
+	FormatJSON     ExportFormat = "json"
+	FormatMarkdown ExportFormat = "markdown"
+	FormatSRT      ExportFormat = "srt"
+	FormatVTT      ExportFormat = "vtt"
+	FormatCSV      ExportFormat = "csv"
+	FormatText     ExportFormat = "txt"
+)
+
+// ExportFormats lists every supported export format
+var ExportFormats = []ExportFormat{FormatJSON, FormatMarkdown, FormatSRT, FormatVTT, FormatCSV, FormatText}
+
+// ParseExportFormat resolves a user-supplied format name. An empty name selects JSON.
+func ParseExportFormat(name string) (ExportFormat, error) {
+	switch strings.ToLower(strings.TrimSpace(name)) {
+	case "", "json":
+		return FormatJSON, nil
+	case "markdown", "md":
+		return FormatMarkdown, nil
+	case "srt":
+		return FormatSRT, nil
+	case "vtt", "webvtt":
+		return FormatVTT, nil
+	case "csv":
+		return FormatCSV, nil
+	case "txt", "text":
+		return FormatText, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q", name)
+	}
+}
+
+// Extension returns the file extension used for the format
+func (f ExportFormat) Extension() string {
+	if f == FormatMarkdown {
+		return "md"
+	}
+	return string(f)
+}
+
+// SetExportDir changes the directory export files are written to
+func (m *Manager) SetExportDir(dir string) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.exportDir = dir
+}
+
+// Render renders the current state of a session in the given format
+func (m *Manager) Render(sessionID string, format ExportFormat) ([]byte, error) {
+	session, err := m.Snapshot(sessionID)
+	if err != nil {
+		return nil, err
+	}
+	return RenderSession(session, format)
+}
+
+// ExportSessionAs renders a session in the given format and writes it to the export directory
+func (m *Manager) ExportSessionAs(sessionID string, format ExportFormat) (string, error) {
+	session, err := m.Snapshot(sessionID)
+	if err != nil {
+		return "", err
+	}
+
+	data, err := RenderSession(session, format)
+	if err != nil {
+		return "", err
+	}
+
+	m.mu.RLock()
+	exportDir := m.exportDir
+	m.mu.RUnlock()
+
+	// #nosec G301 - Export directory needs to be readable for serving files
+	if err := os.MkdirAll(exportDir, 0750); err != nil {
+		return "", fmt.Errorf("error creating export directory: %w", err)
+	}
+
+	// Generate filename
+	filename := fmt.Sprintf("session_%s_%s.%s", session.ID, session.StartTime.Format("20060102_150405"), format.Extension())
+	path := filepath.Join(exportDir, filename)
+
+	// #nosec G306 - Export files need to be readable by the user
+	if err := os.WriteFile(path, data, 0640); err != nil {
+		return "", fmt.Errorf("error writing file: %w", err)
+	}
+
+	return path, nil
+}
+
+// Snapshot returns a copy of a session that is safe to read while it is being
+// recorded. Its transcripts are sorted by when they were spoken.
+func (m *Manager) Snapshot(sessionID string) (*Session, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	copied := *session
+	copied.Transcripts = append([]Transcript(nil), session.Transcripts...)
+	SortBySpeechStart(copied.Transcripts)
+	copied.PendingTranscriptions = append([]PendingTranscription(nil), session.PendingTranscriptions...)
+	copied.Alternates = append([]Alternate(nil), session.Alternates...)
+	copied.Utterances = append([]Utterance(nil), session.Utterances...)
+	copied.UserLanguages = maps.Clone(session.UserLanguages)
+	return &copied, nil
+}
+
+// RenderSession renders a session in the given format
+func RenderSession(session *Session, format ExportFormat) ([]byte, error) {
+	switch format {
+	case FormatJSON:
+		data, err := json.MarshalIndent(session, "", "  ")
+		if err != nil {
+			return nil, fmt.Errorf("error marshaling session: %w", err)
+		}
+		return data, nil
+	case FormatMarkdown:
+		return renderMarkdown(session), nil
+	case FormatSRT:
+		return renderSRT(session), nil
+	case FormatVTT:
+		return renderVTT(session), nil
+	case FormatCSV:
+		return renderCSV(session)
+	case FormatText:
+		return renderText(session), nil
+	default:
+		return nil, fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// CueTiming returns a transcript's start and end offsets relative to the session start.
+// Transcripts with an AudioStart begin there and end at AudioEnd when it was captured;
+// otherwise the timestamp marks the end of the utterance and its audio duration gives the start.
+func CueTiming(session *Session, t Transcript) (start, end time.Duration) {
+	duration := secondsToDuration(t.Duration)
+	if duration <= 0 {
+		duration = defaultCueDuration
+	}
+
+	if !t.AudioStart.IsZero() {
+		start = max(t.AudioStart.Sub(session.StartTime), 0)
+		if t.AudioEnd.After(t.AudioStart) {
+			return start, start + t.AudioEnd.Sub(t.AudioStart)
+		}
+		return start, start + duration
+	}
+
+	end = t.Timestamp.Sub(session.StartTime)
+	if end < 0 {
+		end = 0
+	}
+	start = end - duration
+	if start < 0 {
+		start = 0
+	}
+	if end <= start {
+		end = start + duration
+	}
+	return start, end
+}
+
+func renderMarkdown(session *Session) []byte {
+	var b bytes.Buffer
+
+	fmt.Fprintf(&b, "# Voice Session %s\n\n", session.ID)
+	fmt.Fprintf(&b, "- **Guild:** %s\n", session.GuildID)
+	fmt.Fprintf(&b, "- **Channel:** %s\n", session.ChannelID)
+	fmt.Fprintf(&b, "- **Started:** %s\n", session.StartTime.Format("2006-01-02 15:04:05 MST"))
+	if session.EndTime != nil {
+		fmt.Fprintf(&b, "- **Ended:** %s\n", session.EndTime.Format("2006-01-02 15:04:05 MST"))
+	}
+	fmt.Fprintf(&b, "- **Duration:** %s\n", session.Duration().Round(time.Second))
+
+	if participants := participantNames(session); len(participants) > 0 {
+		fmt.Fprintf(&b, "- **Participants:** %s\n", strings.Join(participants, ", "))
+	}
+
+	b.WriteString("\n## Transcript\n\n")
+	if len(session.Transcripts) == 0 {
+		b.WriteString("_No transcripts recorded._\n")
+	}
+	for _, t := range session.Transcripts {
+		start, _ := CueTiming(session, t)
+		fmt.Fprintf(&b, "**[%s] %s:** %s\n\n", formatClock(start), t.Username, t.Text)
+	}
+
+	return b.Bytes()
+}
+
+func renderSRT(session *Session) []byte {
+	var b bytes.Buffer
+	for i, t := range session.Transcripts {
+		start, end := CueTiming(session, t)
+		fmt.Fprintf(&b, "%d\n%s --> %s\n%s: %s\n\n",
+			i+1, formatCueTime(start, ","), formatCueTime(end, ","), t.Username, t.Text)
+	}
+	return b.Bytes()
+}
+
+func renderVTT(session *Session) []byte {
+	var b bytes.Buffer
+	b.WriteString("WEBVTT\n\n")
+	for i, t := range session.Transcripts {
+		start, end := CueTiming(session, t)
+		fmt.Fprintf(&b, "%d\n%s --> %s\n<v %s>%s\n\n",
+			i+1, formatCueTime(start, "."), formatCueTime(end, "."), vttEscaper.Replace(t.Username), vttCueText(t, start))
+	}
+	return b.Bytes()
+}
+
+// vttEscaper escapes text for a WebVTT cue payload. Escaping > also rules out a
+// "-->" that would be read as cue timing, and a line break can't end the cue early.
+var vttEscaper = strings.NewReplacer("&", "&amp;", "<", "&lt;", ">", "&gt;", "\r", " ", "\n", " ")
+
+// vttCueText renders a cue's text. With word timings, each word after the first is
+// preceded by a WebVTT timestamp tag so players can highlight words as they are spoken.
+func vttCueText(t Transcript, cueStart time.Duration) string {
+	if len(t.Words) == 0 {
+		return vttEscaper.Replace(t.Text)
+	}
+
+	var b strings.Builder
+	for i, w := range t.Words {
+		if i > 0 {
+			fmt.Fprintf(&b, " <%s>", formatCueTime(cueStart+secondsToDuration(w.Start), "."))
+		}
+		b.WriteString(vttEscaper.Replace(w.Text))
+	}
+	return b.String()
+}
+
+func renderCSV(session *Session) ([]byte, error) {
+	var b bytes.Buffer
+	w := csv.NewWriter(&b)
+
+	rows := [][]string{{"timestamp", "start_seconds", "end_seconds", "user_id", "username", "text"}}
+	for _, t := range session.Transcripts {
+		start, end := CueTiming(session, t)
+		rows = append(rows, []string{
+			spokenAt(t).Format(time.RFC3339),
+			strconv.FormatFloat(start.Seconds(), 'f', 3, 64),
+			strconv.FormatFloat(end.Seconds(), 'f', 3, 64),
+			t.UserID,
+			t.Username,
+			t.Text,
+		})
+	}
+
+	if err := w.WriteAll(rows); err != nil {
+		return nil, fmt.Errorf("error writing csv: %w", err)
+	}
+	return b.Bytes(), nil
+}
+
+func renderText(session *Session) []byte {
+	var b bytes.Buffer
+	for _, t := range session.Transcripts {
+		fmt.Fprintf(&b, "[%s] %s: %s\n", spokenAt(t).Format("15:04:05"), t.Username, t.Text)
+	}
+	return b.Bytes()
+}
+
+// spokenAt returns when a transcript's speech started, or when it was
+// transcribed if it has no capture time
+func spokenAt(t Transcript) time.Time {
+	if t.AudioStart.IsZero() {
+		return t.Timestamp
+	}
+	return t.AudioStart
+}
+
+// participantNames returns the distinct speaker names in order of first appearance
+func participantNames(session *Session) []string {
+	seen := make(map[string]bool)
+	var names []string
+	for _, t := range session.Transcripts {
+		if !seen[t.UserID] {
+			seen[t.UserID] = true
+			names = append(names, t.Username)
+		}
+	}
+	return names
+}
+
+// formatCueTime formats an offset as HH:MM:SS<sep>mmm for subtitle cues
+func formatCueTime(d time.Duration, sep string) string {
+	ms := d.Milliseconds()
+	return fmt.Sprintf("%02d:%02d:%02d%s%03d", ms/3600000, (ms/60000)%60, (ms/1000)%60, sep, ms%1000)
+}
+
+// formatClock formats an offset as HH:MM:SS
+func formatClock(d time.Duration) string {
+	s := int64(d.Seconds())
+	return fmt.Sprintf("%02d:%02d:%02d", s/3600, (s/60)%60, s%60)
+}
@@ -0,0 +1,266 @@
+package session
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newExportFixture() *Session {
+	start := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	end := start.Add(10 * time.Minute)
+	return &Session{
+		ID:        "session-1",
+		GuildID:   "guild",
+		ChannelID: "channel",
+		StartTime: start,
+		EndTime:   &end,
+		Transcripts: []Transcript{
+			{
+				Timestamp: start.Add(5 * time.Second),
+				UserID:    "user-1",
+				Username:  "Alice",
+				Text:      "Hello everyone",
+				Duration:  1.5,
+			},
+			{
+				Timestamp: start.Add(time.Hour + 2*time.Minute + 3*time.Second + 250*time.Millisecond),
+				UserID:    "user-2",
+				Username:  "Bob",
+				Text:      "Hi, Alice",
+				Duration:  2,
+			},
+		},
+	}
+}
+
+func TestParseExportFormat(t *testing.T) {
+	tests := map[string]ExportFormat{
+		"":         FormatJSON,
+		"JSON":     FormatJSON,
+		"md":       FormatMarkdown,
+		"markdown": FormatMarkdown,
+		"srt":      FormatSRT,
+		"webvtt":   FormatVTT,
+		"vtt":      FormatVTT,
+		"csv":      FormatCSV,
+		"text":     FormatText,
+		"txt":      FormatText,
+	}
+	for input, expected := range tests {
+		format, err := ParseExportFormat(input)
+		require.NoError(t, err, input)
+		assert.Equal(t, expected, format, input)
+	}
+
+	_, err := ParseExportFormat("docx")
+	assert.Error(t, err)
+}
+
+func TestCueTiming(t *testing.T) {
+	session := newExportFixture()
+
+	start, end := CueTiming(session, session.Transcripts[0])
+	assert.Equal(t, 3500*time.Millisecond, start)
+	assert.Equal(t, 5*time.Second, end)
+
+	// Missing duration falls back to the default cue length
+	noDuration := session.Transcripts[0]
+	noDuration.Duration = 0
+	start, end = CueTiming(session, noDuration)
+	assert.Equal(t, 3*time.Second, start)
+	assert.Equal(t, 5*time.Second, end)
+
+	// Audio longer than the elapsed session time is clamped to zero
+	early := Transcript{Timestamp: session.StartTime.Add(time.Second), Duration: 3}
+	start, end = CueTiming(session, early)
+	assert.Equal(t, time.Duration(0), start)
+	assert.Equal(t, time.Second, end)
+
+	// Captured audio start takes precedence over the completion timestamp
+	captured := session.Transcripts[0]
+	captured.AudioStart = session.StartTime.Add(2 * time.Second)
+	start, end = CueTiming(session, captured)
+	assert.Equal(t, 2*time.Second, start)
+	assert.Equal(t, 3500*time.Millisecond, end)
+
+	// Captured audio end takes precedence over the transcribed duration
+	captured.AudioEnd = captured.AudioStart.Add(1800 * time.Millisecond)
+	start, end = CueTiming(session, captured)
+	assert.Equal(t, 2*time.Second, start)
+	assert.Equal(t, 3800*time.Millisecond, end)
+}
+
+func TestRenderOrdersBySpeechStart(t *testing.T) {
+	m := NewManager()
+	sessionID := m.CreateSession("guild", "channel")
+	start := time.Now().Add(-time.Minute)
+
+	// Alice spoke first, but her longer clip finished transcribing after Bob's reply
+	require.NoError(t, m.AddTranscriptEntry(sessionID, Transcript{
+		UserID: "bob", Username: "Bob", Text: "Sounds good",
+		AudioStart: start.Add(6 * time.Second), AudioEnd: start.Add(7 * time.Second), Duration: 1,
+	}))
+	require.NoError(t, m.AddTranscriptEntry(sessionID, Transcript{
+		UserID: "alice", Username: "Alice", Text: "Let's meet tomorrow",
+		AudioStart: start, AudioEnd: start.Add(5 * time.Second), Duration: 5,
+	}))
+
+	data, err := m.Render(sessionID, FormatText)
+	require.NoError(t, err)
+	text := string(data)
+	assert.Less(t, strings.Index(text, "Alice: Let's meet tomorrow"), strings.Index(text, "Bob: Sounds good"))
+
+	// Times are when the speech started, so they never run backwards
+	assert.Contains(t, text, fmt.Sprintf("[%s] Alice:", start.Format("15:04:05")))
+	assert.Contains(t, text, fmt.Sprintf("[%s] Bob:", start.Add(6*time.Second).Format("15:04:05")))
+
+	data, err = m.Render(sessionID, FormatCSV)
+	require.NoError(t, err)
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, start.Format(time.RFC3339), rows[1][0])
+	assert.Equal(t, start.Add(6*time.Second).Format(time.RFC3339), rows[2][0])
+
+	// The stored session keeps arrival order
+	session, err := m.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "Bob", session.Transcripts[0].Username)
+}
+
+func TestRenderSRT(t *testing.T) {
+	data, err := RenderSession(newExportFixture(), FormatSRT)
+	require.NoError(t, err)
+
+	expected := "1\n00:00:03,500 --> 00:00:05,000\nAlice: Hello everyone\n\n" +
+		"2\n01:02:01,250 --> 01:02:03,250\nBob: Hi, Alice\n\n"
+	assert.Equal(t, expected, string(data))
+}
+
+func TestRenderVTT(t *testing.T) {
+	data, err := RenderSession(newExportFixture(), FormatVTT)
+	require.NoError(t, err)
+
+	text := string(data)
+	assert.True(t, strings.HasPrefix(text, "WEBVTT\n\n"))
+	assert.Contains(t, text, "00:00:03.500 --> 00:00:05.000\n<v Alice>Hello everyone\n")
+	assert.Contains(t, text, "01:02:01.250 --> 01:02:03.250\n<v Bob>Hi, Alice\n")
+}
+
+func TestRenderVTTWordTimestamps(t *testing.T) {
+	session := newExportFixture()
+	session.Transcripts = session.Transcripts[:1]
+	session.Transcripts[0].AudioStart = session.StartTime.Add(2 * time.Second)
+	session.Transcripts[0].Words = []Word{
+		{Text: "Hello", Start: 0.1, End: 0.6, Confidence: 0.9},
+		{Text: "everyone", Start: 0.7, End: 1.4, Confidence: 0.8},
+	}
+
+	data, err := RenderSession(session, FormatVTT)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "00:00:02.000 --> 00:00:03.500\n<v Alice>Hello <00:00:02.700>everyone\n")
+
+	start, end := session.Transcripts[0].WordTime(session.Transcripts[0].Words[1])
+	assert.Equal(t, session.StartTime.Add(2700*time.Millisecond), start)
+	assert.Equal(t, session.StartTime.Add(3400*time.Millisecond), end)
+}
+
+func TestRenderVTTEscapesPayload(t *testing.T) {
+	session := newExportFixture()
+	session.Transcripts = session.Transcripts[:1]
+	session.Transcripts[0].Username = "<Ops & Co>"
+	session.Transcripts[0].Text = "a <b> c --> d\n\ne"
+
+	data, err := RenderSession(session, FormatVTT)
+	require.NoError(t, err)
+	text := string(data)
+	assert.Contains(t, text, "<v &lt;Ops &amp; Co&gt;>a &lt;b&gt; c --&gt; d  e\n")
+	assert.Equal(t, 1, strings.Count(text, "-->"), "only the cue timing")
+
+	session.Transcripts[0].Words = []Word{{Text: "x<y", Start: 0}, {Text: "-->", Start: 0.5}}
+	data, err = RenderSession(session, FormatVTT)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "x&lt;y <00:00:04.000>--&gt;\n")
+}
+
+func TestRenderMarkdown(t *testing.T) {
+	data, err := RenderSession(newExportFixture(), FormatMarkdown)
+	require.NoError(t, err)
+
+	text := string(data)
+	assert.Contains(t, text, "# Voice Session session-1")
+	assert.Contains(t, text, "- **Duration:** 10m0s")
+	assert.Contains(t, text, "- **Participants:** Alice, Bob")
+	assert.Contains(t, text, "**[00:00:03] Alice:** Hello everyone")
+	assert.Contains(t, text, "**[01:02:01] Bob:** Hi, Alice")
+}
+
+func TestRenderCSV(t *testing.T) {
+	data, err := RenderSession(newExportFixture(), FormatCSV)
+	require.NoError(t, err)
+
+	rows, err := csv.NewReader(strings.NewReader(string(data))).ReadAll()
+	require.NoError(t, err)
+	require.Len(t, rows, 3)
+	assert.Equal(t, []string{"timestamp", "start_seconds", "end_seconds", "user_id", "username", "text"}, rows[0])
+	assert.Equal(t, []string{"2025-01-02T15:00:05Z", "3.500", "5.000", "user-1", "Alice", "Hello everyone"}, rows[1])
+	assert.Equal(t, "Hi, Alice", rows[2][5])
+}
+
+func TestRenderText(t *testing.T) {
+	data, err := RenderSession(newExportFixture(), FormatText)
+	require.NoError(t, err)
+
+	assert.Equal(t, "[15:00:05] Alice: Hello everyone\n[16:02:03] Bob: Hi, Alice\n", string(data))
+}
+
+func TestSnapshotRendersWhileLanguagesChange(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.SetLanguage(sessionID, "user1", "de"))
+
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+		for i := range 200 {
+			_ = manager.SetLanguage(sessionID, fmt.Sprintf("user%d", i%10), "fr")
+		}
+	}()
+
+	for range 200 {
+		snapshot, err := manager.Snapshot(sessionID)
+		require.NoError(t, err)
+		_, err = RenderSession(snapshot, FormatJSON)
+		require.NoError(t, err)
+	}
+	<-done
+}
+
+func TestExportSessionAsUsesExportDir(t *testing.T) {
+	dir := filepath.Join(t.TempDir(), "nested", "exports")
+
+	manager := NewManager()
+	manager.SetExportDir(dir)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "First message"))
+
+	path, err := manager.ExportSessionAs(sessionID, FormatMarkdown)
+	require.NoError(t, err)
+	assert.Equal(t, dir, filepath.Dir(path))
+	assert.Equal(t, ".md", filepath.Ext(path))
+
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	assert.Contains(t, string(data), "First message")
+
+	_, err = manager.ExportSessionAs("non-existent", FormatSRT)
+	assert.Error(t, err)
+}
@@ -0,0 +1,215 @@
+package session
+
+import (
+	"bufio"
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+// JournalStore is an append-only JSONL store. Each line holds one Record,
+// so a crash can at worst leave a single partially written trailing line.
+type JournalStore struct {
+	path string
+	file *os.File
+	mu   sync.Mutex
+}
+
+// OpenJournal opens (or creates) the journal file at path
+func OpenJournal(path string) (*JournalStore, error) {
+	if dir := filepath.Dir(path); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return nil, fmt.Errorf("error creating journal directory: %w", err)
+		}
+	}
+
+	// #nosec G304 - journal path is controlled by server configuration
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		return nil, fmt.Errorf("error opening journal: %w", err)
+	}
+
+	logrus.WithField("path", path).Debug("Session journal opened")
+
+	return &JournalStore{
+		path: path,
+		file: file,
+	}, nil
+}
+
+// Append writes a record as a single line and syncs it to disk
+func (j *JournalStore) Append(record Record) error {
+	data, err := json.Marshal(record)
+	if err != nil {
+		return fmt.Errorf("error marshaling journal record: %w", err)
+	}
+	data = append(data, '\n')
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+
+	if _, err := j.file.Write(data); err != nil {
+		return fmt.Errorf("error writing journal record: %w", err)
+	}
+	if err := j.file.Sync(); err != nil {
+		return fmt.Errorf("error syncing journal: %w", err)
+	}
+	return nil
+}
+
+// Replay reads every record from the start of the journal. A truncated
+// trailing line left behind by a crash is cut off so later appends start
+// on a clean line; corrupt lines elsewhere are skipped.
+func (j *JournalStore) Replay(fn func(record Record) error) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+
+	if _, err := j.file.Seek(0, io.SeekStart); err != nil {
+		return fmt.Errorf("error seeking journal: %w", err)
+	}
+
+	reader := bufio.NewReader(j.file)
+	var offset int64
+	lineNumber := 0
+
+	for {
+		line, err := reader.ReadBytes('\n')
+		if errors.Is(err, io.EOF) {
+			if len(bytes.TrimSpace(line)) > 0 {
+				// Partial write from a crash - drop it
+				logrus.WithFields(logrus.Fields{
+					"path":   j.path,
+					"offset": offset,
+					"bytes":  len(line),
+				}).Warn("Discarding truncated record at end of session journal")
+				if err := j.file.Truncate(offset); err != nil {
+					return fmt.Errorf("error truncating journal: %w", err)
+				}
+			}
+			return nil
+		}
+		if err != nil {
+			return fmt.Errorf("error reading journal: %w", err)
+		}
+
+		lineNumber++
+		offset += int64(len(line))
+
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var record Record
+		if err := json.Unmarshal(line, &record); err != nil {
+			logrus.WithError(err).WithFields(logrus.Fields{
+				"path": j.path,
+				"line": lineNumber,
+			}).Warn("Skipping corrupt session journal record")
+			continue
+		}
+
+		if err := fn(record); err != nil {
+			return err
+		}
+	}
+}
+
+// Compact rewrites the journal as one snapshot record per session
+func (j *JournalStore) Compact(sessions []*Session) error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return errors.New("journal is closed")
+	}
+
+	tmpPath := j.path + ".tmp"
+	// #nosec G304 - journal path is controlled by server configuration
+	tmp, err := os.OpenFile(tmpPath, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, 0600)
+	if err != nil {
+		return fmt.Errorf("error creating compacted journal: %w", err)
+	}
+
+	writer := bufio.NewWriter(tmp)
+	encoder := json.NewEncoder(writer)
+	now := time.Now()
+	for _, session := range sessions {
+		record := Record{
+			Type:      RecordSessionSnapshot,
+			SessionID: session.ID,
+			Timestamp: now,
+			Session:   session,
+		}
+		if err := encoder.Encode(record); err != nil {
+			_ = tmp.Close()
+			_ = os.Remove(tmpPath)
+			return fmt.Errorf("error writing compacted journal: %w", err)
+		}
+	}
+
+	if err := writer.Flush(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error flushing compacted journal: %w", err)
+	}
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error syncing compacted journal: %w", err)
+	}
+	if err := tmp.Close(); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error closing compacted journal: %w", err)
+	}
+
+	if err := os.Rename(tmpPath, j.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error replacing journal: %w", err)
+	}
+
+	// Reopen so appends go to the compacted file
+	_ = j.file.Close()
+	// #nosec G304 - journal path is controlled by server configuration
+	file, err := os.OpenFile(j.path, os.O_CREATE|os.O_RDWR|os.O_APPEND, 0600)
+	if err != nil {
+		j.file = nil
+		return fmt.Errorf("error reopening journal: %w", err)
+	}
+	j.file = file
+
+	logrus.WithFields(logrus.Fields{
+		"path":     j.path,
+		"sessions": len(sessions),
+	}).Debug("Session journal compacted")
+
+	return nil
+}
+
+// Close closes the journal file
+func (j *JournalStore) Close() error {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	if j.file == nil {
+		return nil
+	}
+	err := j.file.Close()
+	j.file = nil
+	return err
+}
@@ -0,0 +1,191 @@
+package session
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func newJournalManager(t *testing.T, path string) *Manager {
+	t.Helper()
+
+	store, err := OpenJournal(path)
+	require.NoError(t, err)
+
+	manager, err := NewManagerWithStore(store)
+	require.NoError(t, err)
+	return manager
+}
+
+func TestJournalRestoresSessionsAcrossRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "First message"))
+	require.NoError(t, manager.AddTranscript(sessionID, "user-2", "User2", "Second message"))
+	require.NoError(t, manager.SetLanguage(sessionID, "user-2", "fr"))
+	require.NoError(t, manager.SetTranslationTarget(sessionID, "en"))
+	require.NoError(t, manager.SetRecording(sessionID, Recording{
+		Dir:    "recordings/" + sessionID,
+		Mix:    "mix.opus",
+		Tracks: []RecordingTrack{{SSRC: 7, UserID: "user-1", File: "ssrc-7.opus"}},
+	}))
+	require.NoError(t, manager.EndSession(sessionID))
+	require.NoError(t, manager.Close())
+
+	// Simulate a restart
+	restored := newJournalManager(t, path)
+
+	sessions := restored.ListSessions()
+	require.Len(t, sessions, 1)
+
+	session, err := restored.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Equal(t, "guild", session.GuildID)
+	assert.Equal(t, "channel", session.ChannelID)
+	assert.NotNil(t, session.EndTime)
+	require.Len(t, session.Transcripts, 2)
+	assert.Equal(t, "First message", session.Transcripts[0].Text)
+	assert.Equal(t, "Second message", session.Transcripts[1].Text)
+	assert.Equal(t, "fr", restored.Language(sessionID, "user-2"))
+	assert.Equal(t, "en", restored.TranslationTarget(sessionID))
+	require.NotNil(t, session.Recording)
+	assert.Equal(t, []string{"ssrc-7.opus"}, session.Recording.TracksFor("user-1"))
+	assert.Empty(t, session.Recording.TracksFor("user-2"))
+
+	// Deleting the audio drops the reference, also after the next restart
+	require.NoError(t, restored.ClearRecording(sessionID))
+	require.NoError(t, restored.Close())
+	reopened := newJournalManager(t, path)
+	defer func() { _ = reopened.Close() }()
+	session, err = reopened.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Nil(t, session.Recording)
+}
+
+func TestJournalRecoversFromTruncatedRecord(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "Survives the crash"))
+	require.NoError(t, manager.Close())
+
+	// Simulate a crash in the middle of writing a record
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString(`{"type":"transcript.added","sessionId":"` + sessionID + `","transcript":{"text":"lost`)
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	restored := newJournalManager(t, path)
+	session, err := restored.GetSession(sessionID)
+	require.NoError(t, err)
+	require.Len(t, session.Transcripts, 1)
+	assert.Equal(t, "Survives the crash", session.Transcripts[0].Text)
+
+	// New records must land on a clean line after recovery
+	require.NoError(t, restored.AddTranscript(sessionID, "user-1", "User1", "After recovery"))
+	require.NoError(t, restored.Close())
+
+	again := newJournalManager(t, path)
+	defer func() { _ = again.Close() }()
+	session, err = again.GetSession(sessionID)
+	require.NoError(t, err)
+	require.Len(t, session.Transcripts, 2)
+	assert.Equal(t, "After recovery", session.Transcripts[1].Text)
+}
+
+func TestJournalSkipsCorruptRecords(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.Close())
+
+	f, err := os.OpenFile(path, os.O_APPEND|os.O_WRONLY, 0600)
+	require.NoError(t, err)
+	_, err = f.WriteString("not json at all\n")
+	require.NoError(t, err)
+	require.NoError(t, f.Close())
+
+	restored := newJournalManager(t, path)
+	defer func() { _ = restored.Close() }()
+	_, err = restored.GetSession(sessionID)
+	assert.NoError(t, err)
+}
+
+func TestJournalCompactsOnStartup(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	for i := 0; i < 5; i++ {
+		require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "message"))
+	}
+	require.NoError(t, manager.Close())
+
+	restored := newJournalManager(t, path)
+	require.NoError(t, restored.Close())
+
+	// One snapshot line per session after compaction
+	data, err := os.ReadFile(path)
+	require.NoError(t, err)
+	lines := 0
+	for _, b := range data {
+		if b == '\n' {
+			lines++
+		}
+	}
+	assert.Equal(t, 1, lines)
+
+	again := newJournalManager(t, path)
+	defer func() { _ = again.Close() }()
+	session, err := again.GetSession(sessionID)
+	require.NoError(t, err)
+	assert.Len(t, session.Transcripts, 5)
+}
+
+func TestJournalEndsSessionsLeftOpen(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	openID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(openID, "user-1", "User1", "Last words before the crash"))
+	endedID := manager.CreateSession("guild", "other-channel")
+	require.NoError(t, manager.EndSession(endedID))
+	ended, err := manager.GetSession(endedID)
+	require.NoError(t, err)
+	endedAt := *ended.EndTime
+	last, err := manager.GetSession(openID)
+	require.NoError(t, err)
+	lastTranscript := last.Transcripts[0].Timestamp
+	// No EndSession: the process stopped while recording
+	require.NoError(t, manager.Close())
+
+	restored := newJournalManager(t, path)
+	session, err := restored.GetSession(openID)
+	require.NoError(t, err)
+	assert.Equal(t, StatusEnded, session.Status())
+	require.NotNil(t, session.EndTime)
+	assert.False(t, session.EndTime.Before(lastTranscript), "ended at its last activity")
+	assert.WithinDuration(t, lastTranscript, *session.EndTime, time.Second)
+	duration := session.Duration()
+
+	ended, err = restored.GetSession(endedID)
+	require.NoError(t, err)
+	assert.True(t, endedAt.Equal(*ended.EndTime), "already ended sessions keep their end time")
+	require.NoError(t, restored.Close())
+
+	// The end time was persisted, so the duration stays put across restarts
+	again := newJournalManager(t, path)
+	defer func() { _ = again.Close() }()
+	session, err = again.GetSession(openID)
+	require.NoError(t, err)
+	assert.Equal(t, duration, session.Duration())
+}
@@ -1,10 +1,10 @@
 package session
 
 import (
-	"encoding/json"
+	"cmp"
 	"fmt"
-	"os"
-	"path/filepath"
+	"slices"
+	"sort"
 	"sync"
 	"time"
 
@@ -14,8 +14,11 @@ import (
 
 // Manager handles transcription sessions
 type Manager struct {
-	sessions map[string]*Session
-	mu       sync.RWMutex
+	sessions  map[string]*Session
+	store     Store  // Optional persistent backend, nil for in-memory only
+	exportDir string // Directory export files are written to
+	onCreated []func(session Session)
+	mu        sync.RWMutex
 }
 
 // Session represents a transcription session
@@ -27,6 +30,84 @@ type Session struct {
 	EndTime               *time.Time             `json:"endTime,omitempty"`
 	Transcripts           []Transcript           `json:"transcripts"`
 	PendingTranscriptions []PendingTranscription `json:"pendingTranscriptions,omitempty"`
+
+	// Transcription language for the session and per-user overrides.
+	// Empty means the transcriber's default.
+	Language      string            `json:"language,omitempty"`
+	UserLanguages map[string]string `json:"userLanguages,omitempty"`
+
+	// Language new transcripts are translated into, empty if translation is off
+	TranslateTo string `json:"translateTo,omitempty"`
+
+	// Audio captured for the session, nil if it wasn't recorded
+	Recording *Recording `json:"recording,omitempty"`
+
+	// Re-transcriptions of the recorded audio, kept apart from Transcripts
+	Alternates []Alternate `json:"alternates,omitempty"`
+
+	// Transcripts merged per speaker into whole utterances. Derived from
+	// Transcripts, so it is rebuilt rather than stored.
+	Utterances []Utterance `json:"-"`
+}
+
+// Session status values reported by Status
+const (
+	StatusActive = "active"
+	StatusEnded  = "ended"
+)
+
+// Status reports whether the session is still recording or has ended
+func (s *Session) Status() string {
+	if s.EndTime != nil {
+		return StatusEnded
+	}
+	return StatusActive
+}
+
+// Duration returns how long the session ran, or has been running so far
+func (s *Session) Duration() time.Duration {
+	if s.EndTime != nil {
+		return s.EndTime.Sub(s.StartTime)
+	}
+	return time.Since(s.StartTime)
+}
+
+// Recording locates the audio files captured for a session. Every track
+// starts at Start, so a transcript's audio lies at AudioStart minus Start.
+type Recording struct {
+	Dir    string           `json:"dir"`
+	Start  time.Time        `json:"start"`
+	Mix    string           `json:"mix,omitempty"` // All speakers mixed down, relative to Dir
+	Tracks []RecordingTrack `json:"tracks,omitempty"`
+}
+
+// RecordingTrack is the audio received from one speaker
+type RecordingTrack struct {
+	SSRC     uint32 `json:"ssrc"`
+	UserID   string `json:"userId"`
+	Username string `json:"username,omitempty"`
+	File     string `json:"file"` // Relative to the recording's Dir
+}
+
+// TracksFor returns the files holding a user's audio, one per SSRC they were
+// heard on, or nil if the user wasn't recorded
+func (r *Recording) TracksFor(userID string) []string {
+	var files []string
+	for _, track := range r.Tracks {
+		if track.UserID == userID {
+			files = append(files, track.File)
+		}
+	}
+	return files
+}
+
+// Alternate is a session transcribed again from its retained audio, such as
+// with a larger model after the meeting
+type Alternate struct {
+	ID          string       `json:"id"`
+	Transcriber string       `json:"transcriber"` // What produced it, such as "whisper ggml-large-v3.bin"
+	Created     time.Time    `json:"created"`
+	Transcripts []Transcript `json:"transcripts"` // Ordered by speech start
 }
 
 // PendingTranscription represents an in-progress transcription
@@ -39,23 +120,287 @@ type PendingTranscription struct {
 
 // Transcript represents a single transcribed message
 type Transcript struct {
+	Sequence  int64     `json:"sequence"` // Stable 1-based position within the session
 	Timestamp time.Time `json:"timestamp"`
 	UserID    string    `json:"userId"`
 	Username  string    `json:"username"`
 	Text      string    `json:"text"`
+	Duration  float64   `json:"durationSeconds,omitempty"` // Length of the transcribed audio
+
+	// Recognizer output, when the transcriber provides it
+	Language   string    `json:"language,omitempty"`   // Detected or requested language
+	Confidence float32   `json:"confidence,omitempty"` // 0 to 1
+	AudioStart time.Time `json:"audioStart,omitzero"`  // When the first packet of the audio was captured
+	AudioEnd   time.Time `json:"audioEnd,omitzero"`    // When the last packet of the audio was captured
+	Words      []Word    `json:"words,omitempty"`      // Timed relative to AudioStart
+
+	// Translation of Text, when the session translates
+	Translation         string `json:"translation,omitempty"`
+	TranslationLanguage string `json:"translationLanguage,omitempty"`
 }
 
-// NewManager creates a new session manager
+// Word is one recognized word with offsets into the transcribed audio
+type Word struct {
+	Text       string  `json:"text"`
+	Start      float64 `json:"startSeconds"`
+	End        float64 `json:"endSeconds"`
+	Confidence float32 `json:"confidence,omitempty"`
+}
+
+// WordTime returns the wall-clock time a word was spoken.
+// Returns zero times if the transcript has no AudioStart.
+func (t Transcript) WordTime(w Word) (start, end time.Time) {
+	if t.AudioStart.IsZero() {
+		return time.Time{}, time.Time{}
+	}
+	return t.AudioStart.Add(secondsToDuration(w.Start)), t.AudioStart.Add(secondsToDuration(w.End))
+}
+
+// Span returns when the transcribed speech started and ended. Without capture
+// times it is estimated from when the transcript was added and the audio duration.
+func (t Transcript) Span() (start, end time.Time) {
+	length := secondsToDuration(t.Duration)
+	start = t.AudioStart
+	if start.IsZero() {
+		start = t.Timestamp.Add(-length)
+	}
+	end = t.AudioEnd
+	if end.Before(start) {
+		end = start.Add(length)
+	}
+	return start, end
+}
+
+// SortBySpeechStart orders transcripts by when their speech started, so a slow
+// transcription no longer appears after the reply to it. Ties keep sequence order.
+func SortBySpeechStart(transcripts []Transcript) {
+	sort.SliceStable(transcripts, func(i, j int) bool {
+		a, _ := transcripts[i].Span()
+		b, _ := transcripts[j].Span()
+		return a.Before(b)
+	})
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// NewManager creates a new in-memory session manager
 func NewManager() *Manager {
 	return &Manager{
-		sessions: make(map[string]*Session),
+		sessions:  make(map[string]*Session),
+		exportDir: defaultExportDir,
 	}
 }
 
+// NewManagerWithStore creates a session manager backed by a persistent store.
+// Sessions already in the store are replayed so they survive restarts. Sessions
+// still open when the process stopped are ended at their last recorded activity,
+// since the bot is in no voice channel after a restart.
+func NewManagerWithStore(store Store) (*Manager, error) {
+	m := &Manager{
+		sessions:  make(map[string]*Session),
+		store:     store,
+		exportDir: defaultExportDir,
+	}
+
+	records := 0
+	lastRecord := make(map[string]time.Time)
+	err := store.Replay(func(record Record) error {
+		records++
+		m.applyRecord(record)
+		// Snapshots are written at compaction, not when the session changed
+		if record.Type != RecordSessionSnapshot && record.Timestamp.After(lastRecord[record.SessionID]) {
+			lastRecord[record.SessionID] = record.Timestamp
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("error replaying session store: %w", err)
+	}
+
+	closed := 0
+	for _, session := range m.sessions {
+		if session.EndTime != nil {
+			continue
+		}
+		end := lastActivity(session, lastRecord[session.ID])
+		session.EndTime = &end
+		m.persist(Record{
+			Type:      RecordSessionEnded,
+			SessionID: session.ID,
+			EndTime:   &end,
+		})
+		closed++
+	}
+	if closed > 0 {
+		logrus.WithField("sessions", closed).Info("Ended sessions left open by the previous run")
+	}
+
+	// Collapse the replayed history so the store doesn't grow without bound
+	if c, ok := store.(compactor); ok && (records > len(m.sessions) || closed > 0) {
+		sessions := make([]*Session, 0, len(m.sessions))
+		for _, session := range m.sessions {
+			sessions = append(sessions, session)
+		}
+		if err := c.Compact(sessions); err != nil {
+			logrus.WithError(err).Warn("Failed to compact session store")
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"sessions": len(m.sessions),
+		"records":  records,
+	}).Info("Sessions restored from store")
+
+	return m, nil
+}
+
+// lastActivity returns when a session last changed: its latest transcript or
+// journal record, or its start if neither is later
+func lastActivity(session *Session, lastRecord time.Time) time.Time {
+	last := session.StartTime
+	for _, t := range session.Transcripts {
+		if t.Timestamp.After(last) {
+			last = t.Timestamp
+		}
+	}
+	if lastRecord.After(last) {
+		last = lastRecord
+	}
+	return last
+}
+
+// applyRecord applies a replayed store record to the in-memory state
+func (m *Manager) applyRecord(record Record) {
+	switch record.Type {
+	case RecordSessionCreated, RecordSessionSnapshot:
+		if record.Session == nil {
+			return
+		}
+		session := record.Session
+		if session.Transcripts == nil {
+			session.Transcripts = []Transcript{}
+		}
+		// Pending work did not survive the restart
+		session.PendingTranscriptions = []PendingTranscription{}
+		// Journals written before sequence numbers existed carry none
+		session.Utterances = nil
+		for i := range session.Transcripts {
+			if session.Transcripts[i].Sequence == 0 {
+				session.Transcripts[i].Sequence = int64(i + 1)
+			}
+			addToUtterance(session, session.Transcripts[i])
+		}
+		m.sessions[session.ID] = session
+
+	case RecordTranscriptAdded:
+		session, exists := m.sessions[record.SessionID]
+		if !exists || record.Transcript == nil {
+			logrus.WithField("session_id", record.SessionID).Warn("Skipping journaled transcript for unknown session")
+			return
+		}
+		transcript := *record.Transcript
+		if transcript.Sequence == 0 {
+			transcript.Sequence = nextSequence(session)
+		}
+		session.Transcripts = append(session.Transcripts, transcript)
+		addToUtterance(session, transcript)
+
+	case RecordSessionEnded:
+		session, exists := m.sessions[record.SessionID]
+		if !exists || record.EndTime == nil {
+			return
+		}
+		session.EndTime = record.EndTime
+
+	case RecordLanguageSet:
+		session, exists := m.sessions[record.SessionID]
+		if !exists {
+			return
+		}
+		setLanguage(session, record.UserID, record.Language)
+
+	case RecordTranslationSet:
+		session, exists := m.sessions[record.SessionID]
+		if !exists {
+			return
+		}
+		session.TranslateTo = record.Language
+
+	case RecordTranscriptTranslated:
+		session, exists := m.sessions[record.SessionID]
+		if !exists {
+			return
+		}
+		setTranslation(session, record.Sequence, record.Translation, record.Language)
+
+	case RecordRecordingSet:
+		session, exists := m.sessions[record.SessionID]
+		if !exists {
+			return
+		}
+		session.Recording = record.Recording
+
+	case RecordAlternateAdded:
+		session, exists := m.sessions[record.SessionID]
+		if !exists || record.Alternate == nil {
+			return
+		}
+		session.Alternates = append(session.Alternates, *record.Alternate)
+
+	default:
+		logrus.WithField("type", record.Type).Warn("Skipping unknown session store record")
+	}
+}
+
+// nextSequence returns the sequence number for the next transcript in a session
+func nextSequence(session *Session) int64 {
+	if n := len(session.Transcripts); n > 0 {
+		return session.Transcripts[n-1].Sequence + 1
+	}
+	return 1
+}
+
+// transcriptIndex finds a transcript by sequence. Transcripts are stored in sequence order.
+func transcriptIndex(session *Session, sequence int64) (int, bool) {
+	return slices.BinarySearchFunc(session.Transcripts, sequence, func(t Transcript, sequence int64) int {
+		return cmp.Compare(t.Sequence, sequence)
+	})
+}
+
+// persist writes a record to the store if one is configured.
+// Must be called with m.mu held so records stay in mutation order.
+func (m *Manager) persist(record Record) {
+	if m.store == nil {
+		return
+	}
+	if record.Timestamp.IsZero() {
+		record.Timestamp = time.Now()
+	}
+	if err := m.store.Append(record); err != nil {
+		logrus.WithError(err).WithFields(logrus.Fields{
+			"session_id": record.SessionID,
+			"type":       record.Type,
+		}).Error("Failed to persist session change")
+	}
+}
+
+// Close releases the persistent store, if any
+func (m *Manager) Close() error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.store == nil {
+		return nil
+	}
+	return m.store.Close()
+}
+
 // CreateSession creates a new transcription session
 func (m *Manager) CreateSession(guildID, channelID string) string {
 	m.mu.Lock()
-	defer m.mu.Unlock()
 
 	session := &Session{
 		ID:                    uuid.New().String(),
@@ -67,6 +412,15 @@ func (m *Manager) CreateSession(guildID, channelID string) string {
 	}
 
 	m.sessions[session.ID] = session
+	m.persist(Record{
+		Type:      RecordSessionCreated,
+		SessionID: session.ID,
+		Session:   session,
+	})
+
+	hooks := m.onCreated
+	created := *session
+	m.mu.Unlock()
 
 	logrus.WithFields(logrus.Fields{
 		"session_id": session.ID,
@@ -74,9 +428,21 @@ func (m *Manager) CreateSession(guildID, channelID string) string {
 		"channel_id": channelID,
 	}).Debug("Session created")
 
+	// Hooks run without the lock so they may call back into the manager
+	for _, hook := range hooks {
+		hook(created)
+	}
+
 	return session.ID
 }
 
+// OnSessionCreated registers fn to be called after every new session is created
+func (m *Manager) OnSessionCreated(fn func(session Session)) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	m.onCreated = append(m.onCreated, fn)
+}
+
 // AddPendingTranscription adds a pending transcription to track in-progress work
 func (m *Manager) AddPendingTranscription(sessionID, userID, username string, duration float64) error {
 	m.mu.Lock()
@@ -131,6 +497,27 @@ func (m *Manager) RemovePendingTranscription(sessionID, userID string) error {
 
 // AddTranscript adds a transcript to a session
 func (m *Manager) AddTranscript(sessionID, userID, username, text string) error {
+	return m.AddTranscriptEntry(sessionID, Transcript{
+		UserID:   userID,
+		Username: username,
+		Text:     text,
+	})
+}
+
+// AddTranscriptEntry adds a transcript carrying pipeline metadata such as the
+// audio duration. A zero Timestamp is set to the current time.
+func (m *Manager) AddTranscriptEntry(sessionID string, transcript Transcript) error {
+	_, err := m.AppendTranscript(sessionID, transcript)
+	return err
+}
+
+// AppendTranscript adds a transcript like AddTranscriptEntry and returns the
+// sequence number it was stored under
+func (m *Manager) AppendTranscript(sessionID string, transcript Transcript) (int64, error) {
+	userID := transcript.UserID
+	username := transcript.Username
+	text := transcript.Text
+
 	logrus.WithFields(logrus.Fields{
 		"session_id": sessionID,
 		"user_id":    userID,
@@ -148,17 +535,21 @@ func (m *Manager) AddTranscript(sessionID, userID, username, text string) error
 			"session_id":              sessionID,
 			"available_session_count": len(m.sessions),
 		}).Error("Session not found for transcript")
-		return fmt.Errorf("session %s not found", sessionID)
+		return 0, fmt.Errorf("session %s not found", sessionID)
 	}
 
-	transcript := Transcript{
-		Timestamp: time.Now(),
-		UserID:    userID,
-		Username:  username,
-		Text:      text,
+	if transcript.Timestamp.IsZero() {
+		transcript.Timestamp = time.Now()
 	}
+	transcript.Sequence = nextSequence(session)
 
 	session.Transcripts = append(session.Transcripts, transcript)
+	addToUtterance(session, transcript)
+	m.persist(Record{
+		Type:       RecordTranscriptAdded,
+		SessionID:  sessionID,
+		Transcript: &transcript,
+	})
 
 	logrus.WithFields(logrus.Fields{
 		"session_id":        sessionID,
@@ -185,10 +576,210 @@ func (m *Manager) AddTranscript(sessionID, userID, username, text string) error
 		"total_transcripts": len(session.Transcripts),
 	}).Debug("Transcript added to session")
 
+	return transcript.Sequence, nil
+}
+
+// SetLanguage sets the transcription language of a session, or of one user in it
+// when userID is not empty. An empty language reverts to the default.
+func (m *Manager) SetLanguage(sessionID, userID, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+
+	setLanguage(session, userID, language)
+	m.persist(Record{
+		Type:      RecordLanguageSet,
+		SessionID: sessionID,
+		UserID:    userID,
+		Language:  language,
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"user_id":    userID,
+		"language":   language,
+	}).Debug("Session language set")
+
+	return nil
+}
+
+// setLanguage applies a language setting to a session
+func setLanguage(session *Session, userID, language string) {
+	if userID == "" {
+		session.Language = language
+		return
+	}
+	if language == "" {
+		delete(session.UserLanguages, userID)
+		return
+	}
+	if session.UserLanguages == nil {
+		session.UserLanguages = make(map[string]string)
+	}
+	session.UserLanguages[userID] = language
+}
+
+// Language returns the language to transcribe a user's speech in: the user's
+// override, else the session language. Empty means the transcriber's default.
+func (m *Manager) Language(sessionID, userID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return ""
+	}
+	if language, ok := session.UserLanguages[userID]; ok {
+		return language
+	}
+	return session.Language
+}
+
+// SetTranslationTarget sets the language new transcripts of a session are
+// translated into. An empty language turns translation off.
+func (m *Manager) SetTranslationTarget(sessionID, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+
+	session.TranslateTo = language
+	m.persist(Record{
+		Type:      RecordTranslationSet,
+		SessionID: sessionID,
+		Language:  language,
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"session_id": sessionID,
+		"language":   language,
+	}).Debug("Session translation target set")
+
+	return nil
+}
+
+// SetTranslation attaches a translation to a stored transcript. Translations
+// complete after their transcript was added, so they arrive as a separate update.
+func (m *Manager) SetTranslation(sessionID string, sequence int64, translation, language string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	if !setTranslation(session, sequence, translation, language) {
+		return fmt.Errorf("transcript %d not found in session %s", sequence, sessionID)
+	}
+	m.persist(Record{
+		Type:        RecordTranscriptTranslated,
+		SessionID:   sessionID,
+		Sequence:    sequence,
+		Translation: translation,
+		Language:    language,
+	})
+	return nil
+}
+
+// setTranslation stores the translation of a transcript and the utterance it was merged into
+func setTranslation(session *Session, sequence int64, translation, language string) bool {
+	i, found := transcriptIndex(session, sequence)
+	if !found {
+		return false
+	}
+	session.Transcripts[i].Translation = translation
+	session.Transcripts[i].TranslationLanguage = language
+	retranslateUtterance(session, sequence)
+	return true
+}
+
+// SetRecording records where a session's audio is being captured. The
+// recording replaces any earlier one and must not be modified afterwards.
+func (m *Manager) SetRecording(sessionID string, recording Recording) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+
+	session.Recording = &recording
+	m.persist(Record{
+		Type:      RecordRecordingSet,
+		SessionID: sessionID,
+		Recording: &recording,
+	})
+	return nil
+}
+
+// ClearRecording drops a session's reference to its recording once the audio has been deleted
+func (m *Manager) ClearRecording(sessionID string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+	if session.Recording == nil {
+		return nil
+	}
+
+	session.Recording = nil
+	m.persist(Record{
+		Type:      RecordRecordingSet,
+		SessionID: sessionID,
+	})
+	return nil
+}
+
+// AddAlternate stores a re-transcription of a session next to its live transcript
+func (m *Manager) AddAlternate(sessionID string, alternate Alternate) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return fmt.Errorf("session %s not found", sessionID)
+	}
+
+	session.Alternates = append(session.Alternates, alternate)
+	m.persist(Record{
+		Type:      RecordAlternateAdded,
+		SessionID: sessionID,
+		Alternate: &alternate,
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"session_id":   sessionID,
+		"alternate_id": alternate.ID,
+		"transcripts":  len(alternate.Transcripts),
+	}).Info("Alternate transcript added to session")
+
 	return nil
 }
 
-// EndSession marks a session as ended
+// TranslationTarget returns the language a session translates into, or empty if it doesn't
+func (m *Manager) TranslationTarget(sessionID string) string {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return ""
+	}
+	return session.TranslateTo
+}
+
+// EndSession marks a session as ended. Ending an already ended session is a no-op.
 func (m *Manager) EndSession(sessionID string) error {
 	m.mu.Lock()
 	defer m.mu.Unlock()
@@ -197,9 +788,24 @@ func (m *Manager) EndSession(sessionID string) error {
 	if !exists {
 		return fmt.Errorf("session %s not found", sessionID)
 	}
+	if session.EndTime != nil {
+		return nil
+	}
 
 	now := time.Now()
 	session.EndTime = &now
+	m.persist(Record{
+		Type:      RecordSessionEnded,
+		SessionID: sessionID,
+		EndTime:   &now,
+	})
+
+	logrus.WithFields(logrus.Fields{
+		"session_id":  sessionID,
+		"duration":    now.Sub(session.StartTime).Round(time.Second),
+		"transcripts": len(session.Transcripts),
+	}).Debug("Session ended")
+
 	return nil
 }
 
@@ -230,33 +836,5 @@ func (m *Manager) ListSessions() []Session {
 
 // ExportSession exports a session to JSON file
 func (m *Manager) ExportSession(sessionID string) (string, error) {
-	session, err := m.GetSession(sessionID)
-	if err != nil {
-		return "", err
-	}
-
-	// Create exports directory
-	exportDir := "exports"
-	// #nosec G301 - Export directory needs to be readable for serving files
-	if err := os.MkdirAll(exportDir, 0750); err != nil {
-		return "", fmt.Errorf("error creating export directory: %w", err)
-	}
-
-	// Generate filename
-	filename := fmt.Sprintf("session_%s_%s.json", session.ID, session.StartTime.Format("20060102_150405"))
-	filepath := filepath.Join(exportDir, filename)
-
-	// Marshal to JSON
-	data, err := json.MarshalIndent(session, "", "  ")
-	if err != nil {
-		return "", fmt.Errorf("error marshaling session: %w", err)
-	}
-
-	// Write to file
-	// #nosec G306 - Export files need to be readable by the user
-	if err := os.WriteFile(filepath, data, 0640); err != nil {
-		return "", fmt.Errorf("error writing file: %w", err)
-	}
-
-	return filepath, nil
+	return m.ExportSessionAs(sessionID, FormatJSON)
 }
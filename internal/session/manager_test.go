@@ -138,6 +138,85 @@ func TestEndSession(t *testing.T) {
 	assert.Error(t, err)
 }
 
+func TestSetLanguage(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+
+	// Nothing set - the transcriber's default applies
+	assert.Equal(t, "", manager.Language(sessionID, "user-1"))
+
+	require.NoError(t, manager.SetLanguage(sessionID, "", "de"))
+	require.NoError(t, manager.SetLanguage(sessionID, "user-2", "en"))
+	assert.Equal(t, "de", manager.Language(sessionID, "user-1"))
+	assert.Equal(t, "en", manager.Language(sessionID, "user-2"))
+
+	// Clearing a user override falls back to the session language
+	require.NoError(t, manager.SetLanguage(sessionID, "user-2", ""))
+	assert.Equal(t, "de", manager.Language(sessionID, "user-2"))
+
+	assert.Error(t, manager.SetLanguage("non-existent", "", "de"))
+	assert.Equal(t, "", manager.Language("non-existent", "user-1"))
+}
+
+func TestSetTranslationTarget(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+	assert.Equal(t, "", manager.TranslationTarget(sessionID))
+
+	require.NoError(t, manager.SetTranslationTarget(sessionID, "en"))
+	assert.Equal(t, "en", manager.TranslationTarget(sessionID))
+
+	require.NoError(t, manager.SetTranslationTarget(sessionID, ""))
+	assert.Equal(t, "", manager.TranslationTarget(sessionID))
+
+	assert.Error(t, manager.SetTranslationTarget("non-existent", "en"))
+}
+
+func TestOnSessionCreated(t *testing.T) {
+	manager := NewManager()
+
+	var created []Session
+	manager.OnSessionCreated(func(session Session) {
+		// Hooks may call back into the manager
+		_, err := manager.GetSession(session.ID)
+		assert.NoError(t, err)
+		created = append(created, session)
+	})
+
+	sessionID := manager.CreateSession("guild", "channel")
+	require.Len(t, created, 1)
+	assert.Equal(t, sessionID, created[0].ID)
+	assert.Equal(t, "channel", created[0].ChannelID)
+}
+
+func TestEndSessionIsIdempotent(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+
+	require.NoError(t, manager.EndSession(sessionID))
+	session, err := manager.GetSession(sessionID)
+	require.NoError(t, err)
+	firstEnd := *session.EndTime
+
+	// Ending again must keep the original end time
+	time.Sleep(5 * time.Millisecond)
+	require.NoError(t, manager.EndSession(sessionID))
+	assert.Equal(t, firstEnd, *session.EndTime)
+}
+
+func TestSessionStatusAndDuration(t *testing.T) {
+	start := time.Now().Add(-90 * time.Second)
+	session := &Session{StartTime: start}
+
+	assert.Equal(t, StatusActive, session.Status())
+	assert.GreaterOrEqual(t, session.Duration(), 90*time.Second)
+
+	end := start.Add(time.Minute)
+	session.EndTime = &end
+	assert.Equal(t, StatusEnded, session.Status())
+	assert.Equal(t, time.Minute, session.Duration())
+}
+
 func TestGetSession(t *testing.T) {
 	manager := NewManager()
 	sessionID := manager.CreateSession("guild", "channel")
@@ -0,0 +1,146 @@
+package session
+
+import (
+	"fmt"
+	"sort"
+	"time"
+)
+
+// TranscriptQuery selects a window of a session's transcripts for incremental reads
+type TranscriptQuery struct {
+	AfterSequence int64     // Only entries with a greater sequence number
+	Since         time.Time // Only entries at or after this time, ignored if zero
+	UserID        string    // Only entries from this speaker, ignored if empty
+	Limit         int       // Maximum entries to return, unlimited if <= 0
+	Alternate     string    // Read this alternate transcript instead of the live one
+}
+
+// TranscriptPage is the result of a TranscriptQuery
+type TranscriptPage struct {
+	Transcripts []Transcript `json:"transcripts"`
+	// NextCursor is passed as AfterSequence to continue reading. It advances past
+	// entries skipped by filters so polling never rescans them.
+	NextCursor int64 `json:"nextCursor"`
+	HasMore    bool  `json:"hasMore"`
+}
+
+// QueryTranscripts returns the transcripts of a session matching q in sequence order
+func (m *Manager) QueryTranscripts(sessionID string, q TranscriptQuery) (*TranscriptPage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	transcripts := session.Transcripts
+	if q.Alternate != "" {
+		alternate := findAlternate(session, q.Alternate)
+		if alternate == nil {
+			return nil, fmt.Errorf("alternate transcript %s not found in session %s", q.Alternate, sessionID)
+		}
+		transcripts = alternate.Transcripts
+	}
+
+	page := &TranscriptPage{
+		Transcripts: []Transcript{},
+		NextCursor:  q.AfterSequence,
+	}
+
+	for _, t := range transcripts {
+		if t.Sequence <= q.AfterSequence {
+			continue
+		}
+		if q.Limit > 0 && len(page.Transcripts) == q.Limit {
+			page.HasMore = true
+			break
+		}
+		page.NextCursor = t.Sequence
+
+		if !q.Since.IsZero() && t.Timestamp.Before(q.Since) {
+			continue
+		}
+		if q.UserID != "" && t.UserID != q.UserID {
+			continue
+		}
+		page.Transcripts = append(page.Transcripts, t)
+	}
+
+	return page, nil
+}
+
+// findAlternate returns a session's alternate transcript by ID, or nil
+func findAlternate(session *Session, id string) *Alternate {
+	for i := range session.Alternates {
+		if session.Alternates[i].ID == id {
+			return &session.Alternates[i]
+		}
+	}
+	return nil
+}
+
+// UtterancePage is the result of QueryUtterances
+type UtterancePage struct {
+	Utterances []Utterance `json:"utterances"`
+	NextCursor int64       `json:"nextCursor"` // Transcript sequence to continue from
+	HasMore    bool        `json:"hasMore"`
+}
+
+// QueryUtterances returns the utterances of a session that gained a segment
+// after q.AfterSequence, ordered by their newest segment. An utterance that grew
+// since the last read is returned again with its full text. Since is compared
+// with the time the newest segment was added.
+func (m *Manager) QueryUtterances(sessionID string, q TranscriptQuery) (*UtterancePage, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return nil, fmt.Errorf("session %s not found", sessionID)
+	}
+
+	changed := make([]Utterance, 0, len(session.Utterances))
+	for _, u := range session.Utterances {
+		if u.LastSequence > q.AfterSequence {
+			changed = append(changed, u)
+		}
+	}
+	sort.Slice(changed, func(i, j int) bool {
+		return changed[i].LastSequence < changed[j].LastSequence
+	})
+
+	page := &UtterancePage{
+		Utterances: []Utterance{},
+		NextCursor: q.AfterSequence,
+	}
+	for _, u := range changed {
+		if q.Limit > 0 && len(page.Utterances) == q.Limit {
+			page.HasMore = true
+			break
+		}
+		page.NextCursor = u.LastSequence
+
+		if !q.Since.IsZero() && u.Timestamp.Before(q.Since) {
+			continue
+		}
+		if q.UserID != "" && u.UserID != q.UserID {
+			continue
+		}
+		page.Utterances = append(page.Utterances, u)
+	}
+
+	return page, nil
+}
+
+// LatestSequence returns the sequence number of the newest transcript in a session, or 0 if it has none
+func (m *Manager) LatestSequence(sessionID string) (int64, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	session, exists := m.sessions[sessionID]
+	if !exists {
+		return 0, fmt.Errorf("session %s not found", sessionID)
+	}
+	return nextSequence(session) - 1, nil
+}
@@ -0,0 +1,137 @@
+package session
+
+import (
+	"fmt"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestTranscriptSequenceNumbers(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+
+	for i := 0; i < 3; i++ {
+		require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", fmt.Sprintf("Message %d", i)))
+	}
+
+	session, err := manager.GetSession(sessionID)
+	require.NoError(t, err)
+	for i, transcript := range session.Transcripts {
+		assert.Equal(t, int64(i+1), transcript.Sequence)
+	}
+}
+
+func TestTranscriptSequenceSurvivesRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "First"))
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "Second"))
+	require.NoError(t, manager.Close())
+
+	restored := newJournalManager(t, path)
+	defer func() { _ = restored.Close() }()
+	require.NoError(t, restored.AddTranscript(sessionID, "user-1", "User1", "Third"))
+
+	page, err := restored.QueryTranscripts(sessionID, TranscriptQuery{AfterSequence: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 1)
+	assert.Equal(t, "Third", page.Transcripts[0].Text)
+	assert.Equal(t, int64(3), page.Transcripts[0].Sequence)
+}
+
+func TestQueryTranscripts(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+
+	for i := 1; i <= 5; i++ {
+		userID := "user-1"
+		if i%2 == 0 {
+			userID = "user-2"
+		}
+		require.NoError(t, manager.AddTranscript(sessionID, userID, userID, fmt.Sprintf("Message %d", i)))
+	}
+
+	// Everything
+	page, err := manager.QueryTranscripts(sessionID, TranscriptQuery{})
+	require.NoError(t, err)
+	assert.Len(t, page.Transcripts, 5)
+	assert.Equal(t, int64(5), page.NextCursor)
+	assert.False(t, page.HasMore)
+
+	// Paging with a limit
+	page, err = manager.QueryTranscripts(sessionID, TranscriptQuery{Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 2)
+	assert.Equal(t, int64(2), page.NextCursor)
+	assert.True(t, page.HasMore)
+
+	page, err = manager.QueryTranscripts(sessionID, TranscriptQuery{AfterSequence: page.NextCursor, Limit: 2})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 2)
+	assert.Equal(t, "Message 3", page.Transcripts[0].Text)
+	assert.Equal(t, int64(4), page.NextCursor)
+
+	// Nothing new keeps the cursor in place
+	page, err = manager.QueryTranscripts(sessionID, TranscriptQuery{AfterSequence: 5})
+	require.NoError(t, err)
+	assert.Empty(t, page.Transcripts)
+	assert.Equal(t, int64(5), page.NextCursor)
+
+	// User filter still advances the cursor past other speakers
+	page, err = manager.QueryTranscripts(sessionID, TranscriptQuery{UserID: "user-2"})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 2)
+	assert.Equal(t, "Message 2", page.Transcripts[0].Text)
+	assert.Equal(t, "Message 4", page.Transcripts[1].Text)
+	assert.Equal(t, int64(5), page.NextCursor)
+
+	// Time filter
+	page, err = manager.QueryTranscripts(sessionID, TranscriptQuery{Since: time.Now().Add(time.Minute)})
+	require.NoError(t, err)
+	assert.Empty(t, page.Transcripts)
+
+	_, err = manager.QueryTranscripts("non-existent", TranscriptQuery{})
+	assert.Error(t, err)
+}
+
+func TestQueryAlternateTranscripts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscript(sessionID, "user-1", "User1", "live text"))
+
+	require.NoError(t, manager.AddAlternate(sessionID, Alternate{
+		ID:          "alt-1",
+		Transcriber: "whisper large",
+		Transcripts: []Transcript{
+			{Sequence: 1, UserID: "user-1", Username: "User1", Text: "better text"},
+			{Sequence: 2, UserID: "user-2", Username: "User2", Text: "missed entirely"},
+		},
+	}))
+	assert.Error(t, manager.AddAlternate("non-existent", Alternate{ID: "alt-2"}))
+	require.NoError(t, manager.Close())
+
+	// Alternates are journaled and leave the live transcript alone
+	restored := newJournalManager(t, path)
+	defer func() { _ = restored.Close() }()
+
+	page, err := restored.QueryTranscripts(sessionID, TranscriptQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 1)
+	assert.Equal(t, "live text", page.Transcripts[0].Text)
+
+	page, err = restored.QueryTranscripts(sessionID, TranscriptQuery{Alternate: "alt-1", UserID: "user-2"})
+	require.NoError(t, err)
+	require.Len(t, page.Transcripts, 1)
+	assert.Equal(t, "missed entirely", page.Transcripts[0].Text)
+	assert.Equal(t, int64(2), page.NextCursor)
+
+	_, err = restored.QueryTranscripts(sessionID, TranscriptQuery{Alternate: "unknown"})
+	assert.Error(t, err)
+}
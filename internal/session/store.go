@@ -0,0 +1,62 @@
+package session
+
+import (
+	"time"
+)
+
+// RecordType identifies the kind of change captured in a store record
+type RecordType string
+
+const (
+	// RecordSessionCreated is written when a new session starts
+	RecordSessionCreated RecordType = "session.created"
+	// RecordTranscriptAdded is written for every transcript appended to a session
+	RecordTranscriptAdded RecordType = "transcript.added"
+	// RecordSessionEnded is written when a session is closed
+	RecordSessionEnded RecordType = "session.ended"
+	// RecordLanguageSet is written when the session or a user's language changes
+	RecordLanguageSet RecordType = "language.set"
+	// RecordTranslationSet is written when a session's translation target changes
+	RecordTranslationSet RecordType = "translation.set"
+	// RecordTranscriptTranslated is written when a translation of a stored transcript completes
+	RecordTranscriptTranslated RecordType = "transcript.translated"
+	// RecordRecordingSet is written when a session's audio recording starts, gains a track or is deleted
+	RecordRecordingSet RecordType = "recording.set"
+	// RecordAlternateAdded is written when a re-transcription of a session completes
+	RecordAlternateAdded RecordType = "alternate.added"
+	// RecordSessionSnapshot holds a complete session and is written during compaction
+	RecordSessionSnapshot RecordType = "session.snapshot"
+)
+
+// Record is a single persisted change to a session
+type Record struct {
+	Type        RecordType  `json:"type"`
+	SessionID   string      `json:"sessionId"`
+	Timestamp   time.Time   `json:"timestamp"`
+	Session     *Session    `json:"session,omitempty"`
+	Transcript  *Transcript `json:"transcript,omitempty"`
+	EndTime     *time.Time  `json:"endTime,omitempty"`
+	UserID      string      `json:"userId,omitempty"`      // Language records only
+	Language    string      `json:"language,omitempty"`    // Language and translation records only
+	Sequence    int64       `json:"sequence,omitempty"`    // Transcript translation records only
+	Translation string      `json:"translation,omitempty"` // Transcript translation records only
+	Recording   *Recording  `json:"recording,omitempty"`
+	Alternate   *Alternate  `json:"alternate,omitempty"`
+}
+
+// Store is a pluggable storage backend for the session manager
+type Store interface {
+	// Append durably records a change to a session
+	Append(record Record) error
+
+	// Replay calls fn for every stored record in the order it was written
+	Replay(fn func(record Record) error) error
+
+	// Close releases the underlying storage
+	Close() error
+}
+
+// compactor is implemented by stores that can rewrite their history as snapshots
+type compactor interface {
+	Compact(sessions []*Session) error
+}
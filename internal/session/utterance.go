@@ -0,0 +1,213 @@
+package session
+
+import (
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+	"unicode/utf8"
+)
+
+const (
+	// utteranceMergeGap is the longest pause within one utterance
+	utteranceMergeGap = 1500 * time.Millisecond
+
+	// utteranceContinuationGap is a pause short enough that the buffer must have
+	// cut mid-sentence, so fragments merge whatever their punctuation says
+	utteranceContinuationGap = 300 * time.Millisecond
+
+	// maxUtteranceDuration stops monologues from becoming a single entry
+	maxUtteranceDuration = 30 * time.Second
+
+	// maxOverlapWords bounds the words checked for duplication at a fragment boundary
+	maxOverlapWords = 6
+
+	// utteranceLookback is how many recent utterances are searched for the speaker's open one
+	utteranceLookback = 8
+)
+
+// Utterance is consecutive transcript segments from one speaker merged into
+// what they actually said. Utterances are derived from Transcripts, which keep
+// the raw segments.
+type Utterance struct {
+	Sequence     int64     `json:"sequence"`     // Sequence of the first segment
+	LastSequence int64     `json:"lastSequence"` // Sequence of the newest segment merged in
+	Segments     []int64   `json:"segments"`     // Sequences of every merged segment
+	Timestamp    time.Time `json:"timestamp"`    // When the newest segment was added
+	UserID       string    `json:"userId"`
+	Username     string    `json:"username"`
+	Text         string    `json:"text"`
+	Start        time.Time `json:"start"` // When the speaker started
+	End          time.Time `json:"end"`   // When the speaker stopped
+	Language     string    `json:"language,omitempty"`
+
+	Translation         string `json:"translation,omitempty"`
+	TranslationLanguage string `json:"translationLanguage,omitempty"`
+}
+
+// addToUtterance merges a new segment into its speaker's open utterance, or starts a new one
+func addToUtterance(session *Session, t Transcript) {
+	start, end := t.Span()
+
+	for i, seen := len(session.Utterances)-1, 0; i >= 0 && seen < utteranceLookback; i-- {
+		seen++
+		u := &session.Utterances[i]
+		if u.UserID != t.UserID {
+			continue
+		}
+		if u.continuedBy(t, start, end) {
+			u.merge(t, start, end)
+			return
+		}
+		break
+	}
+
+	session.Utterances = append(session.Utterances, Utterance{
+		Sequence:            t.Sequence,
+		LastSequence:        t.Sequence,
+		Segments:            []int64{t.Sequence},
+		Timestamp:           t.Timestamp,
+		UserID:              t.UserID,
+		Username:            t.Username,
+		Text:                t.Text,
+		Start:               start,
+		End:                 end,
+		Language:            t.Language,
+		Translation:         t.Translation,
+		TranslationLanguage: t.TranslationLanguage,
+	})
+}
+
+// retranslateUtterance rebuilds the translation of the utterance holding a
+// segment whose translation arrived after the segment was merged
+func retranslateUtterance(session *Session, sequence int64) {
+	for i := len(session.Utterances) - 1; i >= 0; i-- {
+		u := &session.Utterances[i]
+		if !slices.Contains(u.Segments, sequence) {
+			continue
+		}
+
+		u.Translation, u.TranslationLanguage = "", ""
+		var end time.Time
+		for _, seq := range u.Segments {
+			j, found := transcriptIndex(session, seq)
+			if !found {
+				continue
+			}
+			t := session.Transcripts[j]
+			start, segmentEnd := t.Span()
+			if t.Translation != "" {
+				adjacent := !end.IsZero() && start.Sub(end) <= utteranceContinuationGap
+				u.Translation = joinFragments(u.Translation, t.Translation, adjacent)
+				u.TranslationLanguage = t.TranslationLanguage
+			}
+			if segmentEnd.After(end) {
+				end = segmentEnd
+			}
+		}
+		return
+	}
+}
+
+// continuedBy reports whether a segment is the rest of this utterance: it
+// follows closely, and either the pause was too short to be a sentence break
+// or the punctuation says the sentence goes on
+func (u *Utterance) continuedBy(t Transcript, start, end time.Time) bool {
+	gap := start.Sub(u.End)
+	if gap > utteranceMergeGap || end.Sub(u.Start) > maxUtteranceDuration {
+		return false
+	}
+	if u.Language != "" && t.Language != "" && u.Language != t.Language {
+		return false
+	}
+	if gap <= utteranceContinuationGap {
+		return true
+	}
+	return !endsSentence(u.Text) || startsLowercase(t.Text)
+}
+
+// merge appends a segment to the utterance
+func (u *Utterance) merge(t Transcript, start, end time.Time) {
+	// A single repeated word is only a duplicate if the cut was mid-speech
+	adjacent := start.Sub(u.End) <= utteranceContinuationGap
+
+	u.Text = joinFragments(u.Text, t.Text, adjacent)
+	if t.Translation != "" {
+		u.Translation = joinFragments(u.Translation, t.Translation, adjacent)
+		u.TranslationLanguage = t.TranslationLanguage
+	}
+	if u.Language == "" {
+		u.Language = t.Language
+	}
+	if end.After(u.End) {
+		u.End = end
+	}
+	u.Username = t.Username
+	u.Timestamp = t.Timestamp
+	u.LastSequence = t.Sequence
+	u.Segments = append(u.Segments, t.Sequence)
+}
+
+// joinFragments appends next to prev, dropping words repeated across the
+// boundary and a period whisper put where the sentence didn't end
+func joinFragments(prev, next string, dropSingleWord bool) string {
+	nextWords := strings.Fields(next)
+	if prev == "" {
+		return strings.Join(nextWords, " ")
+	}
+
+	overlap := overlapWords(strings.Fields(prev), nextWords)
+	if overlap == 1 && !dropSingleWord {
+		overlap = 0
+	}
+	nextWords = nextWords[overlap:]
+	if len(nextWords) == 0 {
+		return prev
+	}
+
+	if strings.HasSuffix(prev, ".") && !strings.HasSuffix(prev, "..") && startsLowercase(nextWords[0]) {
+		prev = strings.TrimSuffix(prev, ".")
+	}
+	return prev + " " + strings.Join(nextWords, " ")
+}
+
+// overlapWords returns how many words at the start of next repeat the end of prev
+func overlapWords(prev, next []string) int {
+	for k := min(maxOverlapWords, len(prev), len(next)); k > 0; k-- {
+		match := true
+		for i := 0; i < k; i++ {
+			a, b := wordKey(prev[len(prev)-k+i]), wordKey(next[i])
+			if a == "" || a != b {
+				match = false
+				break
+			}
+		}
+		if match {
+			return k
+		}
+	}
+	return 0
+}
+
+// wordKey compares words ignoring case and surrounding punctuation
+func wordKey(word string) string {
+	return strings.ToLower(strings.TrimFunc(word, func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}))
+}
+
+// endsSentence reports whether text ends with sentence-final punctuation
+func endsSentence(text string) bool {
+	r, _ := utf8.DecodeLastRuneInString(strings.TrimSpace(text))
+	return strings.ContainsRune(".!?…。！？", r)
+}
+
+// startsLowercase reports whether the first letter of text is lowercase
+func startsLowercase(text string) bool {
+	for _, r := range text {
+		if unicode.IsLetter(r) {
+			return unicode.IsLower(r)
+		}
+	}
+	return false
+}
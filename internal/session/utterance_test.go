@@ -0,0 +1,197 @@
+package session
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fragment builds a transcript of audio starting at offset seconds into the session
+func fragment(base time.Time, userID, text string, offset, duration float64) Transcript {
+	start := base.Add(secondsToDuration(offset))
+	return Transcript{
+		UserID:     userID,
+		Username:   userID,
+		Text:       text,
+		AudioStart: start,
+		Duration:   duration,
+		Timestamp:  start.Add(secondsToDuration(duration + 0.5)),
+	}
+}
+
+func TestUtteranceAssembly(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	add := func(tr Transcript) {
+		require.NoError(t, manager.AddTranscriptEntry(sessionID, tr))
+	}
+
+	// Cut mid-sentence by the buffer, with a word repeated at the boundary
+	add(fragment(base, "alice", "So I think we should", 0, 3))
+	add(fragment(base, "alice", "should ship it.", 3.1, 1))
+	// Bob talks over her; his fragment doesn't interrupt her utterance
+	add(fragment(base, "bob", "Agreed.", 4.5, 0.6))
+	// Whisper ended the fragment with a period, but the sentence goes on
+	add(fragment(base, "alice", "today after lunch.", 4.8, 1.2))
+	// A real pause starts a new utterance
+	add(fragment(base, "alice", "Anyway.", 9, 0.5))
+	// A capitalised sentence after a sentence break, past the continuation gap
+	add(fragment(base, "alice", "Next topic.", 10, 1))
+
+	session, err := manager.GetSession(sessionID)
+	require.NoError(t, err)
+	require.Len(t, session.Transcripts, 6, "raw segments are kept")
+	require.Len(t, session.Utterances, 4)
+
+	first := session.Utterances[0]
+	assert.Equal(t, "So I think we should ship it today after lunch.", first.Text)
+	assert.Equal(t, []int64{1, 2, 4}, first.Segments)
+	assert.Equal(t, int64(4), first.LastSequence)
+	assert.Equal(t, base, first.Start)
+	assert.Equal(t, base.Add(6*time.Second), first.End)
+
+	assert.Equal(t, "Agreed.", session.Utterances[1].Text)
+	assert.Equal(t, "Anyway.", session.Utterances[2].Text)
+	assert.Equal(t, "Next topic.", session.Utterances[3].Text)
+}
+
+func TestJoinFragments(t *testing.T) {
+	tests := []struct {
+		prev, next string
+		adjacent   bool
+		expected   string
+	}{
+		{"", "Hello there", false, "Hello there"},
+		{"I went to the", "the store", true, "I went to the store"},
+		{"I said no.", "No way", false, "I said no. No way"},
+		{"we need to talk about", "talk about the release", false, "we need to talk about the release"},
+		{"It works.", "and it's fast", false, "It works and it's fast"},
+		{"Wait...", "what?", false, "Wait... what?"},
+		{"done", "Done.", true, "done"},
+	}
+
+	for _, tt := range tests {
+		assert.Equal(t, tt.expected, joinFragments(tt.prev, tt.next, tt.adjacent), tt.prev+" + "+tt.next)
+	}
+}
+
+func TestQueryUtterances(t *testing.T) {
+	manager := NewManager()
+	sessionID := manager.CreateSession("guild", "channel")
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	require.NoError(t, manager.AddTranscriptEntry(sessionID, fragment(base, "alice", "Let me check", 0, 2)))
+	require.NoError(t, manager.AddTranscriptEntry(sessionID, fragment(base, "bob", "Sure.", 2.5, 0.5)))
+
+	page, err := manager.QueryUtterances(sessionID, TranscriptQuery{})
+	require.NoError(t, err)
+	require.Len(t, page.Utterances, 2)
+	assert.Equal(t, int64(2), page.NextCursor)
+
+	// Alice keeps talking; her utterance is returned again, complete
+	require.NoError(t, manager.AddTranscriptEntry(sessionID, fragment(base, "alice", "the dashboard.", 2.1, 1)))
+	page, err = manager.QueryUtterances(sessionID, TranscriptQuery{AfterSequence: page.NextCursor})
+	require.NoError(t, err)
+	require.Len(t, page.Utterances, 1)
+	assert.Equal(t, "Let me check the dashboard.", page.Utterances[0].Text)
+	assert.Equal(t, int64(1), page.Utterances[0].Sequence)
+	assert.Equal(t, int64(3), page.NextCursor)
+
+	// Paging follows the newest segment of each utterance
+	page, err = manager.QueryUtterances(sessionID, TranscriptQuery{Limit: 1})
+	require.NoError(t, err)
+	require.Len(t, page.Utterances, 1)
+	assert.Equal(t, "bob", page.Utterances[0].UserID)
+	assert.True(t, page.HasMore)
+
+	page, err = manager.QueryUtterances(sessionID, TranscriptQuery{UserID: "bob"})
+	require.NoError(t, err)
+	require.Len(t, page.Utterances, 1)
+	assert.Equal(t, int64(3), page.NextCursor)
+
+	_, err = manager.QueryUtterances("missing", TranscriptQuery{})
+	assert.Error(t, err)
+}
+
+func TestUtterancesRebuiltAfterRestart(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	require.NoError(t, manager.AddTranscriptEntry(sessionID, fragment(base, "alice", "One two", 0, 1)))
+	require.NoError(t, manager.AddTranscriptEntry(sessionID, fragment(base, "alice", "three four.", 1.1, 1)))
+	require.NoError(t, manager.Close())
+
+	restored := newJournalManager(t, path)
+	defer func() { _ = restored.Close() }()
+	session, err := restored.GetSession(sessionID)
+	require.NoError(t, err)
+	require.Len(t, session.Utterances, 1)
+	assert.Equal(t, "One two three four.", session.Utterances[0].Text)
+}
+
+func TestTranslationArrivesAfterTranscript(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "sessions.jsonl")
+	base := time.Date(2025, 1, 1, 12, 0, 0, 0, time.UTC)
+
+	manager := newJournalManager(t, path)
+	sessionID := manager.CreateSession("guild", "channel")
+	first, err := manager.AppendTranscript(sessionID, fragment(base, "alice", "Eins zwei", 0, 1))
+	require.NoError(t, err)
+	second, err := manager.AppendTranscript(sessionID, fragment(base, "alice", "drei vier.", 1.1, 1))
+	require.NoError(t, err)
+
+	// Translations may complete out of order
+	require.NoError(t, manager.SetTranslation(sessionID, second, "three four.", "en"))
+	require.NoError(t, manager.SetTranslation(sessionID, first, "One two", "en"))
+	assert.Error(t, manager.SetTranslation(sessionID, 99, "lost", "en"))
+
+	check := func(m *Manager) {
+		session, err := m.GetSession(sessionID)
+		require.NoError(t, err)
+		assert.Equal(t, "One two", session.Transcripts[0].Translation)
+		assert.Equal(t, "en", session.Transcripts[1].TranslationLanguage)
+		require.Len(t, session.Utterances, 1)
+		assert.Equal(t, "One two three four.", session.Utterances[0].Translation)
+		assert.Equal(t, "en", session.Utterances[0].TranslationLanguage)
+	}
+	check(manager)
+	require.NoError(t, manager.Close())
+
+	restored := newJournalManager(t, path)
+	defer func() { _ = restored.Close() }()
+	check(restored)
+}
+
+func TestTranscriptSpan(t *testing.T) {
+	base := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+
+	captured := Transcript{AudioStart: base, AudioEnd: base.Add(2500 * time.Millisecond), Duration: 2}
+	start, end := captured.Span()
+	assert.Equal(t, base, start)
+	assert.Equal(t, base.Add(2500*time.Millisecond), end)
+
+	// Without capture times the span ends when the transcript was added
+	estimated := Transcript{Timestamp: base.Add(10 * time.Second), Duration: 4}
+	start, end = estimated.Span()
+	assert.Equal(t, base.Add(6*time.Second), start)
+	assert.Equal(t, base.Add(10*time.Second), end)
+}
+
+func TestSortBySpeechStart(t *testing.T) {
+	base := time.Date(2025, 1, 2, 15, 0, 0, 0, time.UTC)
+	transcripts := []Transcript{
+		{Sequence: 1, Text: "reply", AudioStart: base.Add(4 * time.Second), Duration: 1},
+		{Sequence: 2, Text: "question", AudioStart: base, Duration: 3},
+		{Sequence: 3, Text: "overlap", AudioStart: base.Add(4 * time.Second), Duration: 2},
+	}
+
+	SortBySpeechStart(transcripts)
+	assert.Equal(t, []int64{2, 1, 3}, []int64{transcripts[0].Sequence, transcripts[1].Sequence, transcripts[2].Sequence})
+}
@@ -0,0 +1,200 @@
+package vocabulary
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// MaxTermLength is the longest term accepted, in characters
+	MaxTermLength = 64
+
+	// MaxTermsPerGuild bounds a guild's list; only the first few dozen fit in a prompt anyway
+	MaxTermsPerGuild = 200
+)
+
+// Store keeps a vocabulary list per guild: game names, nicknames and jargon
+// the transcriber should spell correctly. With a path, every change is written
+// to a JSON file so lists survive restarts.
+type Store struct {
+	path   string
+	guilds map[string][]string
+	mu     sync.RWMutex
+}
+
+// NewStore creates an in-memory vocabulary store
+func NewStore() *Store {
+	return &Store{guilds: make(map[string][]string)}
+}
+
+// Open loads the vocabulary file at path, creating it on the first change if it doesn't exist
+func Open(path string) (*Store, error) {
+	s := NewStore()
+	s.path = path
+
+	// #nosec G304 - vocabulary path is controlled by server configuration
+	data, err := os.ReadFile(path)
+	switch {
+	case errors.Is(err, os.ErrNotExist):
+	case err != nil:
+		return nil, fmt.Errorf("error reading vocabulary: %w", err)
+	default:
+		if err := json.Unmarshal(data, &s.guilds); err != nil {
+			return nil, fmt.Errorf("error decoding vocabulary: %w", err)
+		}
+		if s.guilds == nil {
+			s.guilds = make(map[string][]string)
+		}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"path":   path,
+		"guilds": len(s.guilds),
+	}).Debug("Vocabulary loaded")
+
+	return s, nil
+}
+
+// List returns a guild's terms in the order they were added
+func (s *Store) List(guildID string) []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return append([]string(nil), s.guilds[guildID]...)
+}
+
+// Add appends terms to a guild's list, skipping ones already present (case-insensitively).
+// It returns the terms that were added.
+func (s *Store) Add(guildID string, terms []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	current := s.guilds[guildID]
+	var added []string
+	for _, term := range terms {
+		term = strings.Join(strings.Fields(term), " ")
+		if term == "" {
+			continue
+		}
+		if utf8.RuneCountInString(term) > MaxTermLength {
+			return nil, fmt.Errorf("term %q is longer than %d characters", term, MaxTermLength)
+		}
+		if indexOf(current, term) >= 0 {
+			continue
+		}
+		if len(current) >= MaxTermsPerGuild {
+			return nil, fmt.Errorf("vocabulary is full (%d terms)", MaxTermsPerGuild)
+		}
+		current = append(current, term)
+		added = append(added, term)
+	}
+	if len(added) == 0 {
+		return nil, nil
+	}
+
+	s.guilds[guildID] = current
+	if err := s.saveLocked(); err != nil {
+		s.guilds[guildID] = current[:len(current)-len(added)]
+		return nil, err
+	}
+	return added, nil
+}
+
+// Remove deletes terms from a guild's list (case-insensitively) and returns the ones removed
+func (s *Store) Remove(guildID string, terms []string) ([]string, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	previous := s.guilds[guildID]
+	current := append([]string(nil), previous...)
+	var removed []string
+	for _, term := range terms {
+		if i := indexOf(current, strings.Join(strings.Fields(term), " ")); i >= 0 {
+			removed = append(removed, current[i])
+			current = append(current[:i], current[i+1:]...)
+		}
+	}
+	if len(removed) == 0 {
+		return nil, nil
+	}
+
+	if len(current) == 0 {
+		delete(s.guilds, guildID)
+	} else {
+		s.guilds[guildID] = current
+	}
+	if err := s.saveLocked(); err != nil {
+		s.guilds[guildID] = previous
+		return nil, err
+	}
+	return removed, nil
+}
+
+// saveLocked writes every list to the vocabulary file, if there is one.
+// Caller must hold s.mu.
+func (s *Store) saveLocked() error {
+	if s.path == "" {
+		return nil
+	}
+
+	data, err := json.MarshalIndent(s.guilds, "", "  ")
+	if err != nil {
+		return fmt.Errorf("error encoding vocabulary: %w", err)
+	}
+	if dir := filepath.Dir(s.path); dir != "" {
+		if err := os.MkdirAll(dir, 0750); err != nil {
+			return fmt.Errorf("error creating vocabulary directory: %w", err)
+		}
+	}
+
+	// Write to a temporary file first so a crash never leaves a truncated file
+	tmpPath := s.path + ".tmp"
+	if err := os.WriteFile(tmpPath, data, 0600); err != nil {
+		return fmt.Errorf("error writing vocabulary: %w", err)
+	}
+	if err := os.Rename(tmpPath, s.path); err != nil {
+		_ = os.Remove(tmpPath)
+		return fmt.Errorf("error replacing vocabulary: %w", err)
+	}
+	return nil
+}
+
+// Merge combines term lists, keeping the first spelling of each term
+func Merge(lists ...[]string) []string {
+	var merged []string
+	for _, list := range lists {
+		for _, term := range list {
+			if term != "" && indexOf(merged, term) < 0 {
+				merged = append(merged, term)
+			}
+		}
+	}
+	return merged
+}
+
+// Sorted returns a sorted copy of terms, for display
+func Sorted(terms []string) []string {
+	sorted := append([]string(nil), terms...)
+	sort.Slice(sorted, func(i, j int) bool {
+		return strings.ToLower(sorted[i]) < strings.ToLower(sorted[j])
+	})
+	return sorted
+}
+
+// indexOf finds term in terms, ignoring case
+func indexOf(terms []string, term string) int {
+	for i, t := range terms {
+		if strings.EqualFold(t, term) {
+			return i
+		}
+	}
+	return -1
+}
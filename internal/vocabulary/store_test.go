@@ -0,0 +1,49 @@
+package vocabulary
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStoreAddRemove(t *testing.T) {
+	s := NewStore()
+
+	added, err := s.Add("guild", []string{"Valheim", " Deep  Rock Galactic ", "", "valheim"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Valheim", "Deep Rock Galactic"}, added)
+	assert.Equal(t, []string{"Valheim", "Deep Rock Galactic"}, s.List("guild"))
+	assert.Empty(t, s.List("other"))
+
+	removed, err := s.Remove("guild", []string{"VALHEIM", "missing"})
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Valheim"}, removed)
+	assert.Equal(t, []string{"Deep Rock Galactic"}, s.List("guild"))
+
+	_, err = s.Add("guild", []string{strings.Repeat("x", MaxTermLength+1)})
+	assert.Error(t, err)
+}
+
+func TestStorePersists(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "data", "vocabulary.json")
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	_, err = s.Add("guild", []string{"Kubernetes", "Grafana"})
+	require.NoError(t, err)
+	_, err = s.Remove("guild", []string{"grafana"})
+	require.NoError(t, err)
+
+	reopened, err := Open(path)
+	require.NoError(t, err)
+	assert.Equal(t, []string{"Kubernetes"}, reopened.List("guild"))
+}
+
+func TestMerge(t *testing.T) {
+	merged := Merge([]string{"Valheim", "Alice"}, []string{"alice", "", "Bob"})
+	assert.Equal(t, []string{"Valheim", "Alice", "Bob"}, merged)
+	assert.Equal(t, []string{"alice", "Bob", "Valheim"}, Sorted([]string{"Valheim", "alice", "Bob"}))
+}
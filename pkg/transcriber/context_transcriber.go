@@ -1,14 +1,22 @@
 package transcriber
 
-import "strings"
+import (
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
 
 const (
 	// ContextWordCount is the number of words to use as context from previous transcripts
 	ContextWordCount = 30
+
+	// PromptTokenBudget is the rough number of tokens a whisper prompt may use.
+	// whisper keeps at most 224 prompt tokens and silently drops the oldest ones.
+	PromptTokenBudget = 200
 )
 
 // NOTE: TranscribeOptions and ContextAwareTranscriber have been moved to interface.go
-// This file now only contains the helper function CreateContextPrompt
+// This file now only contains the prompt helpers CreateContextPrompt and CreatePrompt
 
 // CreateContextPrompt creates a prompt from the previous transcript for whisper
 // It takes the last N words (ContextWordCount) to stay within token limits
@@ -45,3 +53,58 @@ func CreateContextPrompt(previousTranscript string) string {
 	}
 	return strings.Join(words, " ")
 }
+
+// CreatePrompt builds a whisper prompt from custom vocabulary and the previous transcript.
+// The context from CreateContextPrompt is reserved first; vocabulary terms are then
+// added in order, as a comma-separated list in front of it, until the token budget is used.
+func CreatePrompt(vocabulary []string, previousTranscript string) string {
+	context := CreateContextPrompt(previousTranscript)
+	budget := PromptTokenBudget - estimateTokens(context)
+
+	var terms []string
+	for _, term := range vocabulary {
+		term = cleanVocabularyTerm(term)
+		if term == "" {
+			continue
+		}
+		cost := estimateTokens(term) + 1 // separator
+		if cost > budget {
+			break
+		}
+		budget -= cost
+		terms = append(terms, term)
+	}
+
+	if len(terms) == 0 {
+		return context
+	}
+	glossary := strings.Join(terms, ", ") + "."
+	if context == "" {
+		return glossary
+	}
+	return glossary + " " + context
+}
+
+// cleanVocabularyTerm keeps letters, digits and the punctuation names commonly use
+func cleanVocabularyTerm(term string) string {
+	clean := strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsLetter(r), unicode.IsDigit(r):
+			return r
+		case r == '-' || r == '\'' || r == '.':
+			return r
+		default:
+			return ' '
+		}
+	}, term)
+	return strings.Join(strings.Fields(clean), " ")
+}
+
+// estimateTokens approximates whisper's token count; names and jargon split into
+// more tokens than ordinary words, so this errs on the high side
+func estimateTokens(text string) int {
+	if text == "" {
+		return 0
+	}
+	return (utf8.RuneCountInString(text) + 2) / 3
+}
@@ -1,6 +1,8 @@
 package transcriber
 
 import (
+	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/stretchr/testify/assert"
@@ -348,3 +350,26 @@ func TestContextPromptEdgeCases(t *testing.T) {
 		})
 	}
 }
+
+// TestCreatePrompt tests folding vocabulary into the prompt
+func TestCreatePrompt(t *testing.T) {
+	assert.Equal(t, "", CreatePrompt(nil, ""))
+	assert.Equal(t, "Hello world", CreatePrompt(nil, "Hello world"))
+	assert.Equal(t, "Kubernetes, O'Brien, Lys-Ækir.", CreatePrompt([]string{"Kubernetes", "  O'Brien ", "Lys-Ækir", "@#!"}, ""))
+	assert.Equal(t, "Grafana, Loki. we deployed it", CreatePrompt([]string{"Grafana", "Loki"}, "we deployed it"))
+
+	t.Run("budget_keeps_context", func(t *testing.T) {
+		context := strings.Repeat("context ", 40)
+		var vocabulary []string
+		for i := 0; i < 200; i++ {
+			vocabulary = append(vocabulary, fmt.Sprintf("Term%03d", i))
+		}
+
+		prompt := CreatePrompt(vocabulary, context)
+
+		assert.True(t, strings.HasPrefix(prompt, "Term000, Term001"), "vocabulary is kept in order")
+		assert.True(t, strings.HasSuffix(prompt, CreateContextPrompt(context)), "context is never dropped")
+		assert.NotContains(t, prompt, "Term199")
+		assert.LessOrEqual(t, estimateTokens(prompt), PromptTokenBudget+1)
+	})
+}
@@ -0,0 +1,60 @@
+package transcriber
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/sirupsen/logrus"
+)
+
+// ErrUnknownTranscriber is returned by New for a type name it doesn't know
+var ErrUnknownTranscriber = errors.New("unknown transcriber")
+
+// New creates a transcriber by type name: whisper, whisper-server, openai,
+// google or mock. model is the whisper model path, or the OpenAI model name
+// (empty for OPENAI_TRANSCRIBE_MODEL). Other settings come from the environment.
+func New(kind, model string) (Transcriber, error) {
+	var trans Transcriber
+	var err error
+	switch strings.ToLower(kind) {
+	case "whisper":
+		if model == "" {
+			return nil, errors.New("a whisper model path is required")
+		}
+		if os.Getenv("WHISPER_USE_GPU") == "true" || os.Getenv("WHISPER_USE_GPU") == "" {
+			trans, err = NewGPUWhisperTranscriber(model)
+			if err == nil {
+				return trans, nil
+			}
+			logrus.WithError(err).Warn("Failed to initialize GPU Whisper transcriber, falling back to CPU")
+		}
+		trans, err = NewWhisperTranscriber(model)
+	case "whisper-server":
+		if model == "" {
+			return nil, errors.New("a whisper model path is required")
+		}
+		trans, err = NewWhisperServerTranscriber(model)
+	case "openai":
+		if model == "" {
+			model = os.Getenv("OPENAI_TRANSCRIBE_MODEL")
+		}
+		trans, err = NewOpenAITranscriberWithConfig(OpenAIConfig{
+			BaseURL:  os.Getenv("OPENAI_BASE_URL"),
+			APIKey:   os.Getenv("OPENAI_API_KEY"),
+			Model:    model,
+			Language: os.Getenv("OPENAI_LANGUAGE"),
+		})
+	case "google":
+		trans, err = NewGoogleTranscriber()
+	case "mock":
+		trans = &MockTranscriber{}
+	default:
+		return nil, fmt.Errorf("%w %q, use whisper, whisper-server, openai, google, or mock", ErrUnknownTranscriber, kind)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return trans, nil
+}
@@ -0,0 +1,24 @@
+package transcriber
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNew(t *testing.T) {
+	trans, err := New("Mock", "")
+	require.NoError(t, err)
+	assert.IsType(t, &MockTranscriber{}, trans)
+
+	_, err = New("whisper", "")
+	assert.ErrorContains(t, err, "model path is required")
+
+	_, err = New("whisper-server", "")
+	assert.ErrorContains(t, err, "model path is required")
+
+	_, err = New("vosk", "")
+	assert.ErrorIs(t, err, ErrUnknownTranscriber)
+	assert.ErrorContains(t, err, `unknown transcriber "vosk"`)
+}
@@ -0,0 +1,416 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultGoogleEndpoint is the Cloud Speech-to-Text v1 REST API
+	DefaultGoogleEndpoint = "https://speech.googleapis.com"
+
+	defaultGoogleLanguage = "en-US"
+	googleRequestTimeout  = 30 * time.Second
+	googleMaxAlternatives = 30
+
+	googleTokenAudience = "https://speech.googleapis.com/"
+	googleTokenLifetime = time.Hour
+)
+
+// GoogleConfig configures the Google Cloud Speech-to-Text transcriber.
+// One of APIKey, AccessToken or CredentialsFile is required.
+type GoogleConfig struct {
+	APIKey          string       // API key sent as the key query parameter
+	AccessToken     string       // OAuth2 access token sent as a bearer token
+	CredentialsFile string       // Service account JSON key, used to sign self-issued JWTs
+	Endpoint        string       // Base URL (default: DefaultGoogleEndpoint)
+	Language        string       // BCP-47 language used when the request doesn't set one (default: en-US)
+	Model           string       // Recognition model, e.g. "latest_short" (optional)
+	HTTPClient      *http.Client // Optional custom client
+}
+
+// GoogleTranscriber uses the Google Cloud Speech-to-Text REST API
+type GoogleTranscriber struct {
+	config GoogleConfig
+	client *http.Client
+	signer *serviceAccountSigner
+}
+
+// NewGoogleTranscriber creates a Google transcriber configured from the environment:
+// GOOGLE_API_KEY, GOOGLE_ACCESS_TOKEN or GOOGLE_APPLICATION_CREDENTIALS for auth,
+// plus optional GOOGLE_SPEECH_ENDPOINT, GOOGLE_SPEECH_LANGUAGE and GOOGLE_SPEECH_MODEL.
+func NewGoogleTranscriber() (*GoogleTranscriber, error) {
+	return NewGoogleTranscriberWithConfig(GoogleConfig{
+		APIKey:          os.Getenv("GOOGLE_API_KEY"),
+		AccessToken:     os.Getenv("GOOGLE_ACCESS_TOKEN"),
+		CredentialsFile: os.Getenv("GOOGLE_APPLICATION_CREDENTIALS"),
+		Endpoint:        os.Getenv("GOOGLE_SPEECH_ENDPOINT"),
+		Language:        os.Getenv("GOOGLE_SPEECH_LANGUAGE"),
+		Model:           os.Getenv("GOOGLE_SPEECH_MODEL"),
+	})
+}
+
+// NewGoogleTranscriberWithConfig creates a Google transcriber from explicit settings
+func NewGoogleTranscriberWithConfig(config GoogleConfig) (*GoogleTranscriber, error) {
+	if config.Endpoint == "" {
+		config.Endpoint = DefaultGoogleEndpoint
+	}
+	config.Endpoint = strings.TrimRight(config.Endpoint, "/")
+	if config.Language == "" {
+		config.Language = defaultGoogleLanguage
+	}
+
+	gt := &GoogleTranscriber{config: config, client: config.HTTPClient}
+	if gt.client == nil {
+		gt.client = &http.Client{Timeout: googleRequestTimeout}
+	}
+
+	switch {
+	case config.APIKey != "", config.AccessToken != "":
+	case config.CredentialsFile != "":
+		signer, err := loadServiceAccount(config.CredentialsFile)
+		if err != nil {
+			return nil, err
+		}
+		gt.signer = signer
+	default:
+		return nil, fmt.Errorf("google credentials required: set GOOGLE_API_KEY, GOOGLE_ACCESS_TOKEN or GOOGLE_APPLICATION_CREDENTIALS")
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"endpoint": config.Endpoint,
+		"language": config.Language,
+		"model":    config.Model,
+	}).Info("Google Speech-to-Text transcriber initialized")
+
+	return gt, nil
+}
+
+// Transcribe implements the basic Transcriber interface
+func (gt *GoogleTranscriber) Transcribe(audio []byte) (string, error) {
+	result, err := gt.TranscribeWithContext(audio, TranscriptionOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// TranscribeWithContext sends 48kHz stereo PCM to the recognize API
+func (gt *GoogleTranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	startTime := time.Now()
+
+	// The recognize API needs a language code, so "auto" keeps the configured one
+	language := gt.config.Language
+	if opts.Language != "" && opts.Language != "auto" {
+		language = opts.Language
+	}
+
+	request := googleRecognizeRequest{
+		Config: googleRecognitionConfig{
+			Encoding:                   "LINEAR16",
+			SampleRateHertz:            pcm.SpeechSampleRate, // Google's recommended rate
+			LanguageCode:               language,
+			MaxAlternatives:            min(opts.MaxAlternatives, googleMaxAlternatives),
+			EnableWordTimeOffsets:      opts.EnableTimestamps,
+			EnableWordConfidence:       opts.EnableTimestamps,
+			EnableAutomaticPunctuation: true,
+			Model:                      gt.config.Model,
+		},
+		Audio: googleRecognitionAudio{
+			Content: base64.StdEncoding.EncodeToString(pcm.SamplesToBytes(pcm.SpeechPCM(audio))),
+		},
+	}
+	if len(opts.CustomVocabulary) > 0 {
+		request.Config.SpeechContexts = []googleSpeechContext{{Phrases: opts.CustomVocabulary}}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audio_bytes": len(audio),
+		"language":    language,
+		"phrases":     len(opts.CustomVocabulary),
+	}).Debug("GoogleTranscriber: Starting transcription")
+
+	response, err := gt.recognize(request)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildGoogleResult(response)
+	if result.Language == "" {
+		result.Language = language
+	}
+	result.Duration = time.Since(startTime)
+
+	if result.Text == "" {
+		logrus.Debug("GoogleTranscriber: No speech detected")
+		result.Text = "[No speech detected]"
+	}
+	return result, nil
+}
+
+// IsReady reports whether credentials are configured
+func (gt *GoogleTranscriber) IsReady() bool {
+	return gt.config.APIKey != "" || gt.config.AccessToken != "" || gt.signer != nil
+}
+
+// Close releases idle HTTP connections
+func (gt *GoogleTranscriber) Close() error {
+	gt.client.CloseIdleConnections()
+	return nil
+}
+
+// recognize performs one speech:recognize call
+func (gt *GoogleTranscriber) recognize(request googleRecognizeRequest) (*googleRecognizeResponse, error) {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return nil, fmt.Errorf("error encoding recognize request: %w", err)
+	}
+
+	endpoint := gt.config.Endpoint + "/v1/speech:recognize"
+	if gt.config.APIKey != "" {
+		endpoint += "?key=" + url.QueryEscape(gt.config.APIKey)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), googleRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, endpoint, bytes.NewReader(body))
+	if err != nil {
+		return nil, fmt.Errorf("error creating recognize request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	switch {
+	case gt.config.AccessToken != "":
+		req.Header.Set("Authorization", "Bearer "+gt.config.AccessToken)
+	case gt.signer != nil:
+		token, err := gt.signer.token(time.Now())
+		if err != nil {
+			return nil, err
+		}
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	resp, err := gt.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("google recognize request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading recognize response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr googleErrorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("google recognize failed (%d %s): %s", resp.StatusCode, apiErr.Error.Status, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("google recognize failed with status %d", resp.StatusCode)
+	}
+
+	var response googleRecognizeResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error decoding recognize response: %w", err)
+	}
+	return &response, nil
+}
+
+// buildGoogleResult joins the best alternative of each result into one transcript.
+// Alternatives are only reported for single-result responses, where they are complete.
+func buildGoogleResult(response *googleRecognizeResponse) *TranscriptResult {
+	result := &TranscriptResult{}
+
+	var texts []string
+	var confidence float32
+	var scored int
+	for _, r := range response.Results {
+		if len(r.Alternatives) == 0 {
+			continue
+		}
+		best := r.Alternatives[0]
+		if text := strings.TrimSpace(best.Transcript); text != "" {
+			texts = append(texts, text)
+		}
+		if best.Confidence > 0 {
+			confidence += best.Confidence
+			scored++
+		}
+		for _, w := range best.Words {
+			result.Words = append(result.Words, WordTiming{
+				Word:       w.Word,
+				StartTime:  parseGoogleDuration(w.StartTime),
+				EndTime:    parseGoogleDuration(w.EndTime),
+				Confidence: w.Confidence,
+			})
+		}
+		if result.Language == "" {
+			result.Language = r.LanguageCode
+		}
+	}
+
+	result.Text = strings.Join(texts, " ")
+	if scored > 0 {
+		result.Confidence = confidence / float32(scored)
+	}
+
+	if len(response.Results) == 1 {
+		for _, alt := range response.Results[0].Alternatives[min(1, len(response.Results[0].Alternatives)):] {
+			result.Alternatives = append(result.Alternatives, Alternative{
+				Text:       strings.TrimSpace(alt.Transcript),
+				Confidence: alt.Confidence,
+			})
+		}
+	}
+
+	return result
+}
+
+// parseGoogleDuration parses durations such as "1.300s"
+func parseGoogleDuration(s string) time.Duration {
+	d, err := time.ParseDuration(s)
+	if err != nil {
+		return 0
+	}
+	return d
+}
+
+// serviceAccountSigner issues self-signed JWT access tokens from a service
+// account key, which Google APIs accept without an OAuth token exchange
+type serviceAccountSigner struct {
+	email string
+	keyID string
+	key   *rsa.PrivateKey
+}
+
+// loadServiceAccount reads a service account JSON key file
+func loadServiceAccount(path string) (*serviceAccountSigner, error) {
+	// #nosec G304 - credentials path is server configuration, not user input
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("error reading google credentials: %w", err)
+	}
+
+	var account struct {
+		Type         string `json:"type"`
+		ClientEmail  string `json:"client_email"`
+		PrivateKeyID string `json:"private_key_id"`
+		PrivateKey   string `json:"private_key"`
+	}
+	if err := json.Unmarshal(data, &account); err != nil {
+		return nil, fmt.Errorf("error parsing google credentials: %w", err)
+	}
+	if account.Type != "service_account" || account.ClientEmail == "" || account.PrivateKey == "" {
+		return nil, fmt.Errorf("google credentials must be a service account key")
+	}
+
+	block, _ := pem.Decode([]byte(account.PrivateKey))
+	if block == nil {
+		return nil, fmt.Errorf("google credentials contain no PEM private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return nil, fmt.Errorf("error parsing google private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return nil, fmt.Errorf("google private key is not an RSA key")
+	}
+
+	return &serviceAccountSigner{email: account.ClientEmail, keyID: account.PrivateKeyID, key: key}, nil
+}
+
+// token returns a JWT valid for the Speech API for the next hour
+func (s *serviceAccountSigner) token(now time.Time) (string, error) {
+	header, err := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT", "kid": s.keyID})
+	if err != nil {
+		return "", err
+	}
+	claims, err := json.Marshal(map[string]any{
+		"iss": s.email,
+		"sub": s.email,
+		"aud": googleTokenAudience,
+		"iat": now.Unix(),
+		"exp": now.Add(googleTokenLifetime).Unix(),
+	})
+	if err != nil {
+		return "", err
+	}
+
+	signingInput := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(signingInput))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, s.key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("error signing google token: %w", err)
+	}
+	return signingInput + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
+
+// Cloud Speech-to-Text v1 REST types
+
+type googleRecognizeRequest struct {
+	Config googleRecognitionConfig `json:"config"`
+	Audio  googleRecognitionAudio  `json:"audio"`
+}
+
+type googleRecognitionConfig struct {
+	Encoding                   string                `json:"encoding"`
+	SampleRateHertz            int                   `json:"sampleRateHertz"`
+	LanguageCode               string                `json:"languageCode"`
+	MaxAlternatives            int                   `json:"maxAlternatives,omitempty"`
+	EnableWordTimeOffsets      bool                  `json:"enableWordTimeOffsets,omitempty"`
+	EnableWordConfidence       bool                  `json:"enableWordConfidence,omitempty"`
+	EnableAutomaticPunctuation bool                  `json:"enableAutomaticPunctuation,omitempty"`
+	SpeechContexts             []googleSpeechContext `json:"speechContexts,omitempty"`
+	Model                      string                `json:"model,omitempty"`
+}
+
+type googleSpeechContext struct {
+	Phrases []string `json:"phrases"`
+}
+
+type googleRecognitionAudio struct {
+	Content string `json:"content"`
+}
+
+type googleRecognizeResponse struct {
+	Results []struct {
+		Alternatives []struct {
+			Transcript string  `json:"transcript"`
+			Confidence float32 `json:"confidence"`
+			Words      []struct {
+				StartTime  string  `json:"startTime"`
+				EndTime    string  `json:"endTime"`
+				Word       string  `json:"word"`
+				Confidence float32 `json:"confidence"`
+			} `json:"words"`
+		} `json:"alternatives"`
+		LanguageCode string `json:"languageCode"`
+	} `json:"results"`
+}
+
+type googleErrorResponse struct {
+	Error struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+		Status  string `json:"status"`
+	} `json:"error"`
+}
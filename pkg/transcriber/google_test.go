@@ -0,0 +1,193 @@
+package transcriber
+
+import (
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeGoogleServer records the last recognize request and replies with response
+func fakeGoogleServer(t *testing.T, status int, response string) (*httptest.Server, *http.Request, *googleRecognizeRequest) {
+	t.Helper()
+	var lastReq http.Request
+	var lastBody googleRecognizeRequest
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		lastReq = *r.Clone(r.Context())
+		body, _ := io.ReadAll(r.Body)
+		_ = json.Unmarshal(body, &lastBody)
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, &lastReq, &lastBody
+}
+
+func TestGoogleTranscriberRequestAndResult(t *testing.T) {
+	response := `{
+		"results": [
+			{
+				"alternatives": [
+					{"transcript": "hello world", "confidence": 0.9, "words": [
+						{"startTime": "0s", "endTime": "0.400s", "word": "hello", "confidence": 0.95},
+						{"startTime": "0.400s", "endTime": "0.900s", "word": "world", "confidence": 0.85}
+					]}
+				],
+				"languageCode": "en-us"
+			},
+			{
+				"alternatives": [{"transcript": " again", "confidence": 0.7}]
+			}
+		]
+	}`
+	server, req, body := fakeGoogleServer(t, http.StatusOK, response)
+
+	gt, err := NewGoogleTranscriberWithConfig(GoogleConfig{APIKey: "test-key", Endpoint: server.URL + "/"})
+	require.NoError(t, err)
+	assert.True(t, gt.IsReady())
+
+	// 0.1s of 48kHz stereo silence
+	audio := make([]byte, 4800*4)
+	result, err := gt.TranscribeWithContext(audio, TranscriptionOptions{
+		Language:         "de-DE",
+		CustomVocabulary: []string{"Kubernetes", "Grafana"},
+		MaxAlternatives:  3,
+		EnableTimestamps: true,
+	})
+	require.NoError(t, err)
+
+	// Request
+	assert.Equal(t, "/v1/speech:recognize", req.URL.Path)
+	assert.Equal(t, "test-key", req.URL.Query().Get("key"))
+	assert.Equal(t, "LINEAR16", body.Config.Encoding)
+	assert.Equal(t, 16000, body.Config.SampleRateHertz)
+	assert.Equal(t, "de-DE", body.Config.LanguageCode)
+	assert.Equal(t, 3, body.Config.MaxAlternatives)
+	assert.True(t, body.Config.EnableWordTimeOffsets)
+	require.Len(t, body.Config.SpeechContexts, 1)
+	assert.Equal(t, []string{"Kubernetes", "Grafana"}, body.Config.SpeechContexts[0].Phrases)
+	content, err := base64.StdEncoding.DecodeString(body.Audio.Content)
+	require.NoError(t, err)
+	assert.Len(t, content, 1600*2, "downmixed to 16kHz mono")
+
+	// Result
+	assert.Equal(t, "hello world again", result.Text)
+	assert.InDelta(t, 0.8, result.Confidence, 0.001)
+	assert.Equal(t, "en-us", result.Language)
+	require.Len(t, result.Words, 2)
+	assert.Equal(t, "world", result.Words[1].Word)
+	assert.Equal(t, 400*time.Millisecond, result.Words[1].StartTime)
+	assert.Equal(t, 900*time.Millisecond, result.Words[1].EndTime)
+	assert.Empty(t, result.Alternatives, "alternatives are only reported for single results")
+}
+
+func TestGoogleTranscriberAlternatives(t *testing.T) {
+	response := `{"results": [{"alternatives": [
+		{"transcript": "recognize speech", "confidence": 0.8},
+		{"transcript": "wreck a nice beach", "confidence": 0.1}
+	]}]}`
+	server, req, body := fakeGoogleServer(t, http.StatusOK, response)
+
+	gt, err := NewGoogleTranscriberWithConfig(GoogleConfig{AccessToken: "token", Endpoint: server.URL, Language: "en-GB"})
+	require.NoError(t, err)
+
+	result, err := gt.TranscribeWithContext(make([]byte, 1200), TranscriptionOptions{Language: "auto", MaxAlternatives: 100})
+	require.NoError(t, err)
+
+	assert.Equal(t, "Bearer token", req.Header.Get("Authorization"))
+	assert.Equal(t, "en-GB", body.Config.LanguageCode, "auto falls back to the configured language")
+	assert.Equal(t, googleMaxAlternatives, body.Config.MaxAlternatives)
+	assert.Empty(t, body.Config.SpeechContexts)
+
+	assert.Equal(t, "recognize speech", result.Text)
+	assert.Equal(t, "en-GB", result.Language)
+	require.Len(t, result.Alternatives, 1)
+	assert.Equal(t, "wreck a nice beach", result.Alternatives[0].Text)
+}
+
+func TestGoogleTranscriberNoSpeech(t *testing.T) {
+	server, _, _ := fakeGoogleServer(t, http.StatusOK, `{}`)
+	gt, err := NewGoogleTranscriberWithConfig(GoogleConfig{APIKey: "key", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	text, err := gt.Transcribe(make([]byte, 1200))
+	require.NoError(t, err)
+	assert.Equal(t, "[No speech detected]", text)
+}
+
+func TestGoogleTranscriberAPIError(t *testing.T) {
+	server, _, _ := fakeGoogleServer(t, http.StatusForbidden,
+		`{"error": {"code": 403, "message": "API key not valid", "status": "PERMISSION_DENIED"}}`)
+	gt, err := NewGoogleTranscriberWithConfig(GoogleConfig{APIKey: "bad", Endpoint: server.URL})
+	require.NoError(t, err)
+
+	_, err = gt.Transcribe(make([]byte, 1200))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "PERMISSION_DENIED")
+	assert.Contains(t, err.Error(), "API key not valid")
+}
+
+func TestGoogleTranscriberRequiresCredentials(t *testing.T) {
+	_, err := NewGoogleTranscriberWithConfig(GoogleConfig{})
+	assert.Error(t, err)
+
+	_, err = NewGoogleTranscriberWithConfig(GoogleConfig{CredentialsFile: "/nonexistent/key.json"})
+	assert.Error(t, err)
+}
+
+func TestGoogleTranscriberServiceAccount(t *testing.T) {
+	key, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+	der, err := x509.MarshalPKCS8PrivateKey(key)
+	require.NoError(t, err)
+
+	credentials, err := json.Marshal(map[string]string{
+		"type":           "service_account",
+		"client_email":   "bot@example.iam.gserviceaccount.com",
+		"private_key_id": "key-1",
+		"private_key":    string(pem.EncodeToMemory(&pem.Block{Type: "PRIVATE KEY", Bytes: der})),
+	})
+	require.NoError(t, err)
+	path := filepath.Join(t.TempDir(), "key.json")
+	require.NoError(t, os.WriteFile(path, credentials, 0600))
+
+	server, req, _ := fakeGoogleServer(t, http.StatusOK, `{}`)
+	gt, err := NewGoogleTranscriberWithConfig(GoogleConfig{CredentialsFile: path, Endpoint: server.URL})
+	require.NoError(t, err)
+	_, err = gt.Transcribe(make([]byte, 1200))
+	require.NoError(t, err)
+
+	// The bearer token is a JWT signed with the service account key
+	token := strings.TrimPrefix(req.Header.Get("Authorization"), "Bearer ")
+	parts := strings.Split(token, ".")
+	require.Len(t, parts, 3)
+
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	require.NoError(t, err)
+	digest := sha256.Sum256([]byte(parts[0] + "." + parts[1]))
+	require.NoError(t, rsa.VerifyPKCS1v15(&key.PublicKey, crypto.SHA256, digest[:], signature))
+
+	payload, err := base64.RawURLEncoding.DecodeString(parts[1])
+	require.NoError(t, err)
+	var claims map[string]any
+	require.NoError(t, json.Unmarshal(payload, &claims))
+	assert.Equal(t, "bot@example.iam.gserviceaccount.com", claims["iss"])
+	assert.Equal(t, googleTokenAudience, claims["aud"])
+}
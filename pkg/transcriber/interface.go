@@ -1,6 +1,8 @@
 package transcriber
 
 import (
+	"context"
+	"strings"
 	"time"
 )
 
@@ -19,12 +21,27 @@ type Transcriber interface {
 	Close() error
 }
 
+// SpeechTranslator is implemented by transcribers that can turn speech directly
+// into English text (whisper's translate mode)
+type SpeechTranslator interface {
+	// TranslateSpeech transcribes audio into English regardless of the spoken
+	// language. Cancelling ctx abandons the translation.
+	TranslateSpeech(ctx context.Context, audio []byte, opts TranscriptionOptions) (*TranscriptResult, error)
+}
+
+// Ensure the whisper transcribers implement translate mode
+var _ SpeechTranslator = (*WhisperTranscriber)(nil)
+var _ SpeechTranslator = (*GPUWhisperTranscriber)(nil)
+var _ SpeechTranslator = (*WhisperServerTranscriber)(nil)
+
 // TranscriptionOptions provides enhanced options for transcription
 type TranscriptionOptions struct {
 	// Previous transcript for context (improves accuracy)
 	PreviousContext string
 
-	// Language hint (e.g., "en", "es", "auto")
+	// Language hint (e.g., "en", "es", "de-DE", "auto"). Empty uses the transcriber's
+	// configured default; "auto" asks for detection where the backend supports it.
+	// Whisper backends only use the primary subtag of a BCP-47 tag.
 	Language string
 
 	// Maximum number of alternative transcriptions
@@ -43,6 +60,12 @@ type TranscriptionOptions struct {
 	OverlapAudio []byte
 }
 
+// whisperLanguage reduces a BCP-47 tag to the ISO 639 code whisper accepts (de-DE -> de)
+func whisperLanguage(tag string) string {
+	primary, _, _ := strings.Cut(strings.ToLower(tag), "-")
+	return primary
+}
+
 // TranscriptResult contains the transcription result with metadata
 type TranscriptResult struct {
 	// Primary transcription text
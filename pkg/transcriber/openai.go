@@ -0,0 +1,288 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"math"
+	"mime/multipart"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultOpenAIBaseURL is the OpenAI API; any server implementing
+	// /audio/transcriptions (faster-whisper-server, LocalAI, ...) works too
+	DefaultOpenAIBaseURL = "https://api.openai.com/v1"
+
+	defaultOpenAIModel   = "whisper-1"
+	openAIRequestTimeout = 60 * time.Second
+)
+
+// OpenAIConfig configures the OpenAI-compatible Whisper transcriber
+type OpenAIConfig struct {
+	BaseURL    string       // API base URL including the version (default: DefaultOpenAIBaseURL)
+	APIKey     string       // Bearer token; optional for self-hosted servers
+	Model      string       // Model name (default: whisper-1)
+	Language   string       // ISO-639-1 language used when the request doesn't set one (optional)
+	HTTPClient *http.Client // Optional custom client
+}
+
+// OpenAITranscriber sends audio to an OpenAI-compatible /audio/transcriptions endpoint
+type OpenAITranscriber struct {
+	config OpenAIConfig
+	client *http.Client
+}
+
+// NewOpenAITranscriber creates an OpenAI-compatible transcriber configured from the
+// environment: OPENAI_BASE_URL, OPENAI_API_KEY, OPENAI_TRANSCRIBE_MODEL and OPENAI_LANGUAGE
+func NewOpenAITranscriber() (*OpenAITranscriber, error) {
+	return NewOpenAITranscriberWithConfig(OpenAIConfig{
+		BaseURL:  os.Getenv("OPENAI_BASE_URL"),
+		APIKey:   os.Getenv("OPENAI_API_KEY"),
+		Model:    os.Getenv("OPENAI_TRANSCRIBE_MODEL"),
+		Language: os.Getenv("OPENAI_LANGUAGE"),
+	})
+}
+
+// NewOpenAITranscriberWithConfig creates an OpenAI-compatible transcriber from explicit settings
+func NewOpenAITranscriberWithConfig(config OpenAIConfig) (*OpenAITranscriber, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultOpenAIBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.Model == "" {
+		config.Model = defaultOpenAIModel
+	}
+
+	// The hosted API rejects anonymous requests; self-hosted servers usually don't need a key
+	if config.APIKey == "" && config.BaseURL == DefaultOpenAIBaseURL {
+		return nil, fmt.Errorf("OPENAI_API_KEY is required when using the OpenAI API")
+	}
+
+	ot := &OpenAITranscriber{config: config, client: config.HTTPClient}
+	if ot.client == nil {
+		ot.client = &http.Client{Timeout: openAIRequestTimeout}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"base_url": config.BaseURL,
+		"model":    config.Model,
+		"language": config.Language,
+	}).Info("OpenAI-compatible transcriber initialized")
+
+	return ot, nil
+}
+
+// Transcribe implements the basic Transcriber interface
+func (ot *OpenAITranscriber) Transcribe(audio []byte) (string, error) {
+	result, err := ot.TranscribeWithContext(audio, TranscriptionOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// TranscribeWithContext uploads 48kHz stereo PCM as a 16kHz mono WAV file
+func (ot *OpenAITranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	startTime := time.Now()
+
+	language := ot.config.Language
+	if opts.Language != "" && opts.Language != "auto" {
+		language = whisperLanguage(opts.Language)
+	}
+
+	fields := map[string]string{
+		"model":           ot.config.Model,
+		"response_format": "verbose_json",
+	}
+	if language != "" {
+		fields["language"] = language
+	}
+	if prompt := CreatePrompt(opts.CustomVocabulary, opts.PreviousContext); prompt != "" {
+		fields["prompt"] = prompt
+	}
+	if opts.Temperature > 0 {
+		fields["temperature"] = strconv.FormatFloat(float64(opts.Temperature), 'f', -1, 32)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audio_bytes": len(audio),
+		"language":    language,
+		"has_context": opts.PreviousContext != "",
+		"vocabulary":  len(opts.CustomVocabulary),
+	}).Debug("OpenAITranscriber: Starting transcription")
+
+	wav := pcm.SpeechWAV(audio)
+	response, err := ot.transcribe(wav, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	result := buildOpenAIResult(response)
+	if result.Language == "" {
+		result.Language = language
+	}
+	result.Duration = time.Since(startTime)
+
+	if result.Text == "" {
+		logrus.Debug("OpenAITranscriber: No speech detected")
+		result.Text = "[No speech detected]"
+	}
+	return result, nil
+}
+
+// IsReady reports whether the transcriber is configured
+func (ot *OpenAITranscriber) IsReady() bool {
+	return ot.config.BaseURL != ""
+}
+
+// Close releases idle HTTP connections
+func (ot *OpenAITranscriber) Close() error {
+	ot.client.CloseIdleConnections()
+	return nil
+}
+
+// transcribe performs one multipart /audio/transcriptions call
+func (ot *OpenAITranscriber) transcribe(wav []byte, fields map[string]string) (*openAITranscription, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("error writing form field %s: %w", name, err)
+		}
+	}
+	// Word timings need to be requested explicitly; servers without them return segments only
+	for _, granularity := range []string{"word", "segment"} {
+		if err := form.WriteField("timestamp_granularities[]", granularity); err != nil {
+			return nil, fmt.Errorf("error writing form field: %w", err)
+		}
+	}
+	file, err := form.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return nil, fmt.Errorf("error creating form file: %w", err)
+	}
+	if _, err := file.Write(wav); err != nil {
+		return nil, fmt.Errorf("error writing audio: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("error closing form: %w", err)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), openAIRequestTimeout)
+	defer cancel()
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ot.config.BaseURL+"/audio/transcriptions", &body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating transcription request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+	if ot.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+ot.config.APIKey)
+	}
+
+	resp, err := ot.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("transcription request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading transcription response: %w", err)
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		var apiErr openAIErrorResponse
+		if json.Unmarshal(data, &apiErr) == nil && apiErr.Error.Message != "" {
+			return nil, fmt.Errorf("transcription failed (%d): %s", resp.StatusCode, apiErr.Error.Message)
+		}
+		return nil, fmt.Errorf("transcription failed with status %d", resp.StatusCode)
+	}
+
+	var response openAITranscription
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error decoding transcription response: %w", err)
+	}
+	return &response, nil
+}
+
+// buildOpenAIResult converts a verbose_json response. Word timings come from the
+// top-level words list, or from per-segment words as returned by faster-whisper servers.
+func buildOpenAIResult(response *openAITranscription) *TranscriptResult {
+	result := &TranscriptResult{
+		Text:     strings.TrimSpace(response.Text),
+		Language: response.Language,
+	}
+
+	words := response.Words
+	var confidence float64
+	for _, segment := range response.Segments {
+		// avg_logprob is the mean token log-probability of the segment
+		confidence += math.Exp(segment.AvgLogprob)
+		if len(response.Words) == 0 {
+			words = append(words, segment.Words...)
+		}
+	}
+
+	for _, w := range words {
+		result.Words = append(result.Words, WordTiming{
+			Word:       strings.TrimSpace(w.Word),
+			StartTime:  secondsToDuration(w.Start),
+			EndTime:    secondsToDuration(w.End),
+			Confidence: float32(w.Probability),
+		})
+	}
+
+	switch {
+	case len(response.Segments) > 0:
+		result.Confidence = float32(confidence / float64(len(response.Segments)))
+	case result.Text != "":
+		result.Confidence = 0.95 // Same default as the local Whisper transcriber
+	}
+
+	return result
+}
+
+// secondsToDuration converts fractional seconds to a duration
+func secondsToDuration(seconds float64) time.Duration {
+	return time.Duration(seconds * float64(time.Second))
+}
+
+// verbose_json response of /audio/transcriptions
+type openAITranscription struct {
+	Text     string          `json:"text"`
+	Language string          `json:"language"`
+	Duration float64         `json:"duration"`
+	Words    []openAIWord    `json:"words"`
+	Segments []openAISegment `json:"segments"`
+}
+
+type openAISegment struct {
+	Start      float64      `json:"start"`
+	End        float64      `json:"end"`
+	Text       string       `json:"text"`
+	AvgLogprob float64      `json:"avg_logprob"`
+	Words      []openAIWord `json:"words"`
+}
+
+type openAIWord struct {
+	Word        string  `json:"word"`
+	Start       float64 `json:"start"`
+	End         float64 `json:"end"`
+	Probability float64 `json:"probability"` // Only reported by some servers
+}
+
+type openAIErrorResponse struct {
+	Error struct {
+		Message string `json:"message"`
+		Type    string `json:"type"`
+	} `json:"error"`
+}
@@ -0,0 +1,150 @@
+package transcriber
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// openAIRequest is what the fake server saw in the last transcription request
+type openAIRequest struct {
+	path          string
+	authorization string
+	fields        map[string][]string
+	file          []byte
+}
+
+func fakeOpenAIServer(t *testing.T, status int, response string) (*httptest.Server, *openAIRequest) {
+	t.Helper()
+	last := &openAIRequest{}
+
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		last.path = r.URL.Path
+		last.authorization = r.Header.Get("Authorization")
+		if err := r.ParseMultipartForm(1 << 20); err == nil {
+			last.fields = r.MultipartForm.Value
+			if file, _, err := r.FormFile("file"); err == nil {
+				last.file, _ = io.ReadAll(file)
+				_ = file.Close()
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.WriteHeader(status)
+		_, _ = w.Write([]byte(response))
+	}))
+	t.Cleanup(server.Close)
+	return server, last
+}
+
+func TestOpenAITranscriberRequestAndResult(t *testing.T) {
+	response := `{
+		"text": " Hello world.",
+		"language": "english",
+		"duration": 0.9,
+		"segments": [{"start": 0, "end": 0.9, "text": " Hello world.", "avg_logprob": -0.2}],
+		"words": [
+			{"word": "Hello", "start": 0.0, "end": 0.4},
+			{"word": "world.", "start": 0.4, "end": 0.9}
+		]
+	}`
+	server, req := fakeOpenAIServer(t, http.StatusOK, response)
+
+	ot, err := NewOpenAITranscriberWithConfig(OpenAIConfig{BaseURL: server.URL + "/v1/", APIKey: "sk-test"})
+	require.NoError(t, err)
+	assert.True(t, ot.IsReady())
+
+	// 0.1s of 48kHz stereo silence
+	result, err := ot.TranscribeWithContext(make([]byte, 4800*4), TranscriptionOptions{
+		PreviousContext: "we were talking about the release",
+		Language:        "en",
+		Temperature:     0.2,
+	})
+	require.NoError(t, err)
+
+	// Request
+	assert.Equal(t, "/v1/audio/transcriptions", req.path)
+	assert.Equal(t, "Bearer sk-test", req.authorization)
+	assert.Equal(t, []string{"whisper-1"}, req.fields["model"])
+	assert.Equal(t, []string{"verbose_json"}, req.fields["response_format"])
+	assert.Equal(t, []string{"en"}, req.fields["language"])
+	assert.Equal(t, []string{"0.2"}, req.fields["temperature"])
+	assert.Equal(t, []string{"word", "segment"}, req.fields["timestamp_granularities[]"])
+	require.Len(t, req.fields["prompt"], 1)
+	assert.Contains(t, req.fields["prompt"][0], "we were talking about the release")
+	require.Len(t, req.file, 44+1600*2, "16kHz mono WAV")
+	assert.Equal(t, "RIFF", string(req.file[:4]))
+
+	// Result
+	assert.Equal(t, "Hello world.", result.Text)
+	assert.Equal(t, "english", result.Language)
+	assert.InDelta(t, 0.8187, result.Confidence, 0.001)
+	require.Len(t, result.Words, 2)
+	assert.Equal(t, "world.", result.Words[1].Word)
+	assert.Equal(t, 400*time.Millisecond, result.Words[1].StartTime)
+	assert.Equal(t, 900*time.Millisecond, result.Words[1].EndTime)
+}
+
+func TestOpenAITranscriberSegmentWords(t *testing.T) {
+	// faster-whisper servers nest words inside segments and report probabilities
+	response := `{
+		"text": "one two",
+		"segments": [
+			{"start": 0, "end": 0.5, "text": "one", "avg_logprob": 0, "words": [{"word": " one", "start": 0, "end": 0.5, "probability": 0.9}]},
+			{"start": 0.5, "end": 1, "text": "two", "avg_logprob": 0, "words": [{"word": " two", "start": 0.5, "end": 1, "probability": 0.7}]}
+		]
+	}`
+	server, req := fakeOpenAIServer(t, http.StatusOK, response)
+
+	ot, err := NewOpenAITranscriberWithConfig(OpenAIConfig{BaseURL: server.URL, Model: "large-v3", Language: "de"})
+	require.NoError(t, err)
+
+	result, err := ot.TranscribeWithContext(make([]byte, 1200), TranscriptionOptions{Language: "auto"})
+	require.NoError(t, err)
+
+	assert.Empty(t, req.authorization, "self-hosted servers don't need a key")
+	assert.Equal(t, []string{"large-v3"}, req.fields["model"])
+	assert.Equal(t, []string{"de"}, req.fields["language"], "auto falls back to the configured language")
+	assert.Empty(t, req.fields["prompt"])
+	assert.Empty(t, req.fields["temperature"])
+
+	assert.Equal(t, "de", result.Language)
+	assert.InDelta(t, 1.0, result.Confidence, 0.001)
+	require.Len(t, result.Words, 2)
+	assert.Equal(t, "one", result.Words[0].Word)
+	assert.InDelta(t, 0.7, result.Words[1].Confidence, 0.001)
+}
+
+func TestOpenAITranscriberNoSpeech(t *testing.T) {
+	server, _ := fakeOpenAIServer(t, http.StatusOK, `{"text": ""}`)
+	ot, err := NewOpenAITranscriberWithConfig(OpenAIConfig{BaseURL: server.URL})
+	require.NoError(t, err)
+
+	text, err := ot.Transcribe(make([]byte, 1200))
+	require.NoError(t, err)
+	assert.Equal(t, "[No speech detected]", text)
+}
+
+func TestOpenAITranscriberAPIError(t *testing.T) {
+	server, _ := fakeOpenAIServer(t, http.StatusUnauthorized,
+		`{"error": {"message": "Incorrect API key provided", "type": "invalid_request_error"}}`)
+	ot, err := NewOpenAITranscriberWithConfig(OpenAIConfig{BaseURL: server.URL, APIKey: "bad"})
+	require.NoError(t, err)
+
+	_, err = ot.Transcribe(make([]byte, 1200))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "401")
+	assert.Contains(t, err.Error(), "Incorrect API key provided")
+}
+
+func TestOpenAITranscriberRequiresKeyForHostedAPI(t *testing.T) {
+	_, err := NewOpenAITranscriberWithConfig(OpenAIConfig{})
+	assert.Error(t, err)
+
+	_, err = NewOpenAITranscriberWithConfig(OpenAIConfig{APIKey: "sk-test"})
+	assert.NoError(t, err)
+}
@@ -1,7 +1,7 @@
 package transcriber
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
 	"github.com/sirupsen/logrus"
 )
 
@@ -20,8 +21,7 @@ import (
 type WhisperTranscriber struct {
 	modelPath   string
 	whisperPath string
-	ffmpegPath  string
-	language    string // Language code for transcription (e.g., "en", "de", "auto")
+	language    string // Default language code (e.g., "en", "de", "auto"), overridable per call
 	threads     string // Number of threads for whisper processing
 	beamSize    string // Beam size for whisper (1 = faster, 5 = more accurate)
 }
@@ -48,18 +48,6 @@ func NewWhisperTranscriber(modelPath string) (*WhisperTranscriber, error) {
 		return nil, fmt.Errorf("whisper executable found but not working: %w", err)
 	}
 
-	// Check for ffmpeg executable and validate it works
-	ffmpegPath, err := exec.LookPath("ffmpeg")
-	if err != nil {
-		return nil, fmt.Errorf("ffmpeg executable not found in PATH: %w", err)
-	}
-
-	// Validate ffmpeg binary works
-	// #nosec G204 - ffmpegPath comes from exec.LookPath which is safe
-	if err := exec.Command(ffmpegPath, "-version").Run(); err != nil {
-		return nil, fmt.Errorf("ffmpeg executable found but not working: %w", err)
-	}
-
 	// Get language setting from environment variable (default: auto)
 	language := os.Getenv("WHISPER_LANGUAGE")
 	if language == "" {
@@ -80,7 +68,6 @@ func NewWhisperTranscriber(modelPath string) (*WhisperTranscriber, error) {
 
 	logrus.WithFields(logrus.Fields{
 		"whisper":   whisperPath,
-		"ffmpeg":    ffmpegPath,
 		"model":     modelPath,
 		"language":  language,
 		"threads":   threads,
@@ -90,7 +77,6 @@ func NewWhisperTranscriber(modelPath string) (*WhisperTranscriber, error) {
 	return &WhisperTranscriber{
 		modelPath:   modelPath,
 		whisperPath: whisperPath,
-		ffmpegPath:  ffmpegPath,
 		language:    language,
 		threads:     threads,
 		beamSize:    beamSize,
@@ -108,32 +94,34 @@ func (wt *WhisperTranscriber) Transcribe(audio []byte) (string, error) {
 
 // TranscribeWithContext implements the new Transcriber interface with enhanced options
 func (wt *WhisperTranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
-	startTime := time.Now()
+	return wt.transcribe(context.Background(), audio, opts, false)
+}
 
-	// Convert old TranscribeOptions if needed for backward compatibility
-	var previousTranscript string
-	var overlapAudio []byte
+// TranslateSpeech runs whisper in translate mode, producing English text
+func (wt *WhisperTranscriber) TranslateSpeech(ctx context.Context, audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	return wt.transcribe(ctx, audio, opts, true)
+}
 
-	// Use new options
-	previousTranscript = opts.PreviousContext
-	overlapAudio = opts.OverlapAudio
-	if opts.Language != "" && opts.Language != "auto" {
-		wt.language = opts.Language
+func (wt *WhisperTranscriber) transcribe(ctx context.Context, audio []byte, opts TranscriptionOptions, translate bool) (*TranscriptResult, error) {
+	startTime := time.Now()
+
+	// Language is per call; the transcriber is shared between workers
+	language := wt.language
+	if opts.Language != "" {
+		language = whisperLanguage(opts.Language)
 	}
 
 	// Call the legacy implementation
-	text, err := wt.transcribeInternal(audio, previousTranscript, overlapAudio)
+	result, err := wt.transcribeInternal(ctx, audio, CreatePrompt(opts.CustomVocabulary, opts.PreviousContext), opts.OverlapAudio, language, opts.EnableTimestamps, translate)
 	if err != nil {
 		return nil, err
 	}
 
-	// Build result
-	return &TranscriptResult{
-		Text:       text,
-		Confidence: 0.95, // Whisper doesn't provide confidence scores
-		Language:   wt.language,
-		Duration:   time.Since(startTime),
-	}, nil
+	if result.Language == "" {
+		result.Language = language
+	}
+	result.Duration = time.Since(startTime)
+	return result, nil
 }
 
 // IsReady implements the new Transcriber interface
@@ -146,7 +134,7 @@ func (wt *WhisperTranscriber) IsReady() bool {
 }
 
 // transcribeInternal is the internal implementation (legacy)
-func (wt *WhisperTranscriber) transcribeInternal(audio []byte, previousTranscript string, overlapAudio []byte) (string, error) {
+func (wt *WhisperTranscriber) transcribeInternal(ctx context.Context, audio []byte, prompt string, overlapAudio []byte, language string, withWords, translate bool) (*TranscriptResult, error) {
 	// Use only the current audio chunk without overlap
 	// The overlap context is now provided via the --prompt parameter
 	finalAudio := audio
@@ -160,139 +148,81 @@ func (wt *WhisperTranscriber) transcribeInternal(audio []byte, previousTranscrip
 	logrus.WithFields(logrus.Fields{
 		"audio_bytes": len(finalAudio),
 		"model":       wt.modelPath,
-		"has_context": previousTranscript != "",
+		"language":    language,
+		"has_prompt":  prompt != "",
 	}).Debug("WhisperTranscriber: Starting transcription")
 
-	// Convert PCM to WAV format using ffmpeg
-	// Input: 48kHz, 2 channel, 16-bit signed PCM
-	// #nosec G204 - ffmpegPath is validated during initialization, arguments are hardcoded
-	cmd := exec.Command(wt.ffmpegPath,
-		"-f", "s16le", // Input format: signed 16-bit little-endian
-		"-ar", "48000", // Sample rate: 48kHz
-		"-ac", "2", // Channels: 2 (stereo)
-		"-i", "-", // Input from stdin
-		"-ar", "16000", // Resample to 16kHz for Whisper
-		"-ac", "1", // Convert to mono for Whisper
-		"-f", "wav", // Output format: WAV
-		"-", // Output to stdout
-	)
-	cmd.Stdin = bytes.NewReader(finalAudio)
-
-	var wavBuf bytes.Buffer
-	var ffmpegErr bytes.Buffer
-	cmd.Stdout = &wavBuf
-	cmd.Stderr = &ffmpegErr
-
-	if err := cmd.Run(); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"error":  err,
-			"stderr": ffmpegErr.String(),
-		}).Error("Failed to convert audio to WAV")
-		return "", fmt.Errorf("audio conversion failed: %w", err)
-	}
-
-	logrus.WithField("wav_bytes", wavBuf.Len()).Debug("WhisperTranscriber: Audio converted to WAV")
+	// Whisper expects 16kHz mono WAV
+	wav := pcm.SpeechWAV(finalAudio)
+	logrus.WithField("wav_bytes", len(wav)).Debug("WhisperTranscriber: Audio converted to WAV")
 
 	// Call whisper for transcription
 	// Using more specific parameters for better transcription
 	// #nosec G204 - modelPath is controlled by server configuration, not user input
 	whisperArgs := []string{
 		"-m", wt.modelPath, // Model path
-		"-l", wt.language, // Language: configurable, defaults to auto-detect
+		"-l", language, // Language: configurable, defaults to auto-detect
 		"-t", wt.threads, // Threads: configurable for performance tuning
 		"-bs", wt.beamSize, // Beam size: smaller = faster, larger = more accurate
-		"--no-timestamps", // Don't include timestamps in output
-		"-otxt",           // Output format: plain text
 	}
 
-	// Add context from previous transcript as initial prompt
+	// Add vocabulary and context from previous transcript as initial prompt
 	// This helps maintain continuity across chunk boundaries
 	// IMPORTANT: Use --prompt (not -p) for text prompts
 	// The -p flag expects an integer for parallel processing
-	if prompt := CreateContextPrompt(previousTranscript); prompt != "" {
+	if prompt != "" {
 		// Log the exact prompt for debugging
 		logrus.WithFields(logrus.Fields{
 			"prompt":       prompt,
 			"prompt_len":   len(prompt),
 			"prompt_words": len(strings.Fields(prompt)),
-		}).Debug("Using previous transcript as prompt")
+		}).Debug("Using vocabulary and previous transcript as prompt")
 
 		// Use --prompt (not -p) for text prompts
 		// The -p flag is for number of processors, not prompt text!
 		whisperArgs = append(whisperArgs, "--prompt", prompt)
 	}
 
-	whisperArgs = append(whisperArgs, "-") // Read from stdin
-	// #nosec G204 - whisperPath is validated during initialization, arguments are controlled
-	whisperCmd := exec.Command(wt.whisperPath, whisperArgs...)
-	whisperCmd.Stdin = &wavBuf
-
-	var outBuf, errBuf bytes.Buffer
-	whisperCmd.Stdout = &outBuf
-	whisperCmd.Stderr = &errBuf
+	if translate {
+		whisperArgs = append(whisperArgs, "-tr")
+	}
 
 	logrus.Debug("WhisperTranscriber: Starting whisper process")
 
-	if err := whisperCmd.Run(); err != nil {
+	// Full JSON output carries token probabilities and timings
+	output, stderr, err := runWhisperCLI(ctx, wt.whisperPath, whisperArgs, wav, nil)
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error":  err,
-			"stderr": errBuf.String(),
+			"stderr": stderr,
 		}).Error("Whisper transcription failed")
-		return "", fmt.Errorf("whisper transcription failed: %w", err)
+		return nil, err
 	}
 
-	// Clean up the output (remove extra whitespace)
-	transcript := string(bytes.TrimSpace(outBuf.Bytes()))
-	if transcript == "" {
+	result, err := parseWhisperJSON(output, withWords)
+	if err != nil {
+		return nil, err
+	}
+	if result.Text == "" {
 		logrus.Debug("WhisperTranscriber: No speech detected")
-		return "[No speech detected]", nil
+		result.Text = "[No speech detected]"
+		return result, nil
 	}
 
 	logrus.WithFields(logrus.Fields{
-		"transcript_length": len(transcript),
-		"first_50_chars":    transcript[:min(50, len(transcript))],
+		"transcript_length": len(result.Text),
+		"first_50_chars":    result.Text[:min(50, len(result.Text))],
+		"confidence":        result.Confidence,
+		"words":             len(result.Words),
 	}).Debug("WhisperTranscriber: Transcription complete")
 
-	return transcript, nil
+	return result, nil
 }
 
 func (wt *WhisperTranscriber) Close() error {
 	return nil
 }
 
-// GoogleTranscriber uses Google Cloud Speech API
-type GoogleTranscriber struct {
-	// In production, add Google Cloud client
-}
-
-func NewGoogleTranscriber() (*GoogleTranscriber, error) {
-	return &GoogleTranscriber{}, nil
-}
-
-func (gt *GoogleTranscriber) Transcribe(audio []byte) (string, error) {
-	// Simplified for PoC
-	return "Google transcription not implemented in PoC", nil
-}
-
-func (gt *GoogleTranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
-	// TODO: Implement Google Speech-to-Text with speech context
-	return &TranscriptResult{
-		Text:       "Google transcription not implemented in PoC",
-		Confidence: 0.0,
-		Language:   "en",
-		Duration:   time.Millisecond,
-	}, nil
-}
-
-func (gt *GoogleTranscriber) IsReady() bool {
-	// TODO: Check Google client status
-	return false
-}
-
-func (gt *GoogleTranscriber) Close() error {
-	return nil
-}
-
 // MockTranscriber for testing without actual transcription
 type MockTranscriber struct{}
 
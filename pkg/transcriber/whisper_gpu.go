@@ -1,7 +1,7 @@
 package transcriber
 
 import (
-	"bytes"
+	"context"
 	"fmt"
 	"os"
 	"os/exec"
@@ -10,6 +10,7 @@ import (
 	"strings"
 	"time"
 
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
 	"github.com/sirupsen/logrus"
 )
 
@@ -17,7 +18,6 @@ import (
 type GPUWhisperTranscriber struct {
 	modelPath   string
 	whisperPath string
-	ffmpegPath  string
 	language    string
 	threads     string
 	beamSize    string
@@ -41,12 +41,6 @@ func NewGPUWhisperTranscriber(modelPath string) (*GPUWhisperTranscriber, error)
 		return nil, fmt.Errorf("whisper executable not found in PATH: %w", err)
 	}
 
-	// Check for ffmpeg executable
-	ffmpegPath, err := exec.LookPath("ffmpeg")
-	if err != nil {
-		return nil, fmt.Errorf("ffmpeg executable not found in PATH: %w", err)
-	}
-
 	// Check GPU configuration
 	// Let whisper.cpp auto-detect the best available backend (CUDA, ROCm, Vulkan, etc.)
 	useGPU := false
@@ -113,7 +107,6 @@ func NewGPUWhisperTranscriber(modelPath string) (*GPUWhisperTranscriber, error)
 
 	logrus.WithFields(logrus.Fields{
 		"whisper":    whisperPath,
-		"ffmpeg":     ffmpegPath,
 		"model":      modelPath,
 		"language":   language,
 		"threads":    threads,
@@ -125,7 +118,6 @@ func NewGPUWhisperTranscriber(modelPath string) (*GPUWhisperTranscriber, error)
 	return &GPUWhisperTranscriber{
 		modelPath:   modelPath,
 		whisperPath: whisperPath,
-		ffmpegPath:  ffmpegPath,
 		language:    language,
 		threads:     threads,
 		beamSize:    beamSize,
@@ -145,6 +137,15 @@ func (wt *GPUWhisperTranscriber) Transcribe(audio []byte) (string, error) {
 
 // TranscribeWithContext uses whisper.cpp CLI with context for better accuracy
 func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	return wt.transcribe(context.Background(), audio, opts, false)
+}
+
+// TranslateSpeech runs whisper in translate mode, producing English text
+func (wt *GPUWhisperTranscriber) TranslateSpeech(ctx context.Context, audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	return wt.transcribe(ctx, audio, opts, true)
+}
+
+func (wt *GPUWhisperTranscriber) transcribe(ctx context.Context, audio []byte, opts TranscriptionOptions, translate bool) (*TranscriptResult, error) {
 	startTime := time.Now()
 
 	// Use only the current audio chunk without overlap
@@ -157,44 +158,26 @@ func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts Transc
 		logrus.Debug("Overlap audio available but not prepended (using prompt for context instead)")
 	}
 
+	language := wt.language
+	if opts.Language != "" {
+		language = whisperLanguage(opts.Language)
+	}
+
 	logrus.WithFields(logrus.Fields{
 		"audio_bytes":       len(finalAudio),
 		"audio_duration_ms": len(finalAudio) * 1000 / 192000,
 		"model":             wt.modelPath,
 		"gpu":               wt.useGPU,
 		"gpu_layers":        wt.gpuLayers,
+		"language":          language,
 		"has_context":       opts.PreviousContext != "",
 	}).Debug("GPUWhisperTranscriber: Starting transcription")
 
-	// Convert PCM to WAV format using ffmpeg
-	// #nosec G204 - ffmpegPath is validated at initialization
-	cmd := exec.Command(wt.ffmpegPath,
-		"-f", "s16le",
-		"-ar", "48000",
-		"-ac", "2",
-		"-i", "-",
-		"-ar", "16000",
-		"-ac", "1",
-		"-f", "wav",
-		"-",
-	)
-	cmd.Stdin = bytes.NewReader(finalAudio)
-
-	var wavBuf bytes.Buffer
-	var ffmpegErr bytes.Buffer
-	cmd.Stdout = &wavBuf
-	cmd.Stderr = &ffmpegErr
-
-	if err := cmd.Run(); err != nil {
-		logrus.WithFields(logrus.Fields{
-			"error":  err,
-			"stderr": ffmpegErr.String(),
-		}).Error("Failed to convert audio to WAV")
-		return nil, fmt.Errorf("audio conversion failed: %w", err)
-	}
+	// Whisper expects 16kHz mono WAV
+	wav := pcm.SpeechWAV(finalAudio)
 
 	logrus.WithFields(logrus.Fields{
-		"wav_size":     wavBuf.Len(),
+		"wav_size":     len(wav),
 		"pcm_size":     len(audio),
 		"duration_sec": float64(len(audio)) / 192000.0,
 	}).Debug("GPUWhisperTranscriber: Converted PCM to WAV")
@@ -202,24 +185,22 @@ func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts Transc
 	// Build whisper command with GPU support if available
 	whisperArgs := []string{
 		"-m", wt.modelPath,
-		"-l", wt.language,
+		"-l", language,
 		"-t", wt.threads,
 		"-bs", wt.beamSize,
-		"--no-timestamps",
-		"-otxt",
 	}
 
-	// Add context from previous transcript as initial prompt
+	// Add vocabulary and context from previous transcript as initial prompt
 	// This helps maintain continuity across chunk boundaries
 	// IMPORTANT: Use --prompt (not -p) for text prompts
 	// The -p flag expects an integer for parallel processing
-	if prompt := CreateContextPrompt(opts.PreviousContext); prompt != "" {
+	if prompt := CreatePrompt(opts.CustomVocabulary, opts.PreviousContext); prompt != "" {
 		// Log the exact prompt for debugging
 		logrus.WithFields(logrus.Fields{
 			"prompt":       prompt,
 			"prompt_len":   len(prompt),
 			"prompt_words": len(strings.Fields(prompt)),
-		}).Debug("Using previous transcript as prompt")
+		}).Debug("Using vocabulary and previous transcript as prompt")
 
 		// Use --prompt (not -p) for text prompts
 		// The -p flag is for number of processors, not prompt text!
@@ -227,13 +208,17 @@ func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts Transc
 	}
 
 	// Add additional accuracy parameters for non-English languages
-	if wt.language != "auto" && wt.language != "en" {
+	if language != "auto" && language != "en" {
 		// Higher temperature for better accuracy with non-English
 		whisperArgs = append(whisperArgs, "-tp", "0.8")
 		// Use best_of for better quality
 		whisperArgs = append(whisperArgs, "-bo", "5")
 	}
 
+	if translate {
+		whisperArgs = append(whisperArgs, "-tr")
+	}
+
 	// Add GPU-specific flags if available
 	if wt.useGPU {
 		// The prebuilt whisper binary uses GPU by default when available
@@ -247,50 +232,41 @@ func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts Transc
 		whisperArgs = append(whisperArgs, "--no-gpu")
 	}
 
-	// Add input from stdin
-	whisperArgs = append(whisperArgs, "-")
-
-	// #nosec G204 - whisperPath is validated at initialization
-	whisperCmd := exec.Command(wt.whisperPath, whisperArgs...)
-	whisperCmd.Stdin = &wavBuf
-
-	var outBuf, errBuf bytes.Buffer
-	whisperCmd.Stdout = &outBuf
-	whisperCmd.Stderr = &errBuf
-
-	// Let whisper.cpp handle GPU environment configuration
-	whisperCmd.Env = os.Environ()
-
 	logrus.WithField("gpu", wt.useGPU).Debug("GPUWhisperTranscriber: Starting whisper process")
 
-	if err := whisperCmd.Run(); err != nil {
+	// Let whisper.cpp handle GPU environment configuration.
+	// Full JSON output carries token probabilities and timings.
+	output, stderr, err := runWhisperCLI(ctx, wt.whisperPath, whisperArgs, wav, os.Environ())
+	if err != nil {
 		logrus.WithFields(logrus.Fields{
 			"error":  err,
-			"stderr": errBuf.String(),
+			"stderr": stderr,
 		}).Error("Whisper transcription failed")
-		return nil, fmt.Errorf("whisper transcription failed: %w", err)
+		return nil, err
 	}
 
 	// Log stderr output for debugging (includes model loading and performance info)
-	if errBuf.Len() > 0 {
-		logrus.WithField("stderr", errBuf.String()).Debug("Whisper stderr output")
+	if stderr != "" {
+		logrus.WithField("stderr", stderr).Debug("Whisper stderr output")
 	}
 
-	// Clean up the output
-	transcript := string(bytes.TrimSpace(outBuf.Bytes()))
+	result, err := parseWhisperJSON(output, opts.EnableTimestamps)
+	if err != nil {
+		return nil, err
+	}
 	duration := time.Since(startTime)
+	result.Duration = duration
+	if result.Language == "" {
+		result.Language = language
+	}
 
-	if transcript == "" {
+	if result.Text == "" {
 		logrus.WithFields(logrus.Fields{
 			"audio_duration_ms": len(audio) * 1000 / 192000,
-			"stderr_len":        errBuf.Len(),
+			"stderr_len":        len(stderr),
 		}).Debug("GPUWhisperTranscriber: No speech detected")
-		return &TranscriptResult{
-			Text:       "[No speech detected]",
-			Confidence: 0.0,
-			Language:   wt.language,
-			Duration:   duration,
-		}, nil
+		result.Text = "[No speech detected]"
+		return result, nil
 	}
 
 	// Log performance metrics
@@ -299,19 +275,16 @@ func (wt *GPUWhisperTranscriber) TranscribeWithContext(audio []byte, opts Transc
 	rtf := float64(duration) / float64(audioDuration)
 
 	logrus.WithFields(logrus.Fields{
-		"transcript_length": len(transcript),
+		"transcript_length": len(result.Text),
+		"confidence":        result.Confidence,
+		"words":             len(result.Words),
 		"processing_time":   duration,
 		"audio_duration":    audioDuration,
 		"rtf":               fmt.Sprintf("%.2fx", rtf),
 		"gpu":               wt.useGPU,
 	}).Info("GPUWhisperTranscriber: Transcription complete")
 
-	return &TranscriptResult{
-		Text:       transcript,
-		Confidence: 1.0, // Whisper doesn't provide confidence scores
-		Language:   wt.language,
-		Duration:   duration,
-	}, nil
+	return result, nil
 }
 
 // IsReady returns true if the transcriber is ready to process audio
@@ -323,9 +296,6 @@ func (wt *GPUWhisperTranscriber) IsReady() bool {
 	if _, err := exec.LookPath("whisper"); err != nil {
 		return false
 	}
-	if _, err := exec.LookPath("ffmpeg"); err != nil {
-		return false
-	}
 	return true
 }
 
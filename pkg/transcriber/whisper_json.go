@@ -0,0 +1,134 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+// whisperJSON is the full JSON output of whisper-cli (-ojf)
+type whisperJSON struct {
+	Result struct {
+		Language string `json:"language"`
+	} `json:"result"`
+	Transcription []struct {
+		Text    string         `json:"text"`
+		Offsets whisperOffsets `json:"offsets"`
+		Tokens  []whisperToken `json:"tokens"`
+	} `json:"transcription"`
+}
+
+type whisperToken struct {
+	Text    string         `json:"text"`
+	Offsets whisperOffsets `json:"offsets"`
+	P       float32        `json:"p"` // Token probability
+}
+
+// whisperOffsets are milliseconds from the start of the audio
+type whisperOffsets struct {
+	From int64 `json:"from"`
+	To   int64 `json:"to"`
+}
+
+// runWhisperCLI feeds a WAV file to whisper-cli on stdin and returns its full JSON
+// output. The process is killed if ctx is cancelled.
+func runWhisperCLI(ctx context.Context, whisperPath string, args []string, wav []byte, env []string) ([]byte, string, error) {
+	dir, err := os.MkdirTemp("", "whisper-*")
+	if err != nil {
+		return nil, "", fmt.Errorf("error creating whisper output directory: %w", err)
+	}
+	defer func() { _ = os.RemoveAll(dir) }()
+
+	outputBase := filepath.Join(dir, "transcript")
+	args = append(append([]string(nil), args...), "-ojf", "-of", outputBase, "-")
+
+	// #nosec G204 - whisperPath is validated during initialization, arguments are controlled
+	cmd := exec.CommandContext(ctx, whisperPath, args...)
+	cmd.Stdin = bytes.NewReader(wav)
+	cmd.Env = env
+
+	var errBuf bytes.Buffer
+	cmd.Stderr = &errBuf
+	if err := cmd.Run(); err != nil {
+		return nil, errBuf.String(), fmt.Errorf("whisper transcription failed: %w", err)
+	}
+
+	// #nosec G304 - the path is inside the temporary directory created above
+	data, err := os.ReadFile(outputBase + ".json")
+	if err != nil {
+		return nil, errBuf.String(), fmt.Errorf("error reading whisper output: %w", err)
+	}
+	return data, errBuf.String(), nil
+}
+
+// parseWhisperJSON builds a result from whisper-cli's full JSON output. Confidence is
+// the mean probability of the text tokens. Words are assembled from tokens (a token
+// starting with a space begins a new word) and only included if withWords is set.
+func parseWhisperJSON(data []byte, withWords bool) (*TranscriptResult, error) {
+	var output whisperJSON
+	if err := json.Unmarshal(data, &output); err != nil {
+		return nil, fmt.Errorf("error decoding whisper output: %w", err)
+	}
+
+	result := &TranscriptResult{Language: output.Result.Language}
+
+	var texts []string
+	var probability float32
+	var tokens int
+	var current *WordTiming
+	var currentTokens int
+
+	finishWord := func() {
+		if current == nil {
+			return
+		}
+		current.Word = strings.TrimSpace(current.Word)
+		current.Confidence /= float32(currentTokens)
+		if current.Word != "" {
+			result.Words = append(result.Words, *current)
+		}
+		current = nil
+	}
+
+	for _, segment := range output.Transcription {
+		if text := strings.TrimSpace(segment.Text); text != "" {
+			texts = append(texts, text)
+		}
+
+		for _, token := range segment.Tokens {
+			// Special tokens such as [_BEG_] and [_TT_150] carry no text
+			if token.Text == "" || strings.HasPrefix(token.Text, "[_") {
+				continue
+			}
+			probability += token.P
+			tokens++
+
+			if current == nil || strings.HasPrefix(token.Text, " ") {
+				finishWord()
+				current = &WordTiming{StartTime: time.Duration(token.Offsets.From) * time.Millisecond}
+				currentTokens = 0
+			}
+			current.Word += token.Text
+			current.EndTime = time.Duration(token.Offsets.To) * time.Millisecond
+			current.Confidence += token.P
+			currentTokens++
+		}
+		// Words never span segments
+		finishWord()
+	}
+
+	result.Text = strings.Join(texts, " ")
+	if tokens > 0 {
+		result.Confidence = probability / float32(tokens)
+	}
+	if !withWords {
+		result.Words = nil
+	}
+	return result, nil
+}
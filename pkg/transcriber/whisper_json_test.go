@@ -0,0 +1,73 @@
+package transcriber
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// Trimmed -ojf output of whisper-cli for two short segments
+const whisperFullJSON = `{
+	"result": {"language": "de"},
+	"transcription": [
+		{
+			"offsets": {"from": 0, "to": 1200},
+			"text": " Hallo Welt,",
+			"tokens": [
+				{"text": "[_BEG_]", "offsets": {"from": 0, "to": 0}, "p": 0.99},
+				{"text": " Hal", "offsets": {"from": 0, "to": 300}, "p": 0.8},
+				{"text": "lo", "offsets": {"from": 300, "to": 500}, "p": 0.6},
+				{"text": " Welt", "offsets": {"from": 600, "to": 1000}, "p": 0.9},
+				{"text": ",", "offsets": {"from": 1000, "to": 1200}, "p": 0.5},
+				{"text": "[_TT_60]", "offsets": {"from": 1200, "to": 1200}, "p": 0.2}
+			]
+		},
+		{
+			"offsets": {"from": 1200, "to": 2000},
+			"text": " wie geht's?",
+			"tokens": [
+				{"text": " wie", "offsets": {"from": 1200, "to": 1500}, "p": 1.0},
+				{"text": " geht's?", "offsets": {"from": 1500, "to": 2000}, "p": 0.7}
+			]
+		}
+	]
+}`
+
+func TestParseWhisperJSON(t *testing.T) {
+	result, err := parseWhisperJSON([]byte(whisperFullJSON), true)
+	require.NoError(t, err)
+
+	assert.Equal(t, "Hallo Welt, wie geht's?", result.Text)
+	assert.Equal(t, "de", result.Language)
+	// Mean over the six text tokens; special tokens are ignored
+	assert.InDelta(t, 0.75, result.Confidence, 0.001)
+
+	require.Len(t, result.Words, 4)
+	assert.Equal(t, WordTiming{Word: "Hallo", StartTime: 0, EndTime: 500 * time.Millisecond, Confidence: 0.7}, roundConfidence(result.Words[0]))
+	assert.Equal(t, "Welt,", result.Words[1].Word, "punctuation stays with its word")
+	assert.Equal(t, 1200*time.Millisecond, result.Words[1].EndTime)
+	assert.Equal(t, "geht's?", result.Words[3].Word)
+	assert.Equal(t, 1500*time.Millisecond, result.Words[3].StartTime)
+}
+
+func TestParseWhisperJSONWithoutWords(t *testing.T) {
+	result, err := parseWhisperJSON([]byte(whisperFullJSON), false)
+	require.NoError(t, err)
+	assert.Empty(t, result.Words)
+	assert.InDelta(t, 0.75, result.Confidence, 0.001)
+
+	result, err = parseWhisperJSON([]byte(`{"transcription": []}`), true)
+	require.NoError(t, err)
+	assert.Empty(t, result.Text)
+	assert.Zero(t, result.Confidence)
+
+	_, err = parseWhisperJSON([]byte("not json"), true)
+	assert.Error(t, err)
+}
+
+func roundConfidence(w WordTiming) WordTiming {
+	w.Confidence = float32(int(w.Confidence*1000+0.5)) / 1000
+	return w
+}
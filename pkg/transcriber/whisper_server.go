@@ -0,0 +1,481 @@
+package transcriber
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net"
+	"net/http"
+	"os"
+	"os/exec"
+	"runtime"
+	"strconv"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	defaultWhisperServerStartup = 2 * time.Minute // Loading large models takes a while
+	defaultWhisperHealthPeriod  = 5 * time.Second
+	whisperStartupPollInterval  = 250 * time.Millisecond
+	whisperHealthTimeout        = 2 * time.Second
+	whisperHealthFailureLimit   = 3
+	whisperRequestTimeout       = 2 * time.Minute
+	whisperMinRestartDelay      = time.Second
+	whisperMaxRestartDelay      = 30 * time.Second
+	whisperProcessWaitDelay     = 5 * time.Second
+)
+
+// WhisperServerConfig configures the persistent whisper.cpp server transcriber
+type WhisperServerConfig struct {
+	ModelPath      string
+	Command        []string      // Server executable and leading arguments (default: whisper-server from PATH)
+	Language       string        // Default language (default: auto)
+	Threads        string        // CPU threads (default: number of CPUs)
+	BeamSize       string        // Beam size (default: 1)
+	NoGPU          bool          // Pass --no-gpu to the server
+	HealthInterval time.Duration // Time between health checks (default: 5s)
+	StartupTimeout time.Duration // How long the server may take to load the model (default: 2m)
+}
+
+// WhisperServerTranscriber keeps one whisper.cpp server process running and
+// sends each segment to it over HTTP on localhost, so the model is loaded once
+// instead of for every segment. The process is health-checked and restarted if
+// it exits or stops responding.
+type WhisperServerTranscriber struct {
+	config WhisperServerConfig
+	client *http.Client
+
+	ctx    context.Context
+	cancel context.CancelFunc
+	done   chan struct{}
+
+	ready    atomic.Bool
+	restarts atomic.Int32
+
+	mu      sync.Mutex
+	baseURL string
+	process *os.Process
+}
+
+// NewWhisperServerTranscriber creates a server-backed transcriber configured from
+// the environment: WHISPER_SERVER_PATH, WHISPER_LANGUAGE, WHISPER_THREADS,
+// WHISPER_BEAM_SIZE and WHISPER_USE_GPU
+func NewWhisperServerTranscriber(modelPath string) (*WhisperServerTranscriber, error) {
+	config := WhisperServerConfig{
+		ModelPath: modelPath,
+		Language:  os.Getenv("WHISPER_LANGUAGE"),
+		Threads:   os.Getenv("WHISPER_THREADS"),
+		BeamSize:  os.Getenv("WHISPER_BEAM_SIZE"),
+		NoGPU:     os.Getenv("WHISPER_USE_GPU") == "false",
+	}
+	if serverPath := os.Getenv("WHISPER_SERVER_PATH"); serverPath != "" {
+		config.Command = []string{serverPath}
+	}
+	return NewWhisperServerTranscriberWithConfig(config)
+}
+
+// NewWhisperServerTranscriberWithConfig starts the server process from explicit
+// settings. It returns once the process is launched; IsReady turns true once the
+// model has loaded.
+func NewWhisperServerTranscriberWithConfig(config WhisperServerConfig) (*WhisperServerTranscriber, error) {
+	if _, err := os.Stat(config.ModelPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("whisper model file not found: %s", config.ModelPath)
+		}
+		return nil, fmt.Errorf("whisper model file not accessible: %w", err)
+	}
+
+	if len(config.Command) == 0 {
+		serverPath, err := exec.LookPath("whisper-server")
+		if err != nil {
+			return nil, fmt.Errorf("whisper-server executable not found in PATH: %w", err)
+		}
+		config.Command = []string{serverPath}
+	}
+	if config.Language == "" {
+		config.Language = "auto"
+	}
+	if config.Threads == "" {
+		config.Threads = strconv.Itoa(runtime.NumCPU())
+	}
+	if config.BeamSize == "" {
+		config.BeamSize = "1"
+	}
+	if config.HealthInterval <= 0 {
+		config.HealthInterval = defaultWhisperHealthPeriod
+	}
+	if config.StartupTimeout <= 0 {
+		config.StartupTimeout = defaultWhisperServerStartup
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	wt := &WhisperServerTranscriber{
+		config: config,
+		client: &http.Client{Timeout: whisperRequestTimeout},
+		ctx:    ctx,
+		cancel: cancel,
+		done:   make(chan struct{}),
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"server":    config.Command[0],
+		"model":     config.ModelPath,
+		"language":  config.Language,
+		"threads":   config.Threads,
+		"beam_size": config.BeamSize,
+		"gpu":       !config.NoGPU,
+	}).Info("Starting whisper server transcriber")
+
+	go wt.supervise()
+	return wt, nil
+}
+
+// Transcribe implements the basic Transcriber interface
+func (wt *WhisperServerTranscriber) Transcribe(audio []byte) (string, error) {
+	result, err := wt.TranscribeWithContext(audio, TranscriptionOptions{})
+	if err != nil {
+		return "", err
+	}
+	return result.Text, nil
+}
+
+// TranscribeWithContext sends 48kHz stereo PCM to the server as a 16kHz mono WAV.
+// Waits for the server if it is still loading the model or restarting.
+func (wt *WhisperServerTranscriber) TranscribeWithContext(audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	return wt.transcribe(context.Background(), audio, opts, false)
+}
+
+// TranslateSpeech asks the server for an English translation of the speech
+func (wt *WhisperServerTranscriber) TranslateSpeech(ctx context.Context, audio []byte, opts TranscriptionOptions) (*TranscriptResult, error) {
+	return wt.transcribe(ctx, audio, opts, true)
+}
+
+func (wt *WhisperServerTranscriber) transcribe(ctx context.Context, audio []byte, opts TranscriptionOptions, translate bool) (*TranscriptResult, error) {
+	startTime := time.Now()
+
+	// Give up when either the caller or Close cancels
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+	stop := context.AfterFunc(wt.ctx, cancel)
+	defer stop()
+
+	baseURL, err := wt.waitReady(ctx, wt.config.StartupTimeout)
+	if err != nil {
+		return nil, err
+	}
+
+	language := wt.config.Language
+	if opts.Language != "" {
+		language = whisperLanguage(opts.Language)
+	}
+	fields := map[string]string{
+		"response_format": "verbose_json",
+		"language":        language,
+	}
+	if prompt := CreatePrompt(opts.CustomVocabulary, opts.PreviousContext); prompt != "" {
+		fields["prompt"] = prompt
+	}
+	if translate {
+		fields["translate"] = "true"
+	}
+	if opts.Temperature > 0 {
+		fields["temperature"] = strconv.FormatFloat(float64(opts.Temperature), 'f', -1, 32)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"audio_bytes": len(audio),
+		"language":    language,
+		"has_context": opts.PreviousContext != "",
+		"vocabulary":  len(opts.CustomVocabulary),
+	}).Debug("WhisperServerTranscriber: Starting transcription")
+
+	wav := pcm.SpeechWAV(audio)
+	response, err := wt.inference(ctx, baseURL, wav, fields)
+	if err != nil {
+		return nil, err
+	}
+
+	// verbose_json has the same shape as the OpenAI API's
+	result := buildOpenAIResult(response)
+	if !opts.EnableTimestamps {
+		result.Words = nil
+	}
+	if result.Language == "" {
+		result.Language = language
+	}
+	result.Duration = time.Since(startTime)
+
+	if result.Text == "" {
+		logrus.Debug("WhisperServerTranscriber: No speech detected")
+		result.Text = "[No speech detected]"
+	}
+	return result, nil
+}
+
+// IsReady reports whether the server process is running and passing health checks
+func (wt *WhisperServerTranscriber) IsReady() bool {
+	return wt.ready.Load()
+}
+
+// Close stops the server process and waits for it to exit
+func (wt *WhisperServerTranscriber) Close() error {
+	wt.cancel()
+	<-wt.done
+	wt.client.CloseIdleConnections()
+	return nil
+}
+
+// waitReady blocks until the server is healthy and returns its URL
+func (wt *WhisperServerTranscriber) waitReady(ctx context.Context, timeout time.Duration) (string, error) {
+	deadline := time.NewTimer(timeout)
+	defer deadline.Stop()
+	poll := time.NewTicker(100 * time.Millisecond)
+	defer poll.Stop()
+
+	for {
+		if wt.ready.Load() {
+			wt.mu.Lock()
+			defer wt.mu.Unlock()
+			return wt.baseURL, nil
+		}
+		select {
+		case <-poll.C:
+		case <-deadline.C:
+			return "", fmt.Errorf("whisper server not ready after %s", timeout)
+		case <-ctx.Done():
+			if wt.ctx.Err() != nil {
+				return "", fmt.Errorf("whisper server transcriber closed")
+			}
+			return "", fmt.Errorf("error waiting for whisper server: %w", ctx.Err())
+		}
+	}
+}
+
+// supervise runs the server process, restarting it with backoff until Close
+func (wt *WhisperServerTranscriber) supervise() {
+	defer close(wt.done)
+
+	delay := whisperMinRestartDelay
+	for {
+		started := time.Now()
+		err := wt.runProcess()
+		wt.ready.Store(false)
+		if wt.ctx.Err() != nil {
+			return
+		}
+
+		// A process that ran for a while gets restarted quickly again
+		if time.Since(started) > whisperMaxRestartDelay {
+			delay = whisperMinRestartDelay
+		}
+		wt.restarts.Add(1)
+		logrus.WithError(err).WithField("restart_in", delay).Warn("Whisper server stopped, restarting")
+
+		select {
+		case <-time.After(delay):
+		case <-wt.ctx.Done():
+			return
+		}
+		delay = min(delay*2, whisperMaxRestartDelay)
+	}
+}
+
+// runProcess starts one server process and health-checks it until it exits.
+// A process that never becomes healthy or stops responding is killed.
+func (wt *WhisperServerTranscriber) runProcess() error {
+	port, err := freeLocalPort()
+	if err != nil {
+		return err
+	}
+
+	args := append(append([]string(nil), wt.config.Command[1:]...),
+		"-m", wt.config.ModelPath,
+		"--host", "127.0.0.1",
+		"--port", strconv.Itoa(port),
+		"-t", wt.config.Threads,
+		"-bs", wt.config.BeamSize,
+		"-l", wt.config.Language,
+	)
+	if wt.config.NoGPU {
+		args = append(args, "--no-gpu")
+	}
+
+	// #nosec G204 - the server command comes from server configuration, not user input
+	cmd := exec.CommandContext(wt.ctx, wt.config.Command[0], args...)
+	output := &processLogWriter{entry: logrus.WithField("process", "whisper-server")}
+	cmd.Stdout = output
+	cmd.Stderr = output
+	cmd.WaitDelay = whisperProcessWaitDelay
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("error starting whisper server: %w", err)
+	}
+
+	exited := make(chan error, 1)
+	go func() { exited <- cmd.Wait() }()
+
+	baseURL := fmt.Sprintf("http://127.0.0.1:%d", port)
+	wt.mu.Lock()
+	wt.baseURL = baseURL
+	wt.process = cmd.Process
+	wt.mu.Unlock()
+
+	logrus.WithFields(logrus.Fields{
+		"pid":  cmd.Process.Pid,
+		"port": port,
+	}).Debug("Whisper server process started")
+
+	startupDeadline := time.Now().Add(wt.config.StartupTimeout)
+	healthy := false
+	failures := 0
+	check := time.NewTimer(whisperStartupPollInterval)
+	defer check.Stop()
+
+	for {
+		select {
+		case err := <-exited:
+			if err == nil {
+				err = fmt.Errorf("process exited")
+			}
+			return fmt.Errorf("whisper server exited: %w", err)
+		case <-check.C:
+		}
+
+		if wt.checkHealth(baseURL) {
+			if !healthy {
+				logrus.WithField("pid", cmd.Process.Pid).Info("Whisper server ready")
+			}
+			healthy = true
+			failures = 0
+			wt.ready.Store(true)
+			check.Reset(wt.config.HealthInterval)
+			continue
+		}
+
+		wt.ready.Store(false)
+		if !healthy {
+			if time.Now().After(startupDeadline) {
+				_ = cmd.Process.Kill()
+				<-exited
+				return fmt.Errorf("whisper server did not become healthy within %s", wt.config.StartupTimeout)
+			}
+			check.Reset(whisperStartupPollInterval)
+			continue
+		}
+
+		failures++
+		if failures >= whisperHealthFailureLimit {
+			_ = cmd.Process.Kill()
+			<-exited
+			return fmt.Errorf("whisper server failed %d health checks", failures)
+		}
+		check.Reset(wt.config.HealthInterval)
+	}
+}
+
+// checkHealth queries the server's /health endpoint, which answers 200 once the model is loaded
+func (wt *WhisperServerTranscriber) checkHealth(baseURL string) bool {
+	ctx, cancel := context.WithTimeout(wt.ctx, whisperHealthTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, baseURL+"/health", nil)
+	if err != nil {
+		return false
+	}
+	resp, err := wt.client.Do(req)
+	if err != nil {
+		return false
+	}
+	defer func() { _ = resp.Body.Close() }()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	return resp.StatusCode == http.StatusOK
+}
+
+// inference posts one WAV file to the server's /inference endpoint
+func (wt *WhisperServerTranscriber) inference(ctx context.Context, baseURL string, wav []byte, fields map[string]string) (*openAITranscription, error) {
+	var body bytes.Buffer
+	form := multipart.NewWriter(&body)
+	for name, value := range fields {
+		if err := form.WriteField(name, value); err != nil {
+			return nil, fmt.Errorf("error writing form field %s: %w", name, err)
+		}
+	}
+	file, err := form.CreateFormFile("file", "audio.wav")
+	if err != nil {
+		return nil, fmt.Errorf("error creating form file: %w", err)
+	}
+	if _, err := file.Write(wav); err != nil {
+		return nil, fmt.Errorf("error writing audio: %w", err)
+	}
+	if err := form.Close(); err != nil {
+		return nil, fmt.Errorf("error closing form: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, baseURL+"/inference", &body)
+	if err != nil {
+		return nil, fmt.Errorf("error creating inference request: %w", err)
+	}
+	req.Header.Set("Content-Type", form.FormDataContentType())
+
+	resp, err := wt.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("whisper server request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("error reading whisper server response: %w", err)
+	}
+
+	var response struct {
+		openAITranscription
+		Error string `json:"error"`
+	}
+	if err := json.Unmarshal(data, &response); err != nil {
+		return nil, fmt.Errorf("error decoding whisper server response (status %d): %w", resp.StatusCode, err)
+	}
+	if resp.StatusCode != http.StatusOK || response.Error != "" {
+		return nil, fmt.Errorf("whisper server inference failed (%d): %s", resp.StatusCode, response.Error)
+	}
+	return &response.openAITranscription, nil
+}
+
+// freeLocalPort finds a port on localhost that is currently free
+func freeLocalPort() (int, error) {
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		return 0, fmt.Errorf("error finding free port: %w", err)
+	}
+	defer func() { _ = listener.Close() }()
+	return listener.Addr().(*net.TCPAddr).Port, nil
+}
+
+// processLogWriter forwards a child process's output to the debug log, line by line
+type processLogWriter struct {
+	entry *logrus.Entry
+	buf   []byte
+}
+
+func (w *processLogWriter) Write(p []byte) (int, error) {
+	w.buf = append(w.buf, p...)
+	for {
+		i := bytes.IndexByte(w.buf, '\n')
+		if i < 0 {
+			break
+		}
+		if line := strings.TrimSpace(string(w.buf[:i])); line != "" {
+			w.entry.Debug(line)
+		}
+		w.buf = w.buf[i+1:]
+	}
+	return len(p), nil
+}
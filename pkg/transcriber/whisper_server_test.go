@@ -0,0 +1,162 @@
+package transcriber
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// TestWhisperServerHelperProcess is not a real test: it stands in for whisper-server
+// when the test binary is launched by newTestWhisperServer
+func TestWhisperServerHelperProcess(t *testing.T) {
+	if os.Getenv("WHISPER_SERVER_HELPER") == "" {
+		t.Skip("helper process")
+	}
+
+	var port string
+	args := os.Args
+	for i, arg := range args {
+		if arg == "--port" && i+1 < len(args) {
+			port = args[i+1]
+		}
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		if os.Getenv("WHISPER_SERVER_HELPER") == "unhealthy" {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		_, _ = w.Write([]byte(`{"status":"ok"}`))
+	})
+	mux.HandleFunc("/inference", func(w http.ResponseWriter, r *http.Request) {
+		file, _, err := r.FormFile("file")
+		if err != nil {
+			w.WriteHeader(http.StatusBadRequest)
+			_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+			return
+		}
+		info, _ := file.(interface{ Size() int64 })
+		size := int64(-1)
+		if info != nil {
+			size = info.Size()
+		}
+		text := fmt.Sprintf(" pid=%d language=%s prompt=%q bytes=%d translate=%s ",
+			os.Getpid(), r.FormValue("language"), r.FormValue("prompt"), size, r.FormValue("translate"))
+		_ = json.NewEncoder(w).Encode(map[string]any{
+			"text": text,
+			"segments": []map[string]any{{
+				"text":        text,
+				"avg_logprob": -0.1,
+				"words":       []map[string]any{{"word": " pid", "start": 0.0, "end": 0.5, "probability": 0.9}},
+			}},
+		})
+	})
+
+	// #nosec G114 - test helper only
+	_ = http.ListenAndServe("127.0.0.1:"+port, mux)
+	os.Exit(0)
+}
+
+func newTestWhisperServer(t *testing.T, mode string) *WhisperServerTranscriber {
+	t.Helper()
+	t.Setenv("WHISPER_SERVER_HELPER", mode)
+
+	model := filepath.Join(t.TempDir(), "ggml-test.bin")
+	require.NoError(t, os.WriteFile(model, []byte("model"), 0600))
+
+	wt, err := NewWhisperServerTranscriberWithConfig(WhisperServerConfig{
+		ModelPath:      model,
+		Command:        []string{os.Args[0], "-test.run=^TestWhisperServerHelperProcess$", "--"},
+		Language:       "de",
+		HealthInterval: 50 * time.Millisecond,
+		StartupTimeout: time.Second,
+	})
+	require.NoError(t, err)
+	t.Cleanup(func() { _ = wt.Close() })
+	return wt
+}
+
+func TestWhisperServerTranscriber(t *testing.T) {
+	wt := newTestWhisperServer(t, "healthy")
+
+	result, err := wt.TranscribeWithContext(make([]byte, 4800*4), TranscriptionOptions{
+		PreviousContext:  "the deployment went fine",
+		CustomVocabulary: []string{"Grafana"},
+	})
+	require.NoError(t, err)
+	assert.True(t, wt.IsReady())
+
+	assert.Contains(t, result.Text, "language=de")
+	assert.Contains(t, result.Text, `prompt="Grafana. the deployment went fine"`)
+	assert.Contains(t, result.Text, fmt.Sprintf("bytes=%d", 44+1600*2), "sent as 16kHz mono WAV")
+	assert.Equal(t, "de", result.Language)
+
+	assert.InDelta(t, 0.905, result.Confidence, 0.001)
+	assert.Empty(t, result.Words, "words are only kept when timestamps are enabled")
+
+	// Whisper only takes the primary subtag of a BCP-47 tag
+	result, err = wt.TranscribeWithContext(make([]byte, 1200), TranscriptionOptions{Language: "en-US", EnableTimestamps: true})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "language=en ")
+	require.Len(t, result.Words, 1)
+	assert.Equal(t, "pid", result.Words[0].Word)
+	assert.Equal(t, 500*time.Millisecond, result.Words[0].EndTime)
+	assert.NotContains(t, result.Text, "translate=true")
+
+	result, err = wt.TranslateSpeech(context.Background(), make([]byte, 1200), TranscriptionOptions{Language: "de"})
+	require.NoError(t, err)
+	assert.Contains(t, result.Text, "translate=true")
+}
+
+func TestWhisperServerTranscriberRestartsCrashedProcess(t *testing.T) {
+	wt := newTestWhisperServer(t, "healthy")
+
+	first, err := wt.Transcribe(make([]byte, 1200))
+	require.NoError(t, err)
+
+	wt.mu.Lock()
+	require.NoError(t, wt.process.Kill())
+	wt.mu.Unlock()
+
+	assert.Eventually(t, func() bool { return !wt.IsReady() }, 2*time.Second, 10*time.Millisecond)
+	assert.Eventually(t, wt.IsReady, 5*time.Second, 20*time.Millisecond)
+	assert.Equal(t, int32(1), wt.restarts.Load())
+
+	second, err := wt.Transcribe(make([]byte, 1200))
+	require.NoError(t, err)
+	assert.NotEqual(t, first, second, "served by a new process")
+}
+
+func TestWhisperServerTranscriberUnhealthyProcess(t *testing.T) {
+	wt := newTestWhisperServer(t, "unhealthy")
+
+	_, err := wt.Transcribe(make([]byte, 1200))
+	require.Error(t, err)
+	assert.False(t, wt.IsReady())
+
+	// A cancelled translation stops waiting for the server
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	_, err = wt.TranslateSpeech(ctx, make([]byte, 1200), TranscriptionOptions{})
+	assert.ErrorIs(t, err, context.Canceled)
+
+	// The process is killed after the startup timeout and started again
+	assert.Eventually(t, func() bool { return wt.restarts.Load() >= 1 }, 5*time.Second, 20*time.Millisecond)
+}
+
+func TestWhisperServerTranscriberRequiresModel(t *testing.T) {
+	_, err := NewWhisperServerTranscriberWithConfig(WhisperServerConfig{
+		ModelPath: "/nonexistent/model.bin",
+		Command:   []string{"whisper-server"},
+	})
+	assert.Error(t, err)
+}
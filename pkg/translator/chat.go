@@ -0,0 +1,161 @@
+package translator
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	// DefaultChatBaseURL is a local Ollama server; llama.cpp's llama-server,
+	// LM Studio, vLLM and the OpenAI API expose the same endpoint
+	DefaultChatBaseURL = "http://localhost:11434/v1"
+
+	chatRequestTimeout = 30 * time.Second
+)
+
+// ChatConfig configures the chat-completions translator
+type ChatConfig struct {
+	BaseURL    string       // API base URL including the version (default: DefaultChatBaseURL)
+	APIKey     string       // Bearer token; optional for local servers
+	Model      string       // Model name (required)
+	HTTPClient *http.Client // Optional custom client
+}
+
+// ChatTranslator translates with an LLM behind an OpenAI-compatible /chat/completions endpoint
+type ChatTranslator struct {
+	config ChatConfig
+	client *http.Client
+}
+
+// NewChatTranslator creates a chat-completions translator configured from the
+// environment: TRANSLATOR_BASE_URL, TRANSLATOR_API_KEY and TRANSLATOR_MODEL
+func NewChatTranslator() (*ChatTranslator, error) {
+	return NewChatTranslatorWithConfig(ChatConfig{
+		BaseURL: os.Getenv("TRANSLATOR_BASE_URL"),
+		APIKey:  os.Getenv("TRANSLATOR_API_KEY"),
+		Model:   os.Getenv("TRANSLATOR_MODEL"),
+	})
+}
+
+// NewChatTranslatorWithConfig creates a chat-completions translator from explicit settings
+func NewChatTranslatorWithConfig(config ChatConfig) (*ChatTranslator, error) {
+	if config.BaseURL == "" {
+		config.BaseURL = DefaultChatBaseURL
+	}
+	config.BaseURL = strings.TrimRight(config.BaseURL, "/")
+	if config.Model == "" {
+		return nil, fmt.Errorf("TRANSLATOR_MODEL is required for the chat translator")
+	}
+
+	ct := &ChatTranslator{config: config, client: config.HTTPClient}
+	if ct.client == nil {
+		ct.client = &http.Client{Timeout: chatRequestTimeout}
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"base_url": config.BaseURL,
+		"model":    config.Model,
+	}).Info("Chat translator initialized")
+
+	return ct, nil
+}
+
+// Translate asks the model for a translation of text and nothing else
+func (ct *ChatTranslator) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	source := "the source language"
+	if sourceLanguage != "" && sourceLanguage != "auto" {
+		source = sourceLanguage
+	}
+	request := chatRequest{
+		Model: ct.config.Model,
+		Messages: []chatMessage{
+			{
+				Role: "system",
+				Content: fmt.Sprintf("You translate transcribed speech from %s into the language with code %q. "+
+					"Reply with the translation only, without quotes, notes or explanations.", source, targetLanguage),
+			},
+			{Role: "user", Content: text},
+		},
+		Temperature: 0,
+	}
+
+	body, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("error encoding translation request: %w", err)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, ct.config.BaseURL+"/chat/completions", bytes.NewReader(body))
+	if err != nil {
+		return "", fmt.Errorf("error creating translation request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if ct.config.APIKey != "" {
+		req.Header.Set("Authorization", "Bearer "+ct.config.APIKey)
+	}
+
+	resp, err := ct.client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("translation request failed: %w", err)
+	}
+	defer func() { _ = resp.Body.Close() }()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("error reading translation response: %w", err)
+	}
+
+	var response chatResponse
+	if err := json.Unmarshal(data, &response); err != nil {
+		if resp.StatusCode != http.StatusOK {
+			return "", fmt.Errorf("translation failed with status %d", resp.StatusCode)
+		}
+		return "", fmt.Errorf("error decoding translation response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		if response.Error != nil && response.Error.Message != "" {
+			return "", fmt.Errorf("translation failed (%d): %s", resp.StatusCode, response.Error.Message)
+		}
+		return "", fmt.Errorf("translation failed with status %d", resp.StatusCode)
+	}
+	if len(response.Choices) == 0 {
+		return "", fmt.Errorf("translation response contained no choices")
+	}
+
+	return strings.TrimSpace(response.Choices[0].Message.Content), nil
+}
+
+// Close releases idle HTTP connections
+func (ct *ChatTranslator) Close() error {
+	ct.client.CloseIdleConnections()
+	return nil
+}
+
+// OpenAI-compatible /chat/completions request and response
+type chatRequest struct {
+	Model       string        `json:"model"`
+	Messages    []chatMessage `json:"messages"`
+	Temperature float64       `json:"temperature"`
+}
+
+type chatMessage struct {
+	Role    string `json:"role"`
+	Content string `json:"content"`
+}
+
+type chatResponse struct {
+	Choices []struct {
+		Message chatMessage `json:"message"`
+	} `json:"choices"`
+	Error *struct {
+		Message string `json:"message"`
+	} `json:"error,omitempty"`
+}
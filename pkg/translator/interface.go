@@ -0,0 +1,14 @@
+package translator
+
+import (
+	"context"
+)
+
+// Translator is the unified interface for text translation backends
+type Translator interface {
+	// Translate returns text in targetLanguage. sourceLanguage may be empty if unknown.
+	Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error)
+
+	// Close releases resources
+	Close() error
+}
@@ -0,0 +1,20 @@
+package translator
+
+import (
+	"context"
+	"fmt"
+)
+
+// MockTranslator tags text with the target language instead of translating it.
+// Used for testing the translation stage without a translation backend.
+type MockTranslator struct{}
+
+// Translate returns the text prefixed with the target language
+func (mt *MockTranslator) Translate(ctx context.Context, text, sourceLanguage, targetLanguage string) (string, error) {
+	return fmt.Sprintf("[%s] %s", targetLanguage, text), nil
+}
+
+// Close is a no-op
+func (mt *MockTranslator) Close() error {
+	return nil
+}
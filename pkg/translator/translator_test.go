@@ -0,0 +1,61 @@
+package translator
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMockTranslator(t *testing.T) {
+	text, err := (&MockTranslator{}).Translate(context.Background(), "Hallo", "de", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "[en] Hallo", text)
+}
+
+func TestChatTranslator(t *testing.T) {
+	var request chatRequest
+	var auth string
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		assert.Equal(t, "/v1/chat/completions", r.URL.Path)
+		auth = r.Header.Get("Authorization")
+		require.NoError(t, json.NewDecoder(r.Body).Decode(&request))
+		_, _ = w.Write([]byte(`{"choices":[{"message":{"role":"assistant","content":" Hello everyone \n"}}]}`))
+	}))
+	defer server.Close()
+
+	ct, err := NewChatTranslatorWithConfig(ChatConfig{BaseURL: server.URL + "/v1/", APIKey: "key", Model: "llama3"})
+	require.NoError(t, err)
+
+	text, err := ct.Translate(context.Background(), "Hallo zusammen", "de", "en")
+	require.NoError(t, err)
+	assert.Equal(t, "Hello everyone", text)
+
+	assert.Equal(t, "Bearer key", auth)
+	assert.Equal(t, "llama3", request.Model)
+	require.Len(t, request.Messages, 2)
+	assert.Contains(t, request.Messages[0].Content, `from de into the language with code "en"`)
+	assert.Equal(t, "Hallo zusammen", request.Messages[1].Content)
+}
+
+func TestChatTranslatorErrors(t *testing.T) {
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNotFound)
+		_, _ = w.Write([]byte(`{"error":{"message":"model \"missing\" not found"}}`))
+	}))
+	defer server.Close()
+
+	ct, err := NewChatTranslatorWithConfig(ChatConfig{BaseURL: server.URL, Model: "missing"})
+	require.NoError(t, err)
+
+	_, err = ct.Translate(context.Background(), "Hallo", "", "en")
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "not found")
+
+	_, err = NewChatTranslatorWithConfig(ChatConfig{BaseURL: server.URL})
+	assert.Error(t, err, "a model is required")
+}
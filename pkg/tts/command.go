@@ -0,0 +1,132 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"os"
+	"os/exec"
+	"strconv"
+	"strings"
+
+	"github.com/fankserver/discord-voice-mcp/internal/audio/pcm"
+	"github.com/sirupsen/logrus"
+)
+
+// OutputFormat describes what a TTS executable writes to stdout
+type OutputFormat int
+
+const (
+	// OutputWAV is a RIFF/WAVE file with 16-bit PCM
+	OutputWAV OutputFormat = iota
+	// OutputRaw is headerless signed 16-bit little-endian mono PCM
+	OutputRaw
+)
+
+const defaultPiperSampleRate = 22050
+
+// CommandSynthesizer runs a local TTS executable, writing text to its stdin
+// and reading audio from its stdout
+type CommandSynthesizer struct {
+	path       string
+	args       []string
+	format     OutputFormat
+	sampleRate int // Only used for OutputRaw
+}
+
+// NewCommandSynthesizer creates a synthesizer for an arbitrary executable.
+// sampleRate is only used when format is OutputRaw.
+func NewCommandSynthesizer(name string, args []string, format OutputFormat, sampleRate int) (*CommandSynthesizer, error) {
+	path, err := exec.LookPath(name)
+	if err != nil {
+		return nil, fmt.Errorf("%s executable not found in PATH: %w", name, err)
+	}
+	if format == OutputRaw && sampleRate <= 0 {
+		return nil, fmt.Errorf("sample rate is required for raw output")
+	}
+
+	return &CommandSynthesizer{
+		path:       path,
+		args:       args,
+		format:     format,
+		sampleRate: sampleRate,
+	}, nil
+}
+
+// NewPiperSynthesizer creates a synthesizer using the piper neural TTS engine.
+// PIPER_SAMPLE_RATE overrides the model's output rate (default: 22050).
+func NewPiperSynthesizer(modelPath string) (*CommandSynthesizer, error) {
+	if _, err := os.Stat(modelPath); err != nil {
+		if os.IsNotExist(err) {
+			return nil, fmt.Errorf("piper model file not found: %s", modelPath)
+		}
+		return nil, fmt.Errorf("piper model file not accessible: %w", err)
+	}
+
+	sampleRate := defaultPiperSampleRate
+	if envRate := os.Getenv("PIPER_SAMPLE_RATE"); envRate != "" {
+		rate, err := strconv.Atoi(envRate)
+		if err != nil || rate <= 0 {
+			return nil, fmt.Errorf("invalid PIPER_SAMPLE_RATE %q", envRate)
+		}
+		sampleRate = rate
+	}
+
+	return NewCommandSynthesizer("piper", []string{"--model", modelPath, "--output_raw"}, OutputRaw, sampleRate)
+}
+
+// NewEspeakSynthesizer creates a synthesizer using espeak-ng. An empty voice uses the default.
+func NewEspeakSynthesizer(voice string) (*CommandSynthesizer, error) {
+	args := []string{"--stdout"}
+	if voice != "" {
+		args = append(args, "-v", voice)
+	}
+	return NewCommandSynthesizer("espeak-ng", args, OutputWAV, 0)
+}
+
+// Synthesize runs the executable once for the given text
+func (cs *CommandSynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return nil, fmt.Errorf("nothing to synthesize")
+	}
+
+	// #nosec G204 - path comes from exec.LookPath and args are fixed at construction
+	cmd := exec.CommandContext(ctx, cs.path, cs.args...)
+	cmd.Stdin = strings.NewReader(text)
+	var stdout, stderr bytes.Buffer
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		return nil, fmt.Errorf("error running %s: %w (stderr: %s)", cs.path, err, strings.TrimSpace(stderr.String()))
+	}
+
+	audio := &Audio{SampleRate: cs.sampleRate, Channels: 1}
+	switch cs.format {
+	case OutputWAV:
+		var err error
+		audio.Samples, audio.SampleRate, audio.Channels, err = pcm.DecodeWAV(stdout.Bytes())
+		if err != nil {
+			return nil, err
+		}
+	default:
+		audio.Samples = pcm.BytesToSamples(stdout.Bytes())
+	}
+	if len(audio.Samples) == 0 {
+		return nil, fmt.Errorf("%s produced no audio", cs.path)
+	}
+
+	logrus.WithFields(logrus.Fields{
+		"engine":   cs.path,
+		"chars":    len(text),
+		"duration": audio.Duration(),
+	}).Debug("Synthesized speech")
+
+	return audio, nil
+}
+
+// Close is a no-op; each synthesis runs its own process
+func (cs *CommandSynthesizer) Close() error {
+	return nil
+}
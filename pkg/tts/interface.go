@@ -0,0 +1,31 @@
+package tts
+
+import (
+	"context"
+	"time"
+)
+
+// Synthesizer is the unified interface for all text-to-speech backends
+type Synthesizer interface {
+	// Synthesize renders text to PCM audio
+	Synthesize(ctx context.Context, text string) (*Audio, error)
+
+	// Close releases resources
+	Close() error
+}
+
+// Audio is signed 16-bit PCM produced by a Synthesizer
+type Audio struct {
+	Samples    []int16 // Interleaved if Channels > 1
+	SampleRate int
+	Channels   int
+}
+
+// Duration returns the playback length of the audio
+func (a *Audio) Duration() time.Duration {
+	if a.SampleRate <= 0 || a.Channels <= 0 {
+		return 0
+	}
+	frames := len(a.Samples) / a.Channels
+	return time.Duration(frames) * time.Second / time.Duration(a.SampleRate)
+}
@@ -0,0 +1,54 @@
+package tts
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/sirupsen/logrus"
+)
+
+const (
+	toneSampleRate  = 22050
+	toneFrequency   = 440.0
+	toneAmplitude   = 0.3
+	tonePerWord     = 250 * time.Millisecond
+	toneMinDuration = 250 * time.Millisecond
+)
+
+// ToneSynthesizer renders a sine beep whose length follows the word count.
+// Used for testing playback without a real TTS engine.
+type ToneSynthesizer struct{}
+
+// Synthesize returns one tone lasting 250ms per word
+func (ts *ToneSynthesizer) Synthesize(ctx context.Context, text string) (*Audio, error) {
+	words := len(strings.Fields(text))
+	if words == 0 {
+		return nil, fmt.Errorf("nothing to synthesize")
+	}
+
+	duration := time.Duration(words) * tonePerWord
+	if duration < toneMinDuration {
+		duration = toneMinDuration
+	}
+	logrus.WithFields(logrus.Fields{
+		"words":    words,
+		"duration": duration,
+	}).Debug("ToneSynthesizer: Generating tone")
+
+	n := int(duration.Seconds() * toneSampleRate)
+	samples := make([]int16, n)
+	for i := range samples {
+		v := toneAmplitude * math.Sin(2*math.Pi*toneFrequency*float64(i)/toneSampleRate)
+		samples[i] = int16(v * math.MaxInt16)
+	}
+
+	return &Audio{Samples: samples, SampleRate: toneSampleRate, Channels: 1}, nil
+}
+
+// Close is a no-op
+func (ts *ToneSynthesizer) Close() error {
+	return nil
+}
@@ -0,0 +1,105 @@
+package tts
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"os/exec"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// buildWAV builds a 16-bit PCM WAV file, with an extra chunk before the data
+func buildWAV(samples []int16, sampleRate, channels int) []byte {
+	var data bytes.Buffer
+	for _, s := range samples {
+		_ = binary.Write(&data, binary.LittleEndian, s)
+	}
+
+	var b bytes.Buffer
+	b.WriteString("RIFF")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(36+8+2+8+data.Len()))
+	b.WriteString("WAVE")
+
+	b.WriteString("fmt ")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(16))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(1))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate))
+	_ = binary.Write(&b, binary.LittleEndian, uint32(sampleRate*channels*2))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(channels*2))
+	_ = binary.Write(&b, binary.LittleEndian, uint16(16))
+
+	b.WriteString("LIST")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(2))
+	b.WriteString("xx")
+
+	b.WriteString("data")
+	_ = binary.Write(&b, binary.LittleEndian, uint32(data.Len()))
+	b.Write(data.Bytes())
+	return b.Bytes()
+}
+
+func TestToneSynthesizer(t *testing.T) {
+	synth := &ToneSynthesizer{}
+
+	audio, err := synth.Synthesize(context.Background(), "one two three four")
+	require.NoError(t, err)
+	assert.Equal(t, 1, audio.Channels)
+	assert.Equal(t, time.Second, audio.Duration())
+
+	_, err = synth.Synthesize(context.Background(), "   ")
+	assert.Error(t, err)
+}
+
+func TestCommandSynthesizerRawOutput(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	// cat echoes the text back, so each pair of bytes becomes one sample
+	synth, err := NewCommandSynthesizer("cat", nil, OutputRaw, 8000)
+	require.NoError(t, err)
+
+	audio, err := synth.Synthesize(context.Background(), "abcd")
+	require.NoError(t, err)
+	assert.Equal(t, 8000, audio.SampleRate)
+	assert.Equal(t, []int16{int16('a') | int16('b')<<8, int16('c') | int16('d')<<8}, audio.Samples)
+}
+
+func TestCommandSynthesizerWAVOutput(t *testing.T) {
+	if _, err := exec.LookPath("cat"); err != nil {
+		t.Skip("cat not available")
+	}
+
+	synth, err := NewCommandSynthesizer("cat", nil, OutputWAV, 0)
+	require.NoError(t, err)
+
+	// cat echoes the text back, so the text is the WAV file
+	audio, err := synth.Synthesize(context.Background(), string(buildWAV([]int16{1, -2, 3, -4}, 16000, 2)))
+	require.NoError(t, err)
+	assert.Equal(t, 16000, audio.SampleRate)
+	assert.Equal(t, 2, audio.Channels)
+	assert.Equal(t, []int16{1, -2, 3, -4}, audio.Samples)
+
+	// espeak-ng writing to a pipe leaves the data size as a placeholder
+	wav := buildWAV([]int16{5, 6, 7}, 22050, 1)
+	binary.LittleEndian.PutUint32(wav[len(wav)-10:], 0x7fffffff)
+	audio, err = synth.Synthesize(context.Background(), string(wav))
+	require.NoError(t, err)
+	assert.Equal(t, []int16{5, 6, 7}, audio.Samples)
+
+	_, err = synth.Synthesize(context.Background(), "not a wav file")
+	assert.Error(t, err)
+}
+
+func TestNewCommandSynthesizerMissingExecutable(t *testing.T) {
+	_, err := NewCommandSynthesizer("definitely-not-a-tts-engine", nil, OutputWAV, 0)
+	assert.Error(t, err)
+
+	_, err = NewPiperSynthesizer("/nonexistent/model.onnx")
+	assert.Error(t, err)
+}